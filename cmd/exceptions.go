@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/controlplaneio/kubesec/v2/pkg/ruler"
+	"github.com/controlplaneio/kubesec/v2/pkg/util"
+	"github.com/spf13/cobra"
+)
+
+// ExceptionStatus is the state of an exception reported by exceptions check
+type ExceptionStatus struct {
+	Index   int      `json:"index"`
+	Rules   []string `json:"rules"`
+	Owner   string   `json:"owner"`
+	Expires string   `json:"expires"`
+	Expired bool     `json:"expired"`
+	Unused  bool     `json:"unused"`
+}
+
+func init() {
+	var format string
+	var exceptionsCmd = &cobra.Command{
+		Use:   `exceptions`,
+		Short: "Manage the exceptions file",
+	}
+
+	var checkCmd = &cobra.Command{
+		Use:   `check [file|directory|glob]...`,
+		Short: "List the expired exceptions, and the exceptions unused by the given files",
+		Example: `  kubesec exceptions check --exceptions ./exceptions.yaml
+  kubesec exceptions check --exceptions ./exceptions.yaml ./manifests --exclude 'tests/'
+  helm template ./chart | kubesec exceptions check --exceptions ./exceptions.yaml -`,
+	}
+
+	checkCmd.Flags().StringVarP(&format, "format", "f", "table", "Set output format (json, yaml, table)")
+	checkCmd.Flags().StringVar(&exceptionsFile, "exceptions", "", "Exceptions file to check")
+	checkCmd.Flags().StringSliceVar(&rulesFiles, "rules-file", []string{}, "Load custom rules from a YAML or JSON file (can be specified multiple times)")
+	checkCmd.Flags().StringSliceVar(&rulesDirs, "rules-dir", []string{}, "Load custom rules from every YAML or JSON file in a directory (can be specified multiple times)")
+	checkCmd.Flags().StringSliceVar(&includes, "include", []string{}, "Check the files of directories matching these .gitignore style patterns (default *.yaml,*.yml,*.json)")
+	checkCmd.Flags().StringSliceVar(&excludes, "exclude", []string{}, "Skip the files and directories of directories matching these .gitignore style patterns")
+	checkCmd.Flags().StringSliceVar(&ignoreFiles, "ignore-file", []string{}, "Skip the files matching the patterns of a .gitignore style file (can be specified multiple times)")
+	checkCmd.Flags().IntVar(&exitCode, "exit-code", 2, "Set the exit-code to use when stale or unused exceptions are found")
+	checkCmd.RunE = func(cmd *cobra.Command, args []string) error {
+		if exceptionsFile == "" {
+			return fmt.Errorf("exceptions file is required, set it with --exceptions")
+		}
+
+		rootCmd.SilenceErrors = true
+		rootCmd.SilenceUsage = true
+
+		rulesetConfig, err := getRulesetConfig()
+		if err != nil {
+			return err
+		}
+
+		ruleset, err := ruler.NewRulesetWithConfig(logger, rulesetConfig)
+		if err != nil {
+			return err
+		}
+
+		// usage only depends on the findings, skip schema validation
+		schemaConfig := ruler.NewDefaultSchemaConfig()
+		schemaConfig.DisableValidation = true
+
+		if len(args) > 0 {
+			files, err := collectFiles(args)
+			if err != nil {
+				return err
+			}
+			if _, _, err := scanFiles(ruleset, files, schemaConfig); err != nil {
+				return err
+			}
+		}
+
+		exceptions := rulesetConfig.Exceptions
+		statuses := make([]ExceptionStatus, 0)
+		for i, e := range exceptions.List() {
+			status := ExceptionStatus{
+				Index:   i,
+				Rules:   e.Rules,
+				Owner:   e.Owner,
+				Expires: e.Expires,
+				Expired: e.Expired(time.Now()),
+				Unused:  len(args) > 0 && !exceptions.Used(i),
+			}
+			if status.Expired || status.Unused {
+				statuses = append(statuses, status)
+			}
+		}
+
+		printTableFn := func(w io.Writer) error {
+			tw := util.NewTabWriter(w)
+			fmt.Fprintf(tw, "Index\tRules\tOwner\tExpires\tStatus\n")
+			for _, s := range statuses {
+				var problems []string
+				if s.Expired {
+					problems = append(problems, "expired")
+				}
+				if s.Unused {
+					problems = append(problems, "unused")
+				}
+				fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t\n",
+					s.Index,
+					strings.Join(s.Rules, ","),
+					s.Owner,
+					s.Expires,
+					strings.Join(problems, ","),
+				)
+			}
+			return tw.Flush()
+		}
+
+		if err := util.Print(format, statuses, os.Stdout, printTableFn); err != nil {
+			return err
+		}
+
+		if len(statuses) > 0 {
+			os.Exit(exitCode)
+		}
+		return nil
+	}
+
+	exceptionsCmd.AddCommand(checkCmd)
+	rootCmd.AddCommand(exceptionsCmd)
+}
@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/controlplaneio/kubesec/v2/pkg/fix"
+	"github.com/controlplaneio/kubesec/v2/pkg/ruler"
+	"github.com/controlplaneio/kubesec/v2/pkg/rules"
+	"github.com/controlplaneio/kubesec/v2/pkg/util"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	var fixFormat string
+	var fixRules []string
+	var inPlace bool
+	var dryRun bool
+	var list bool
+	var fixCmd = &cobra.Command{
+		Use:   `fix [file]`,
+		Short: "Remediate the findings of a Kubernetes resource manifest",
+		Long: `Rewrite a Kubernetes resource manifest with the fixes of the findings, keeping
+its comments, key order and documents. Only the safe fixes are applied unless
+the rules are given with --rules, run 'kubesec fix --list' to see the fixes.`,
+		Example: `  kubesec fix ./deployment.yaml
+  kubesec fix --in-place ./deployment.yaml
+  kubesec fix --dry-run ./deployment.yaml
+  kubesec fix --rules CapDropAll,ReadOnlyRootFilesystem ./deployment.yaml
+  kubesec fix -f json-patch ./deployment.yaml
+  kubesec fix -f kustomize ./deployment.yaml
+  cat deployment.yaml | kubesec fix -`,
+	}
+
+	fixCmd.Flags().StringVarP(&fixFormat, "format", "f", "yaml", "Set output format (yaml, json-patch, kustomize)")
+	fixCmd.Flags().StringSliceVarP(&fixRules, "rules", "r", []string{}, "Comma-separated list of rule IDs to fix (empty applies the safe fixes)")
+	fixCmd.Flags().BoolVar(&inPlace, "in-place", false, "Write the fixed manifest to the file instead of the output")
+	fixCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Print the diff of the fixes without writing them")
+	fixCmd.Flags().BoolVar(&list, "list", false, "List the available fixes")
+	fixCmd.Flags().StringVar(&configFile, "config", "", "Load the pod spec mappings of custom resources from a kubesec configuration file")
+	fixCmd.RunE = func(cmd *cobra.Command, args []string) error {
+		if list {
+			rootCmd.SilenceUsage = true
+			return printFixers(fixFormat)
+		}
+		if len(args) != 1 {
+			return fmt.Errorf("file path is required")
+		}
+		if inPlace && args[0] == "-" {
+			return fmt.Errorf("--in-place requires a file")
+		}
+		if inPlace && fixFormat != "yaml" {
+			return fmt.Errorf("--in-place only supports the yaml format")
+		}
+
+		rootCmd.SilenceErrors = true
+		rootCmd.SilenceUsage = true
+
+		if configFile != "" {
+			c, err := ruler.LoadConfig(configFile)
+			if err != nil {
+				return err
+			}
+			if err := rules.RegisterPodSpecMappings(c.PodSpecs...); err != nil {
+				return err
+			}
+		}
+
+		file, err := getInput(args)
+		if err != nil {
+			return err
+		}
+
+		result, err := fix.Fix(file.fileBytes, fixRules...)
+		if err != nil {
+			return err
+		}
+
+		if dryRun {
+			return result.WriteDiff(os.Stdout, file.fileBytes, file.fileName)
+		}
+
+		switch fixFormat {
+		case "json-patch":
+			return result.WriteJSONPatch(os.Stdout)
+		case "kustomize":
+			return result.WriteKustomize(os.Stdout)
+		case "yaml":
+			if !inPlace {
+				_, err := os.Stdout.Write(result.Manifest())
+				return err
+			}
+			if !result.Changed() {
+				return nil
+			}
+			info, err := os.Stat(args[0])
+			if err != nil {
+				return err
+			}
+			return os.WriteFile(args[0], result.Manifest(), info.Mode())
+		default:
+			return fmt.Errorf("unsupported format %q (use yaml, json-patch or kustomize)", fixFormat)
+		}
+	}
+
+	rootCmd.AddCommand(fixCmd)
+}
+
+func printFixers(format string) error {
+	// the manifest formats are not list formats
+	if format != "json" {
+		format = "table"
+	}
+
+	printTableFn := func(w io.Writer) error {
+		tw := util.NewTabWriter(w)
+		fmt.Fprintf(tw, "Rule ID\tSafe\tDescription\n")
+		for _, f := range fix.Fixers() {
+			fmt.Fprintf(tw, "%s\t%t\t%s\t\n", f.RuleID, f.Safe, f.Description)
+		}
+		return tw.Flush()
+	}
+
+	return util.Print(format, fix.Fixers(), os.Stdout, printTableFn)
+}
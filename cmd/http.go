@@ -23,6 +23,11 @@ func init() {
 	httpCmd.Flags().StringVarP(&keypath, "keypath", "k", "", "Path to in-toto link signing key")
 	httpCmd.Flags().StringVar(&k8sVersion, "kubernetes-version", "", "Kubernetes version to validate manifets")
 	httpCmd.Flags().StringSliceVar(&schemaLocations, "schema-location", []string{}, "Override schema location search path, local or http (can be specified multiple times)")
+	httpCmd.Flags().StringSliceVar(&rulesFiles, "rules-file", []string{}, "Load custom rules from a YAML or JSON file (can be specified multiple times)")
+	httpCmd.Flags().StringSliceVar(&rulesDirs, "rules-dir", []string{}, "Load custom rules from every YAML or JSON file in a directory (can be specified multiple times)")
+	httpCmd.Flags().StringVar(&configFile, "config", "", "Load policy profiles from a kubesec configuration file")
+	httpCmd.Flags().StringVar(&profile, "profile", "", "Default policy profile, overridden by the profile query parameter")
+	httpCmd.Flags().StringVar(&exceptionsFile, "exceptions", "", "Accept the findings matched by the exceptions of a YAML or JSON file")
 
 	rootCmd.AddCommand(httpCmd)
 }
@@ -83,7 +88,16 @@ var httpCmd = &cobra.Command{
 		schemaConfig.Locations = schemaLocations
 		schemaConfig.ValidatorOpts.KubernetesVersion = k8sVersion
 
-		server.ListenAndServe(addr, time.Minute, jsonLogger, stopCh, keypath, schemaConfig)
+		rulesetConfig, err := getRulesetConfig()
+		if err != nil {
+			return err
+		}
+		// fail on startup rather than on each request
+		if _, err := ruler.NewRulesetWithConfig(jsonLogger, rulesetConfig); err != nil {
+			return err
+		}
+
+		server.ListenAndServe(addr, time.Minute, jsonLogger, stopCh, keypath, schemaConfig, rulesetConfig)
 		return nil
 	},
 }
@@ -14,24 +14,51 @@ import (
 
 func init() {
 	var format string
+	var framework string
 	var printRulesCmd = &cobra.Command{
 		Use:   `print-rules`,
 		Short: "Print all the scanning rules with their associated scores",
 		Example: `  kubesec print-rules
   kubesec print-rules -f yaml
-  kubesec print-rules -f table`,
+  kubesec print-rules -f table
+  kubesec print-rules --rules-file ./house-rules.yaml
+  kubesec print-rules --framework cis`,
 	}
 
 	printRulesCmd.Flags().StringVarP(&format, "format", "f", "json", "Set output format (json, yaml, table)")
+	printRulesCmd.Flags().StringVar(&framework, "framework", "", "Only print the rules mapped to a compliance framework (cis, nsa, mitre)")
+	printRulesCmd.Flags().StringSliceVar(&rulesFiles, "rules-file", []string{}, "Load custom rules from a YAML or JSON file (can be specified multiple times)")
+	printRulesCmd.Flags().StringSliceVar(&rulesDirs, "rules-dir", []string{}, "Load custom rules from every YAML or JSON file in a directory (can be specified multiple times)")
 	printRulesCmd.RunE = func(cmd *cobra.Command, args []string) error {
 		rootCmd.SilenceErrors = true
 		rootCmd.SilenceUsage = true
 
-		ruleSet, err := ruler.NewRuleset(logger)
+		if framework != "" {
+			if err := ruler.ValidateFramework(framework); err != nil {
+				return err
+			}
+		}
+
+		rulesetConfig, err := getRulesetConfig()
 		if err != nil {
 			return err
 		}
 
+		ruleSet, err := ruler.NewRulesetWithConfig(logger, rulesetConfig)
+		if err != nil {
+			return err
+		}
+
+		if framework != "" {
+			var filtered []ruler.Rule
+			for _, rule := range ruleSet.Rules {
+				if rule.HasFramework(framework) {
+					filtered = append(filtered, rule)
+				}
+			}
+			ruleSet.Rules = filtered
+		}
+
 		// Sort by rule ID
 		sort.Slice(ruleSet.Rules, func(i, j int) bool {
 			return ruleSet.Rules[i].ID < ruleSet.Rules[j].ID
@@ -39,13 +66,26 @@ func init() {
 
 		printTableFn := func(w io.Writer) error {
 			tw := util.NewTabWriter(w)
-			fmt.Fprintf(tw, "ID\tReason\tPoints\tKinds\n")
+			fmt.Fprintf(tw, "ID\tReason\tPoints\tSeverity\tKinds\tSource\tCompliance\n")
 			for _, rule := range ruleSet.Rules {
-				fmt.Fprintf(tw, "%s\t%s\t%d\t%s\t\n",
+				source := rule.Source
+				if source == "" {
+					source = "builtin"
+				}
+				var compliance []string
+				for _, c := range rule.Compliance {
+					if framework == "" || c.Framework == framework {
+						compliance = append(compliance, c.String())
+					}
+				}
+				fmt.Fprintf(tw, "%s\t%s\t%d\t%s\t%s\t%s\t%s\t\n",
 					rule.ID,
 					rule.Reason,
 					rule.Points,
+					rule.Severity,
 					strings.Join(rule.Kinds, ","),
+					source,
+					strings.Join(compliance, ","),
 				)
 			}
 			return tw.Flush()
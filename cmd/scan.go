@@ -7,10 +7,15 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"runtime"
 	"strings"
+	"sync"
 
+	"github.com/controlplaneio/kubesec/v2/pkg/input"
+	"github.com/controlplaneio/kubesec/v2/pkg/pss"
 	"github.com/controlplaneio/kubesec/v2/pkg/report"
 	"github.com/controlplaneio/kubesec/v2/pkg/ruler"
+	"github.com/controlplaneio/kubesec/v2/pkg/rules"
 	"github.com/spf13/cobra"
 	"go.uber.org/zap"
 )
@@ -23,27 +28,59 @@ func (e *ScanFailedValidationError) Error() string {
 }
 
 var (
-	debug           bool
-	absolutePath    bool
-	format          string
-	template        string
-	k8sVersion      string
-	schemaLocations = []string{}
-	outputLocation  string
-	exitCode        int
-	rulesIDs        []string
+	debug                 bool
+	absolutePath          bool
+	format                string
+	template              string
+	k8sVersion            string
+	schemaLocations       = []string{}
+	outputLocation        string
+	exitCode              int
+	partialExitCode       int
+	rulesIDs              []string
+	rulesFiles            []string
+	rulesDirs             []string
+	configFile            string
+	profile               string
+	exceptionsFile        string
+	pssLevel              string
+	framework             string
+	failOnSeverity        string
+	includes              []string
+	excludes              []string
+	ignoreFiles           []string
+	concurrency           int
+	baselineFile          string
+	maxLength             int
+	schemaInvalidExitCode int
 )
 
 func init() {
 	scanCmd.Flags().BoolVar(&debug, "debug", false, "turn on debug logs")
 	scanCmd.Flags().BoolVar(&absolutePath, "absolute-path", false, "use the absolute path for the file name")
-	scanCmd.Flags().StringVarP(&format, "format", "f", "json", "Set output format (json, table, template)")
+	scanCmd.Flags().StringVarP(&format, "format", "f", "json", "Set output format (json, table, sarif, junit, markdown, html, template)")
 	scanCmd.Flags().StringVar(&k8sVersion, "kubernetes-version", "", "Kubernetes version to validate manifets")
 	scanCmd.Flags().StringSliceVar(&schemaLocations, "schema-location", []string{}, "Override schema location search path, local or http (can be specified multiple times)")
 	scanCmd.Flags().StringVarP(&template, "template", "t", "", "Set output template, it will check for a file or read input as the template")
+	scanCmd.Flags().StringVar(&baselineFile, "baseline", "", "Compare the scores of the markdown output to the JSON output of a previous scan")
+	scanCmd.Flags().IntVar(&maxLength, "max-length", report.DefaultMarkdownMaxLength, "Leave out the objects past this length of the markdown output (0 for no limit)")
 	scanCmd.Flags().StringSliceVarP(&rulesIDs, "rules", "r", []string{}, "Comma-separated list of rule IDs to scan (empty scans all rules). Run 'kubesec print-rules' to see all rules")
+	scanCmd.Flags().StringSliceVar(&rulesFiles, "rules-file", []string{}, "Load custom rules from a YAML or JSON file (can be specified multiple times)")
+	scanCmd.Flags().StringSliceVar(&rulesDirs, "rules-dir", []string{}, "Load custom rules from every YAML or JSON file in a directory (can be specified multiple times)")
+	scanCmd.Flags().StringVar(&configFile, "config", "", "Load policy profiles from a kubesec configuration file")
+	scanCmd.Flags().StringVar(&profile, "profile", "", "Scan with the named policy profile of the configuration file")
+	scanCmd.Flags().StringVar(&pssLevel, "pss", "", "Check the Pod Security Standards level (baseline, restricted) and fail on any violation")
+	scanCmd.Flags().StringVar(&framework, "framework", "", "Report the status of the controls of a compliance framework (cis, nsa, mitre) instead of points, failing on any failed control")
+	scanCmd.Flags().StringVar(&exceptionsFile, "exceptions", "", "Accept the findings matched by the exceptions of a YAML or JSON file")
+	scanCmd.Flags().StringVar(&failOnSeverity, "fail-on-severity", "", "Fail on findings at or above a severity (critical, high, medium, low, info) instead of a low score")
 	scanCmd.Flags().StringVarP(&outputLocation, "output", "o", "", "Set output location")
 	scanCmd.Flags().IntVar(&exitCode, "exit-code", 2, "Set the exit-code to use on failure")
+	scanCmd.Flags().StringSliceVar(&includes, "include", []string{}, "Scan the files of directories matching these .gitignore style patterns (default *.yaml,*.yml,*.json)")
+	scanCmd.Flags().StringSliceVar(&excludes, "exclude", []string{}, "Skip the files and directories of directories matching these .gitignore style patterns")
+	scanCmd.Flags().StringSliceVar(&ignoreFiles, "ignore-file", []string{}, "Skip the files matching the patterns of a .gitignore style file (can be specified multiple times)")
+	scanCmd.Flags().IntVar(&concurrency, "concurrency", runtime.NumCPU(), "Set the number of files scanned concurrently")
+	scanCmd.Flags().IntVar(&partialExitCode, "partial-exit-code", 3, "Set the exit-code to use when some documents could not be scanned")
+	scanCmd.Flags().IntVar(&schemaInvalidExitCode, "schema-invalid-exit-code", 0, "Set the exit-code to use when objects fail the schema validation (default --exit-code)")
 	rootCmd.AddCommand(scanCmd)
 }
 
@@ -67,7 +104,14 @@ func getInput(args []string) (File, error) {
 		}
 		return file, nil
 	}
-	fileName := args[0]
+	return readFile(args[0])
+}
+
+// readFile reads a file to scan, named by its absolute path with
+// --absolute-path
+func readFile(fileName string) (File, error) {
+	var file File
+
 	filePath, err := filepath.Abs(fileName)
 	if err != nil {
 		return file, err
@@ -87,10 +131,158 @@ func getInput(args []string) (File, error) {
 	return file, nil
 }
 
+// getRulesetConfig loads the custom rules passed with --rules-file and --rules-dir,
+// the profiles of the --config file, the --exceptions file and the --pss level
+func getRulesetConfig() (ruler.RulesetConfig, error) {
+	var config ruler.RulesetConfig
+
+	if configFile != "" {
+		c, err := ruler.LoadConfig(configFile)
+		if err != nil {
+			return config, err
+		}
+		config.Profiles = c.Profiles
+		config.ExitPolicy = c.ExitPolicy
+		if err := rules.RegisterPodSpecMappings(c.PodSpecs...); err != nil {
+			return config, err
+		}
+	}
+	if profile != "" && configFile == "" {
+		return config, fmt.Errorf("--profile requires a configuration file set with --config")
+	}
+	config.Profile = profile
+
+	if pssLevel != "" {
+		level, err := pss.ParseLevel(pssLevel)
+		if err != nil {
+			return config, err
+		}
+		config.PodSecurityLevel = level
+	}
+
+	if exceptionsFile != "" {
+		exceptions, err := ruler.LoadExceptions(exceptionsFile)
+		if err != nil {
+			return config, err
+		}
+		config.Exceptions = exceptions
+	}
+
+	paths := append([]string{}, rulesFiles...)
+	for _, dir := range rulesDirs {
+		dirPaths, err := ruler.ListRulesFiles(dir)
+		if err != nil {
+			return config, err
+		}
+		paths = append(paths, dirPaths...)
+	}
+
+	customRules, err := ruler.LoadRulesFiles(paths...)
+	if err != nil {
+		return config, err
+	}
+	config.CustomRules = customRules
+
+	return config, nil
+}
+
+// exitPolicy returns the exit policy of the configuration file, or the policy
+// of the flags: objects fail with a score below their threshold or not above
+// zero, or with --fail-on-severity on findings at or above the severity only
+func exitPolicy(configured *ruler.ExitPolicy, minSeverity ruler.Severity) *ruler.ExitPolicy {
+	if configured != nil {
+		policy := *configured
+		if minSeverity != "" {
+			policy.FailOnSeverity = minSeverity
+		}
+		return &policy
+	}
+
+	policy := &ruler.ExitPolicy{}
+	if minSeverity != "" {
+		ignoreScore := true
+		policy.IgnoreScore = &ignoreScore
+		policy.FailOnSeverity = minSeverity
+	}
+	return policy
+}
+
+// collectFiles returns the files to scan of the arguments, the files of
+// directories being filtered by --include, --exclude and --ignore-file.
+// Standard input is scanned when it is the only argument.
+func collectFiles(args []string) ([]string, error) {
+	if len(args) == 1 && (args[0] == "-" || args[0] == "/dev/stdin") {
+		return args, nil
+	}
+
+	files, err := input.Collect(args, input.Options{Include: includes, Exclude: excludes, IgnoreFiles: ignoreFiles})
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no files to scan in %s", strings.Join(args, ", "))
+	}
+	return files, nil
+}
+
+// scanFiles scans the files with a pool of --concurrency workers and returns
+// their reports in the order of the files, and their contents by name. The
+// error of a single file is returned, the files of a larger scan get invalid
+// reports instead.
+func scanFiles(ruleset *ruler.Ruleset, files []string, schemaConfig ruler.SchemaConfig) ([]ruler.Report, map[string][]byte, error) {
+	results := make([][]ruler.Report, len(files))
+	inputs := make([]File, len(files))
+	errs := make([]error, len(files))
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < max(1, min(concurrency, len(files))); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				file, err := getInput(files[i : i+1])
+				if err != nil {
+					results[i] = []ruler.Report{ruler.NewErrorReport(files[i], 0, 1, ruler.ErrorReasonRead, err)}
+					errs[i] = err
+					continue
+				}
+				inputs[i] = file
+				reports, err := ruleset.Run(file.fileName, file.fileBytes, schemaConfig)
+				if err != nil {
+					reports = []ruler.Report{ruler.NewErrorReport(file.fileName, 0, 1, ruler.ErrorReasonInvalidInput, err)}
+					errs[i] = err
+				}
+				results[i] = reports
+			}
+		}()
+	}
+	for i := range files {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	if len(files) == 1 && errs[0] != nil {
+		return nil, nil, errs[0]
+	}
+
+	var reports []ruler.Report
+	sources := make(map[string][]byte, len(files))
+	for i, r := range results {
+		reports = append(reports, r...)
+		if inputs[i].fileName != "" {
+			sources[inputs[i].fileName] = inputs[i].fileBytes
+		}
+	}
+	return reports, sources, nil
+}
+
 var scanCmd = &cobra.Command{
-	Use:   `scan [file]`,
+	Use:   `scan [file|directory|glob]...`,
 	Short: "Scans Kubernetes resource YAML or JSON",
 	Example: `  kubesec scan ./deployment.yaml
+  kubesec scan ./manifests ./charts/*/rendered.yaml --exclude 'tests/'
   cat file.json | kubesec scan -
   helm template -f values.yaml ./chart | kubesec scan /dev/stdin`,
 	RunE: func(cmd *cobra.Command, args []string) error {
@@ -109,7 +301,13 @@ var scanCmd = &cobra.Command{
 		rootCmd.SilenceErrors = true
 		rootCmd.SilenceUsage = true
 
-		file, err := getInput(args)
+		if framework != "" {
+			if err := ruler.ValidateFramework(framework); err != nil {
+				return err
+			}
+		}
+
+		files, err := collectFiles(args)
 		if err != nil {
 			return err
 		}
@@ -128,31 +326,65 @@ var scanCmd = &cobra.Command{
 		schemaConfig.Locations = schemaLocations
 		schemaConfig.ValidatorOpts.KubernetesVersion = k8sVersion
 
-		ruleset, err := ruler.NewRuleset(logger, rulesIDs...)
+		var minSeverity ruler.Severity
+		if failOnSeverity != "" {
+			if minSeverity, err = ruler.ParseSeverity(failOnSeverity); err != nil {
+				return err
+			}
+		}
+
+		rulesetConfig, err := getRulesetConfig()
+		if err != nil {
+			return err
+		}
+
+		ruleset, err := ruler.NewRulesetWithConfig(logger, rulesetConfig, rulesIDs...)
 		if err != nil {
 			return err
 		}
-		reports, err := ruleset.Run(file.fileName, file.fileBytes, schemaConfig)
+		reports, sources, err := scanFiles(ruleset, files, schemaConfig)
 		if err != nil {
 			return err
 		}
 
 		if len(reports) == 0 {
-			return fmt.Errorf("invalid input %s", file.fileName)
+			return fmt.Errorf("invalid input %s", strings.Join(files, ", "))
 		}
 
-		var lowScore bool
-		for _, r := range reports {
-			if r.Score <= 0 {
-				lowScore = true
-				break
-			}
-		}
+		// the formats show the objects passing the policy of the exit code
+		policy := exitPolicy(ruleset.ExitPolicy, minSeverity)
 
+		var failed bool
 		var buff bytes.Buffer
-		err = report.WriteReports(format, &buff, reports, template)
-		if err != nil {
-			return err
+		if framework != "" {
+			complianceReports := make([]ruler.ComplianceReport, 0, len(reports))
+			for _, r := range reports {
+				cr := ruler.NewComplianceReport(r, framework)
+				if !cr.Passed {
+					failed = true
+				}
+				complianceReports = append(complianceReports, cr)
+			}
+
+			if err := report.WriteComplianceReports(format, &buff, complianceReports); err != nil {
+				return err
+			}
+		} else {
+			opts := report.Options{
+				Template:  template,
+				MaxLength: maxLength,
+				Rules:     ruleset.Rules,
+				Sources:   sources,
+				Policy:    policy,
+			}
+			if baselineFile != "" {
+				if opts.Baseline, err = report.LoadBaseline(baselineFile); err != nil {
+					return err
+				}
+			}
+			if err := report.WriteReportsWithOptions(format, &buff, reports, opts); err != nil {
+				return err
+			}
 		}
 
 		if outputLocation != "" {
@@ -165,7 +397,39 @@ var scanCmd = &cobra.Command{
 		out := buff.String()
 		fmt.Println(out)
 
-		if len(reports) > 0 && !lowScore {
+		if framework == "" {
+			schemaInvalid := schemaInvalidExitCode
+			if schemaInvalid == 0 {
+				schemaInvalid = exitCode
+			}
+			decision := policy.Decide(reports, ruler.ExitCodes{
+				PolicyFailed:  exitCode,
+				SchemaInvalid: schemaInvalid,
+				InvalidInput:  partialExitCode,
+			})
+			// the reasons are printed when the policy is configured or the
+			// scan fails, on stderr keeping the output parseable
+			if ruleset.ExitPolicy != nil || !decision.Passed() {
+				if err := decision.Write(os.Stderr); err != nil {
+					return err
+				}
+			}
+			if decision.Passed() {
+				return nil
+			}
+			os.Exit(decision.ExitCode)
+		}
+
+		for _, r := range reports {
+			if r.Error != nil {
+				os.Exit(partialExitCode)
+			}
+			if r.PodSecurity != nil && !r.PodSecurity.Allowed {
+				failed = true
+			}
+		}
+
+		if len(reports) > 0 && !failed {
 			return nil
 		}
 
@@ -0,0 +1,485 @@
+package fix
+
+import (
+	"bytes"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// edit replaces the bytes of a document between two offsets
+type edit struct {
+	start, end int
+	text       string
+}
+
+// source is the text of a document, the fixes are written as edits of the
+// bytes of the nodes they change so the other lines are kept as they are
+type source struct {
+	data []byte
+	// lines are the offsets of the starts of the lines
+	lines []int
+	// compact is set when the block sequences of the document start at the
+	// column of their key instead of being indented
+	compact bool
+	// newline ends the written lines, CRLF if the document uses it
+	newline string
+}
+
+func newSource(data []byte, original *yaml.Node) *source {
+	s := &source{data: data, lines: []int{0}, newline: "\n"}
+	for i, b := range data {
+		if b == '\n' {
+			s.lines = append(s.lines, i+1)
+		}
+	}
+	s.compact = compactSequence(original)
+	if bytes.Contains(data, []byte("\r\n")) {
+		s.newline = "\r\n"
+	}
+	return s
+}
+
+// patch returns the document with the changes of the fixed node, it returns
+// false if a change can not be located in the document
+func (s *source) patch(original, fixed *yaml.Node) ([]byte, bool) {
+	edits, ok := s.diff(original, fixed)
+	if !ok {
+		return nil, false
+	}
+
+	// insertions at the same offset stay in the order of the document
+	sort.SliceStable(edits, func(i, j int) bool { return edits[i].start < edits[j].start })
+
+	var buf bytes.Buffer
+	last := 0
+	for _, e := range edits {
+		if e.start < last {
+			return nil, false
+		}
+		buf.Write(s.data[last:e.start])
+		buf.WriteString(e.text)
+		last = e.end
+	}
+	buf.Write(s.data[last:])
+	return buf.Bytes(), true
+}
+
+// rewrite returns the document with its content encoded again, the bytes
+// before the content are kept
+func (s *source) rewrite(root *yaml.Node) ([]byte, error) {
+	content := root.Content[0]
+	start, ok := s.offset(content)
+	if !ok {
+		start = 0
+	}
+	// the comments before the content are in the kept bytes
+	content.HeadComment = ""
+	if len(content.Content) > 0 {
+		content.Content[0].HeadComment = ""
+	}
+
+	var buf bytes.Buffer
+	if err := encode(&buf, content); err != nil {
+		return nil, err
+	}
+	text := buf.String()
+	if s.compact {
+		text = compactSequences(text)
+	}
+	return append(append([]byte{}, s.data[:start]...), text...), nil
+}
+
+// diff returns the edits turning the original node into the fixed node. The
+// fixes only replace values and append entries to mappings and sequences.
+func (s *source) diff(original, fixed *yaml.Node) ([]edit, bool) {
+	if original.Kind == yaml.DocumentNode {
+		if len(original.Content) == 0 || len(fixed.Content) == 0 {
+			return nil, false
+		}
+		return s.diff(original.Content[0], fixed.Content[0])
+	}
+
+	if original.Style&yaml.FlowStyle != 0 {
+		// flow nodes are written again on their own
+		if !changed(fixed) {
+			return nil, true
+		}
+		start, ok := s.offset(original)
+		if !ok {
+			return nil, false
+		}
+		end, ok := s.flowEnd(start)
+		if !ok {
+			return nil, false
+		}
+		text, ok := s.encode(fixed)
+		return []edit{{start, end, strings.TrimSuffix(text, "\n")}}, ok
+	}
+
+	step := 1
+	switch original.Kind {
+	case yaml.MappingNode:
+		step = 2
+	case yaml.SequenceNode:
+	default:
+		return nil, true
+	}
+	if len(fixed.Content) < len(original.Content) {
+		return nil, false
+	}
+
+	var edits []edit
+	for i := step - 1; i < len(original.Content); i += step {
+		var e []edit
+		var ok bool
+		if fixed.Content[i].Line == 0 {
+			e, ok = s.replace(original, original.Content[i], fixed.Content[i])
+		} else {
+			e, ok = s.diff(original.Content[i], fixed.Content[i])
+		}
+		if !ok {
+			return nil, false
+		}
+		edits = append(edits, e...)
+	}
+
+	if appended := fixed.Content[len(original.Content):]; len(appended) > 0 {
+		e, ok := s.append(original, appended)
+		if !ok {
+			return nil, false
+		}
+		edits = append(edits, e)
+	}
+	return edits, true
+}
+
+// replace returns the edits replacing a scalar value of a block node
+func (s *source) replace(parent, original, fixed *yaml.Node) ([]edit, bool) {
+	if original.Kind != yaml.ScalarNode || original.Style&(yaml.LiteralStyle|yaml.FoldedStyle) != 0 {
+		return nil, false
+	}
+	start, ok := s.offset(original)
+	if !ok {
+		return nil, false
+	}
+	end, ok := s.scalarEnd(original, start)
+	if !ok {
+		return nil, false
+	}
+
+	if fixed.Kind == yaml.ScalarNode {
+		// strings keep their quotes
+		if original.Tag == "!!str" && fixed.Tag == "!!str" {
+			fixed.Style = original.Style
+		}
+		text, ok := s.encode(fixed)
+		return []edit{{start, end, strings.TrimSuffix(text, "\n")}}, ok
+	}
+
+	// a block value goes on the lines after the key of a mapping, the
+	// scalar is removed with the spaces before it
+	if parent.Kind != yaml.MappingNode || bytes.IndexByte(s.data[start:end], '\n') >= 0 {
+		return nil, false
+	}
+	key := parent.Content[0]
+	indent := key.Column - 1
+	if fixed.Kind == yaml.MappingNode || !s.compact {
+		indent += 2
+	}
+	text, ok := s.block(fixed, indent)
+	if !ok {
+		return nil, false
+	}
+	from := start
+	for from > 0 && s.data[from-1] == ' ' {
+		from--
+	}
+	at := s.nextLine(end)
+	if at == len(s.data) && !bytes.HasSuffix(s.data, []byte("\n")) {
+		text = s.newline + strings.TrimSuffix(text, s.newline)
+	}
+	return []edit{{from, end, ""}, {at, at, text}}, true
+}
+
+// append returns the edit appending entries to a block mapping or items to
+// a block sequence, on the lines after its last one
+func (s *source) append(node *yaml.Node, appended []*yaml.Node) (edit, bool) {
+	end, ok := s.end(node)
+	if !ok {
+		return edit{}, false
+	}
+
+	indent := node.Column - 1
+	if node.Kind == yaml.MappingNode {
+		indent = node.Content[0].Column - 1
+	}
+	text, ok := s.block(&yaml.Node{Kind: node.Kind, Content: appended}, indent)
+	if !ok {
+		return edit{}, false
+	}
+
+	at := s.nextLine(end)
+	if at == len(s.data) && !bytes.HasSuffix(s.data, []byte("\n")) {
+		text = s.newline + strings.TrimSuffix(text, s.newline)
+	}
+	return edit{at, at, text}, true
+}
+
+// encode returns the YAML of a node in the sequence style of the document
+func (s *source) encode(node *yaml.Node) (string, bool) {
+	var buf bytes.Buffer
+	if err := encode(&buf, node); err != nil {
+		return "", false
+	}
+	if s.compact {
+		return compactSequences(buf.String()), true
+	}
+	return buf.String(), true
+}
+
+// block returns the YAML of a block node indented to a column
+func (s *source) block(node *yaml.Node, indent int) (string, bool) {
+	text, ok := s.encode(node)
+	if !ok {
+		return "", false
+	}
+	lines := strings.SplitAfter(text, "\n")
+	for i, l := range lines {
+		if strings.TrimSpace(l) != "" {
+			lines[i] = strings.Repeat(" ", indent) + l
+		}
+	}
+	return strings.ReplaceAll(strings.Join(lines, ""), "\n", s.newline), true
+}
+
+// offset returns the byte offset of the position of a node
+func (s *source) offset(node *yaml.Node) (int, bool) {
+	if node.Line < 1 || node.Line > len(s.lines) {
+		return 0, false
+	}
+	// columns count characters
+	i := s.lines[node.Line-1]
+	for c := 1; c < node.Column; c++ {
+		if i >= len(s.data) || s.data[i] == '\n' {
+			return 0, false
+		}
+		_, size := utf8.DecodeRune(s.data[i:])
+		i += size
+	}
+	return i, true
+}
+
+// nextLine returns the offset of the line after the one of an offset
+func (s *source) nextLine(offset int) int {
+	if i := bytes.IndexByte(s.data[offset:], '\n'); i >= 0 {
+		return offset + i + 1
+	}
+	return len(s.data)
+}
+
+// end returns the offset of the end of the content of a node
+func (s *source) end(node *yaml.Node) (int, bool) {
+	start, ok := s.offset(node)
+	if !ok {
+		return 0, false
+	}
+	switch {
+	case node.Style&yaml.FlowStyle != 0:
+		return s.flowEnd(start)
+	case node.Kind == yaml.MappingNode || node.Kind == yaml.SequenceNode:
+		if len(node.Content) == 0 {
+			return 0, false
+		}
+		return s.end(node.Content[len(node.Content)-1])
+	case node.Kind == yaml.ScalarNode:
+		return s.scalarEnd(node, start)
+	}
+	return 0, false
+}
+
+// scalarEnd returns the offset of the end of a scalar starting at an offset
+func (s *source) scalarEnd(node *yaml.Node, start int) (int, bool) {
+	switch {
+	case node.Style&yaml.DoubleQuotedStyle != 0:
+		for i := start + 1; i < len(s.data); i++ {
+			switch s.data[i] {
+			case '\\':
+				i++
+			case '"':
+				return i + 1, true
+			}
+		}
+		return 0, false
+	case node.Style&yaml.SingleQuotedStyle != 0:
+		for i := start + 1; i < len(s.data); i++ {
+			if s.data[i] == '\'' {
+				if i+1 < len(s.data) && s.data[i+1] == '\'' {
+					i++
+					continue
+				}
+				return i + 1, true
+			}
+		}
+		return 0, false
+	case node.Style&(yaml.LiteralStyle|yaml.FoldedStyle) != 0:
+		return s.blockScalarEnd(start), true
+	}
+
+	// plain scalars are only located on a single line with no tag
+	line := s.data[start:s.nextLine(start)]
+	if i := commentStart.FindIndex(line); i != nil {
+		line = line[:i[0]]
+	}
+	line = bytes.TrimRight(line, " \t\r\n")
+	if string(line) != node.Value {
+		return 0, false
+	}
+	return start + len(line), true
+}
+
+// blockScalarEnd returns the offset of the end of the last line of a block
+// scalar, the lines more indented than its header
+func (s *source) blockScalarEnd(start int) int {
+	header := bytes.LastIndexByte(s.data[:start], '\n') + 1
+	indent := len(s.data[header:]) - len(bytes.TrimLeft(s.data[header:], " "))
+
+	end := s.nextLine(start) - 1
+	for i := s.nextLine(start); i < len(s.data); i = s.nextLine(i) {
+		line := bytes.TrimRight(s.data[i:s.nextLine(i)], "\r\n")
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		if len(line)-len(bytes.TrimLeft(line, " ")) <= indent {
+			break
+		}
+		end = i + len(line)
+	}
+	return end
+}
+
+// flowEnd returns the offset after the bracket closing the flow node at an
+// offset
+func (s *source) flowEnd(start int) (int, bool) {
+	depth := 0
+	for i := start; i < len(s.data); i++ {
+		switch c := s.data[i]; c {
+		case '{', '[':
+			depth++
+		case '}', ']':
+			depth--
+			if depth == 0 {
+				return i + 1, true
+			}
+		case '"', '\'':
+			end, ok := s.scalarEnd(&yaml.Node{Style: quoteStyle(c)}, i)
+			if !ok {
+				return 0, false
+			}
+			i = end - 1
+		case '#':
+			if i > 0 && (s.data[i-1] == ' ' || s.data[i-1] == '\t' || s.data[i-1] == '\n') {
+				i = s.nextLine(i) - 1
+			}
+		}
+	}
+	return 0, false
+}
+
+func quoteStyle(quote byte) yaml.Style {
+	if quote == '"' {
+		return yaml.DoubleQuotedStyle
+	}
+	return yaml.SingleQuotedStyle
+}
+
+var commentStart = regexp.MustCompile(`[ \t]#`)
+
+// changed reports whether a node or one of its children is not in the
+// document
+func changed(node *yaml.Node) bool {
+	if node.Line == 0 {
+		return true
+	}
+	for _, c := range node.Content {
+		if changed(c) {
+			return true
+		}
+	}
+	return false
+}
+
+// compactSequence reports whether the first block sequence which is the
+// value of a mapping starts at the column of its key
+func compactSequence(node *yaml.Node) bool {
+	compact, _ := findSequence(node)
+	return compact
+}
+
+func findSequence(node *yaml.Node) (compact, found bool) {
+	for i, c := range node.Content {
+		if node.Kind == yaml.MappingNode && i%2 == 1 && c.Kind == yaml.SequenceNode && c.Style&yaml.FlowStyle == 0 && len(c.Content) > 0 {
+			return c.Column == node.Content[i-1].Column, true
+		}
+		if compact, found := findSequence(c); found {
+			return compact, true
+		}
+	}
+	return false, false
+}
+
+var blockScalarHeader = regexp.MustCompile(`(^|[: ])[|>][0-9+-]*$`)
+
+// compactSequences moves the block sequences which are the values of a
+// mapping to the column of their key, yaml.v3 indents them
+func compactSequences(text string) string {
+	var b strings.Builder
+	// sequences are the columns of the dashes of the enclosing sequences
+	var sequences []int
+	// key is the column of the key of the previous line when it has no
+	// value, scalar the indent of the header of a block scalar
+	key, scalar := -1, -1
+	for _, line := range strings.SplitAfter(text, "\n") {
+		content := strings.TrimLeft(line, " ")
+		column := len(line) - len(content)
+		if strings.TrimSpace(line) == "" {
+			b.WriteString(line)
+			continue
+		}
+
+		for len(sequences) > 0 && column < sequences[len(sequences)-1] {
+			sequences = sequences[:len(sequences)-1]
+		}
+		shift := min(2*len(sequences), column)
+		if scalar >= 0 && column > scalar {
+			b.WriteString(line[shift:])
+			continue
+		}
+		scalar = -1
+
+		if key >= 0 && column == key+2 && strings.HasPrefix(content, "-") {
+			sequences = append(sequences, column)
+			shift = min(2*len(sequences), column)
+		}
+		b.WriteString(line[shift:])
+
+		// the key of the line is after the dashes of its items
+		value := strings.TrimRight(content, "\r\n")
+		keyColumn := column
+		for strings.HasPrefix(value, "- ") {
+			value = value[2:]
+			keyColumn += 2
+		}
+		key = -1
+		switch {
+		case strings.HasSuffix(value, ":"):
+			key = keyColumn
+		case blockScalarHeader.MatchString(value):
+			scalar = column
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,305 @@
+// Package fix remediates the findings of the built-in rules in YAML
+// manifests, keeping their comments, key order and document layout.
+package fix
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/controlplaneio/kubesec/v2/pkg/ruler"
+	"github.com/controlplaneio/kubesec/v2/pkg/rules"
+)
+
+// Operation is a RFC 6902 JSON patch operation
+type Operation struct {
+	Op    string      `json:"op" yaml:"op"`
+	Path  string      `json:"path" yaml:"path"`
+	Value interface{} `json:"value,omitempty" yaml:"value,omitempty"`
+}
+
+// Target identifies the object of a document
+type Target struct {
+	Kind      string `json:"kind" yaml:"kind"`
+	Name      string `json:"name" yaml:"name"`
+	Namespace string `json:"namespace,omitempty" yaml:"namespace,omitempty"`
+}
+
+// Document is a document of a manifest with its fixes
+type Document struct {
+	Target     Target
+	Operations []Operation
+	// Original and Fixed are the YAML of the document with its markers
+	// before and after the fixes, Fixed is Original if there is no fix
+	Original []byte
+	Fixed    []byte
+}
+
+// Result is a manifest with the fixes of its documents
+type Result struct {
+	Documents []Document
+	// skipped are the bytes before each document, the empty documents the
+	// decoder of scan skips
+	skipped [][]byte
+}
+
+// podSpec is the pod spec of an object with the JSON pointers of its fields
+type podSpec struct {
+	pointer    string
+	spec       map[string]interface{}
+	containers []container
+}
+
+type container struct {
+	pointer string
+	node    map[string]interface{}
+}
+
+// Fix applies the fixes of the rules to every document of a YAML manifest,
+// the safe fixes if no rule is given
+func Fix(data []byte, ruleIDs ...string) (*Result, error) {
+	selected, err := selectFixers(ruleIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	docs, err := ruler.SplitDocuments(data)
+	if err != nil {
+		return nil, err
+	}
+	result := &Result{}
+	offset := 0
+	for i, doc := range docs {
+		document, err := fixDocument(doc.Data, selected)
+		if err != nil {
+			return nil, fmt.Errorf("document %d: %w", i+1, err)
+		}
+		result.Documents = append(result.Documents, document)
+		result.skipped = append(result.skipped, data[offset:doc.Offset])
+		offset = doc.Offset + len(doc.Data)
+	}
+
+	return result, nil
+}
+
+func fixDocument(doc []byte, selected []Fixer) (Document, error) {
+	document := Document{Original: doc, Fixed: doc}
+	if len(bytes.TrimSpace(doc)) == 0 {
+		return document, nil
+	}
+
+	var root, original yaml.Node
+	if err := yaml.Unmarshal(doc, &root); err != nil {
+		return document, err
+	}
+	if err := yaml.Unmarshal(doc, &original); err != nil {
+		return document, err
+	}
+	if root.Kind != yaml.DocumentNode || len(root.Content) == 0 || root.Content[0].Kind != yaml.MappingNode {
+		return document, nil
+	}
+
+	for _, f := range selected {
+		// fixers see the changes of the previous ones
+		var object map[string]interface{}
+		if err := root.Content[0].Decode(&object); err != nil {
+			return document, err
+		}
+		document.Target = getTarget(object)
+
+		specs := newPodSpecs(object)
+		if len(specs) == 0 {
+			return document, nil
+		}
+		for _, p := range specs {
+			for _, op := range f.fix(p) {
+				if err := apply(root.Content[0], op); err != nil {
+					return document, fmt.Errorf("%s: %w", f.RuleID, err)
+				}
+				document.Operations = append(document.Operations, op)
+			}
+		}
+	}
+
+	if len(document.Operations) == 0 {
+		return document, nil
+	}
+
+	// only the changed nodes are written, the whole content of the document
+	// if one of them can not be located
+	s := newSource(doc, &original)
+	if fixed, ok := s.patch(&original, &root); ok {
+		document.Fixed = fixed
+		return document, nil
+	}
+	fixed, err := s.rewrite(&root)
+	if err != nil {
+		return document, err
+	}
+	document.Fixed = fixed
+
+	return document, nil
+}
+
+func getTarget(object map[string]interface{}) Target {
+	var t Target
+	t.Kind, _ = object["kind"].(string)
+	t.Name, _ = get(object, "metadata", "name").(string)
+	t.Namespace, _ = get(object, "metadata", "namespace").(string)
+	return t
+}
+
+// newPodSpecs returns the pod specs of an object holding containers, located
+// as the rules locate them, pod spec mappings included
+func newPodSpecs(object map[string]interface{}) []*podSpec {
+	// the spec selectors of the rules expect JSON
+	data, err := json.Marshal(object)
+	if err != nil {
+		return nil
+	}
+
+	var specs []*podSpec
+	for _, selector := range rules.SpecSelectors(data) {
+		if p, ok := newPodSpec(object, selector); ok {
+			specs = append(specs, p)
+		}
+	}
+	return specs
+}
+
+func newPodSpec(object map[string]interface{}, selector string) (*podSpec, bool) {
+	spec, ok := get(object, strings.Split(selector, ".")...).(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+
+	p := &podSpec{
+		pointer: "/" + strings.ReplaceAll(selector, ".", "/"),
+		spec:    spec,
+	}
+	for _, field := range []string{"initContainers", "containers", "ephemeralContainers"} {
+		items, _ := spec[field].([]interface{})
+		for i, item := range items {
+			if node, ok := item.(map[string]interface{}); ok {
+				p.containers = append(p.containers, container{
+					pointer: fmt.Sprintf("%s/%s/%d", p.pointer, field, i),
+					node:    node,
+				})
+			}
+		}
+	}
+	if len(p.containers) == 0 {
+		return nil, false
+	}
+
+	return p, true
+}
+
+// Manifest returns the fixed manifest
+func (r *Result) Manifest() []byte {
+	var buf bytes.Buffer
+	for i, d := range r.Documents {
+		buf.Write(r.skipped[i])
+		buf.Write(d.Fixed)
+	}
+	return buf.Bytes()
+}
+
+// Changed reports whether any document was fixed
+func (r *Result) Changed() bool {
+	for _, d := range r.Documents {
+		if len(d.Operations) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// apply applies an add or replace operation to a YAML node
+func apply(root *yaml.Node, op Operation) error {
+	tokens := strings.Split(strings.TrimPrefix(op.Path, "/"), "/")
+	for i := range tokens {
+		tokens[i] = unescape(tokens[i])
+	}
+
+	parent := root
+	for _, token := range tokens[:len(tokens)-1] {
+		child := lookup(parent, token)
+		if child == nil {
+			return fmt.Errorf("path %s not found", op.Path)
+		}
+		parent = child
+	}
+
+	var value yaml.Node
+	if err := value.Encode(op.Value); err != nil {
+		return err
+	}
+
+	last := tokens[len(tokens)-1]
+	switch parent.Kind {
+	case yaml.MappingNode:
+		for i := 0; i < len(parent.Content); i += 2 {
+			if parent.Content[i].Value == last {
+				keepComments(parent.Content[i+1], &value)
+				parent.Content[i+1] = &value
+				return nil
+			}
+		}
+		if op.Op == "replace" {
+			return fmt.Errorf("path %s not found", op.Path)
+		}
+		key := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: last}
+		parent.Content = append(parent.Content, key, &value)
+	case yaml.SequenceNode:
+		if last == "-" {
+			parent.Content = append(parent.Content, &value)
+			return nil
+		}
+		i, err := strconv.Atoi(last)
+		if err != nil || i < 0 || i >= len(parent.Content) {
+			return fmt.Errorf("invalid index in path %s", op.Path)
+		}
+		keepComments(parent.Content[i], &value)
+		parent.Content[i] = &value
+	default:
+		return fmt.Errorf("path %s not found", op.Path)
+	}
+	return nil
+}
+
+// keepComments moves the comments of a replaced node to its replacement
+func keepComments(old, replacement *yaml.Node) {
+	replacement.HeadComment = old.HeadComment
+	replacement.LineComment = old.LineComment
+	replacement.FootComment = old.FootComment
+}
+
+func lookup(node *yaml.Node, token string) *yaml.Node {
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i < len(node.Content); i += 2 {
+			if node.Content[i].Value == token {
+				return node.Content[i+1]
+			}
+		}
+	case yaml.SequenceNode:
+		if i, err := strconv.Atoi(token); err == nil && i >= 0 && i < len(node.Content) {
+			return node.Content[i]
+		}
+	}
+	return nil
+}
+
+// escape and unescape JSON pointer tokens
+func escape(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+}
+
+func unescape(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+}
@@ -0,0 +1,463 @@
+package fix
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/controlplaneio/kubesec/v2/pkg/rules"
+)
+
+func TestFix_KeepsCommentsAndKeyOrder(t *testing.T) {
+	var data = `# the web server
+apiVersion: v1
+kind: Pod
+metadata:
+  name: web # inline comment
+spec:
+  containers:
+    - name: app
+      image: nginx
+      securityContext:
+        # keep this
+        privileged: false
+`
+	result, err := Fix([]byte(data), "CapDropAll")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	wanted := `# the web server
+apiVersion: v1
+kind: Pod
+metadata:
+  name: web # inline comment
+spec:
+  containers:
+    - name: app
+      image: nginx
+      securityContext:
+        # keep this
+        privileged: false
+        capabilities:
+          drop:
+            - ALL
+`
+	if got := string(result.Manifest()); got != wanted {
+		t.Errorf("Got manifest\n%s\nwanted\n%s", got, wanted)
+	}
+}
+
+func TestFix_KeepsLayout(t *testing.T) {
+	var tests = []struct {
+		name   string
+		rules  []string
+		data   string
+		wanted string
+		// fixed are the numbers of the lines the fixes change
+		fixed map[int]bool
+	}{
+		{
+			name:  "compact sequences, comments and flow nodes",
+			rules: []string{"CapDropAll", "AllowPrivilegeEscalation", "SeccompUnconfined", "RunAsNonRoot", "Privileged"},
+			data: `---  # web
+apiVersion: v1
+kind: Pod
+metadata:
+  name: web   # the name
+spec:
+  securityContext:
+    seccompProfile: {type: Unconfined}
+  containers:
+  - name: app   # c
+    image: "nginx"
+    securityContext:
+      privileged: true
+      runAsNonRoot: false
+      capabilities:
+        drop: [NET_RAW]
+  - name: sidecar
+    image: envoy
+    securityContext:
+    command:
+    - |
+      echo hi
+
+      echo bye
+...
+`,
+			wanted: `---  # web
+apiVersion: v1
+kind: Pod
+metadata:
+  name: web   # the name
+spec:
+  securityContext:
+    seccompProfile: {type: RuntimeDefault}
+    runAsNonRoot: true
+  containers:
+  - name: app   # c
+    image: "nginx"
+    securityContext:
+      privileged: false
+      runAsNonRoot: true
+      capabilities:
+        drop: [NET_RAW, ALL]
+      allowPrivilegeEscalation: false
+  - name: sidecar
+    image: envoy
+    securityContext:
+      capabilities:
+        drop:
+        - ALL
+      allowPrivilegeEscalation: false
+    command:
+    - |
+      echo hi
+
+      echo bye
+...
+`,
+			fixed: map[int]bool{8: true, 13: true, 14: true, 16: true},
+		},
+		{
+			name:   "CRLF without a final line break",
+			rules:  []string{"AllowPrivilegeEscalation"},
+			data:   "kind: Pod\r\nspec:\r\n  containers:\r\n    - name: app\r\n      image: 'nginx'",
+			wanted: "kind: Pod\r\nspec:\r\n  containers:\r\n    - name: app\r\n      image: 'nginx'\r\n      securityContext:\r\n        allowPrivilegeEscalation: false",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Fix([]byte(tt.data), tt.rules...)
+			if err != nil {
+				t.Fatal(err.Error())
+			}
+			if got := string(result.Manifest()); got != tt.wanted {
+				t.Errorf("Got manifest\n%q\nwanted\n%q", got, tt.wanted)
+			}
+
+			// the lines which are not fixed are kept as they are, in order
+			var i int
+			lines := strings.Split(string(result.Manifest()), "\n")
+			for n, line := range strings.Split(tt.data, "\n") {
+				if tt.fixed[n+1] {
+					continue
+				}
+				for i < len(lines) && strings.TrimSuffix(lines[i], "\r") != strings.TrimSuffix(line, "\r") {
+					i++
+				}
+				if i == len(lines) {
+					t.Fatalf("Got manifest without the line %d %q", n+1, line)
+				}
+				i++
+			}
+		})
+	}
+}
+
+func TestFix_MultiDocument(t *testing.T) {
+	var configMap = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cfg
+data:
+  key:    "value"
+`
+	var data = `---
+apiVersion: v1
+kind: Pod
+metadata:
+  name: web
+spec:
+  containers:
+    - name: app
+      image: nginx
+--- # the config
+` + configMap
+
+	result, err := Fix([]byte(data))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	// documents are split the way scan reads them
+	if len(result.Documents) != 2 {
+		t.Fatalf("Got %d documents wanted %d", len(result.Documents), 2)
+	}
+	if string(result.Documents[1].Fixed) != "--- # the config\n"+configMap {
+		t.Errorf("Got unfixed document\n%s\nwanted\n%s", result.Documents[1].Fixed, configMap)
+	}
+
+	manifest := string(result.Manifest())
+	if !strings.HasPrefix(manifest, "---\n") {
+		t.Errorf("Got manifest without the leading separator\n%s", manifest)
+	}
+	if !strings.HasSuffix(manifest, "--- # the config\n"+configMap) {
+		t.Errorf("Got manifest without the config map\n%s", manifest)
+	}
+	if !result.Changed() {
+		t.Errorf("Got unchanged result wanted changed")
+	}
+}
+
+func TestFix_Unchanged(t *testing.T) {
+	var data = `apiVersion: v1
+kind: Pod
+metadata:
+  name: web
+spec:
+  automountServiceAccountToken: false
+  securityContext:
+    seccompProfile:
+      type: RuntimeDefault
+  containers:
+    - name: app
+      image: nginx
+      securityContext:
+        allowPrivilegeEscalation: false
+        capabilities:
+          drop: ["all"]
+`
+	result, err := Fix([]byte(data))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if result.Changed() {
+		t.Errorf("Got changed result with operations %v", result.Documents[0].Operations)
+	}
+	if string(result.Manifest()) != data {
+		t.Errorf("Got manifest\n%s\nwanted\n%s", result.Manifest(), data)
+	}
+}
+
+func TestFix_Operations(t *testing.T) {
+	var tests = []struct {
+		name    string
+		rules   []string
+		data    string
+		wanted  []Operation
+		wantErr bool
+	}{
+		{
+			name:  "fixes compose on a missing security context",
+			rules: []string{"AllowPrivilegeEscalation", "CapDropAll"},
+			data: `apiVersion: v1
+kind: Pod
+metadata:
+  name: web
+spec:
+  containers:
+    - name: app
+      image: nginx
+`,
+			wanted: []Operation{
+				{Op: "add", Path: "/spec/containers/0/securityContext", Value: map[string]interface{}{
+					"capabilities": map[string]interface{}{"drop": []interface{}{"ALL"}},
+				}},
+				{Op: "add", Path: "/spec/containers/0/securityContext/allowPrivilegeEscalation", Value: false},
+			},
+		},
+		{
+			name:  "cron job pod spec",
+			rules: []string{"AutomountServiceAccountToken", "ReadOnlyRootFilesystem"},
+			data: `apiVersion: batch/v1
+kind: CronJob
+metadata:
+  name: backup
+spec:
+  schedule: "0 0 * * *"
+  jobTemplate:
+    spec:
+      template:
+        spec:
+          initContainers:
+            - name: init
+              image: busybox
+          containers:
+            - name: backup
+              image: busybox
+              securityContext:
+                readOnlyRootFilesystem: false
+`,
+			wanted: []Operation{
+				{Op: "add", Path: "/spec/jobTemplate/spec/template/spec/automountServiceAccountToken", Value: false},
+				{Op: "add", Path: "/spec/jobTemplate/spec/template/spec/initContainers/0/securityContext", Value: map[string]interface{}{
+					"readOnlyRootFilesystem": true,
+				}},
+				{Op: "replace", Path: "/spec/jobTemplate/spec/template/spec/containers/0/securityContext/readOnlyRootFilesystem", Value: true},
+			},
+		},
+		{
+			name:  "only privileged containers",
+			rules: []string{"Privileged", "SeccompUnconfined"},
+			data: `apiVersion: v1
+kind: Pod
+metadata:
+  name: web
+spec:
+  securityContext:
+    seccompProfile:
+      type: Unconfined
+  containers:
+    - name: app
+      image: nginx
+    - name: sidecar
+      image: envoy
+      securityContext:
+        privileged: true
+`,
+			wanted: []Operation{
+				{Op: "replace", Path: "/spec/securityContext/seccompProfile/type", Value: "RuntimeDefault"},
+				{Op: "replace", Path: "/spec/containers/1/securityContext/privileged", Value: false},
+			},
+		},
+		{
+			name:  "knative revision pod spec",
+			rules: []string{"AutomountServiceAccountToken", "Privileged"},
+			data: `apiVersion: serving.knative.dev/v1
+kind: Revision
+metadata:
+  name: web
+spec:
+  containers:
+    - name: app
+      image: nginx
+      securityContext:
+        privileged: true
+`,
+			wanted: []Operation{
+				{Op: "add", Path: "/spec/automountServiceAccountToken", Value: false},
+				{Op: "replace", Path: "/spec/containers/0/securityContext/privileged", Value: false},
+			},
+		},
+		{
+			name:    "unknown rule",
+			rules:   []string{"CapDropAll", "NoSuchRule"},
+			data:    `kind: Pod`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Fix([]byte(tt.data), tt.rules...)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Got no error wanted error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err.Error())
+			}
+
+			got, _ := json.Marshal(result.Documents[0].Operations)
+			wanted, _ := json.Marshal(tt.wanted)
+			if string(got) != string(wanted) {
+				t.Errorf("Got operations %s wanted %s", got, wanted)
+			}
+		})
+	}
+}
+
+func TestFix_PodSpecMappings(t *testing.T) {
+	// the mapping of a kind no other test uses stays registered
+	err := rules.RegisterPodSpecMappings(rules.PodSpecMapping{
+		APIVersion: "example.com",
+		Kind:       "Worker",
+		Paths:      []string{"spec.server.spec", "spec.sidecar.spec"},
+	})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	var data = `apiVersion: example.com/v1
+kind: Worker
+metadata:
+  name: web
+spec:
+  server:
+    spec:
+      containers:
+        - name: app
+          image: nginx
+  sidecar:
+    spec:
+      containers:
+        - name: envoy
+          image: envoy
+`
+	result, err := Fix([]byte(data), "AllowPrivilegeEscalation")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	// every pod spec of the mapping is fixed
+	wanted := []Operation{
+		{Op: "add", Path: "/spec/server/spec/containers/0/securityContext", Value: map[string]interface{}{
+			"allowPrivilegeEscalation": false,
+		}},
+		{Op: "add", Path: "/spec/sidecar/spec/containers/0/securityContext", Value: map[string]interface{}{
+			"allowPrivilegeEscalation": false,
+		}},
+	}
+	got, _ := json.Marshal(result.Documents[0].Operations)
+	wantedJSON, _ := json.Marshal(wanted)
+	if string(got) != string(wantedJSON) {
+		t.Errorf("Got operations %s wanted %s", got, wantedJSON)
+	}
+}
+
+func TestResult_Patches(t *testing.T) {
+	var data = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: prod
+spec:
+  template:
+    spec:
+      automountServiceAccountToken: true
+      containers:
+        - name: app
+          image: nginx
+`
+	result, err := Fix([]byte(data), "AutomountServiceAccountToken")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	var buf bytes.Buffer
+	if err := result.WriteJSONPatch(&buf); err != nil {
+		t.Fatal(err.Error())
+	}
+	var patches []Patch
+	if err := json.Unmarshal(buf.Bytes(), &patches); err != nil {
+		t.Fatal(err.Error())
+	}
+	if len(patches) != 1 || patches[0].Target != (Target{Kind: "Deployment", Name: "web", Namespace: "prod"}) {
+		t.Errorf("Got patches %v", patches)
+	}
+
+	buf.Reset()
+	if err := result.WriteKustomize(&buf); err != nil {
+		t.Fatal(err.Error())
+	}
+	wanted := `patches:
+  - target:
+      kind: Deployment
+      name: web
+      namespace: prod
+    patch: |
+      - op: replace
+        path: /spec/template/spec/automountServiceAccountToken
+        value: false
+`
+	if buf.String() != wanted {
+		t.Errorf("Got kustomize patches\n%s\nwanted\n%s", buf.String(), wanted)
+	}
+}
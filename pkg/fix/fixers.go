@@ -0,0 +1,265 @@
+package fix
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Fixer remediates the findings of a rule
+type Fixer struct {
+	// RuleID is the ID of the rule the fixer remediates
+	RuleID      string `json:"ruleID"`
+	Description string `json:"description"`
+	// Safe fixers are applied by default, others have to be opted in as
+	// they may prevent workloads from running
+	Safe bool `json:"safe"`
+
+	fix func(p *podSpec) []Operation
+}
+
+// fixers are the available fixers, in the order they are applied
+var fixers = []Fixer{
+	{
+		RuleID:      "CapDropAll",
+		Description: "Drop ALL capabilities in every container",
+		Safe:        true,
+		fix:         fixCapDropAll,
+	},
+	{
+		RuleID:      "AllowPrivilegeEscalation",
+		Description: "Set allowPrivilegeEscalation to false in every container",
+		Safe:        true,
+		fix: func(p *podSpec) []Operation {
+			return p.setInContainers(false, "securityContext", "allowPrivilegeEscalation")
+		},
+	},
+	{
+		RuleID:      "SeccompAny",
+		Description: "Add a RuntimeDefault seccomp profile to the pod security context",
+		Safe:        true,
+		fix:         fixSeccompAny,
+	},
+	{
+		RuleID:      "AutomountServiceAccountToken",
+		Description: "Set automountServiceAccountToken to false in the pod spec",
+		Safe:        true,
+		fix: func(p *podSpec) []Operation {
+			return ops(set(p.spec, p.pointer, false, "automountServiceAccountToken"))
+		},
+	},
+	{
+		RuleID:      "SeccompUnconfined",
+		Description: "Replace Unconfined seccomp profiles with RuntimeDefault",
+		fix:         fixSeccompUnconfined,
+	},
+	{
+		RuleID:      "ReadOnlyRootFilesystem",
+		Description: "Set readOnlyRootFilesystem to true in every container",
+		fix: func(p *podSpec) []Operation {
+			return p.setInContainers(true, "securityContext", "readOnlyRootFilesystem")
+		},
+	},
+	{
+		RuleID:      "RunAsNonRoot",
+		Description: "Set runAsNonRoot to true in the pod security context, and in the containers setting it to false",
+		fix:         fixRunAsNonRoot,
+	},
+	{
+		RuleID:      "Privileged",
+		Description: "Set privileged to false in the privileged containers",
+		fix: func(p *podSpec) []Operation {
+			var operations []Operation
+			for _, c := range p.containers {
+				if get(c.node, "securityContext", "privileged") == true {
+					operations = append(operations, ops(set(c.node, c.pointer, false, "securityContext", "privileged"))...)
+				}
+			}
+			return operations
+		},
+	},
+}
+
+// Fixers returns the available fixers
+func Fixers() []Fixer {
+	return append([]Fixer{}, fixers...)
+}
+
+// DefaultRules returns the IDs of the rules of the safe fixers
+func DefaultRules() []string {
+	var ids []string
+	for _, f := range fixers {
+		if f.Safe {
+			ids = append(ids, f.RuleID)
+		}
+	}
+	return ids
+}
+
+// selectFixers returns the fixers of the rules, in application order
+func selectFixers(ruleIDs []string) ([]Fixer, error) {
+	if len(ruleIDs) == 0 {
+		ruleIDs = DefaultRules()
+	}
+
+	var unknown []string
+	for _, id := range ruleIDs {
+		var found bool
+		for _, f := range fixers {
+			if f.RuleID == id {
+				found = true
+				break
+			}
+		}
+		if !found {
+			unknown = append(unknown, id)
+		}
+	}
+	if len(unknown) > 0 {
+		return nil, fmt.Errorf("no fix available for rules: %s", strings.Join(unknown, ", "))
+	}
+
+	var selected []Fixer
+	for _, f := range fixers {
+		for _, id := range ruleIDs {
+			if f.RuleID == id {
+				selected = append(selected, f)
+				break
+			}
+		}
+	}
+	return selected, nil
+}
+
+func fixCapDropAll(p *podSpec) []Operation {
+	var operations []Operation
+	for _, c := range p.containers {
+		drop, ok := get(c.node, "securityContext", "capabilities", "drop").([]interface{})
+		if !ok {
+			operations = append(operations, ops(set(c.node, c.pointer, []interface{}{"ALL"}, "securityContext", "capabilities", "drop"))...)
+			continue
+		}
+
+		var all bool
+		for _, capability := range drop {
+			if s, ok := capability.(string); ok && strings.EqualFold(s, "ALL") {
+				all = true
+			}
+		}
+		if !all {
+			operations = append(operations, Operation{
+				Op:    "add",
+				Path:  c.pointer + "/securityContext/capabilities/drop/-",
+				Value: "ALL",
+			})
+		}
+	}
+	return operations
+}
+
+func fixSeccompAny(p *podSpec) []Operation {
+	if get(p.spec, "securityContext", "seccompProfile", "type") != nil {
+		return nil
+	}
+	return ops(set(p.spec, p.pointer, "RuntimeDefault", "securityContext", "seccompProfile", "type"))
+}
+
+func fixSeccompUnconfined(p *podSpec) []Operation {
+	var operations []Operation
+	nodes := []struct {
+		node    map[string]interface{}
+		pointer string
+	}{{p.spec, p.pointer}}
+	for _, c := range p.containers {
+		nodes = append(nodes, struct {
+			node    map[string]interface{}
+			pointer string
+		}{c.node, c.pointer})
+	}
+
+	for _, n := range nodes {
+		if get(n.node, "securityContext", "seccompProfile", "type") == "Unconfined" {
+			operations = append(operations, ops(set(n.node, n.pointer, "RuntimeDefault", "securityContext", "seccompProfile", "type"))...)
+		}
+	}
+	return operations
+}
+
+func fixRunAsNonRoot(p *podSpec) []Operation {
+	var operations []Operation
+	if get(p.spec, "securityContext", "runAsNonRoot") != true {
+		operations = append(operations, ops(set(p.spec, p.pointer, true, "securityContext", "runAsNonRoot"))...)
+	}
+	for _, c := range p.containers {
+		if get(c.node, "securityContext", "runAsNonRoot") == false {
+			operations = append(operations, ops(set(c.node, c.pointer, true, "securityContext", "runAsNonRoot"))...)
+		}
+	}
+	return operations
+}
+
+// setInContainers sets the value at the keys of every container
+func (p *podSpec) setInContainers(value interface{}, keys ...string) []Operation {
+	var operations []Operation
+	for _, c := range p.containers {
+		operations = append(operations, ops(set(c.node, c.pointer, value, keys...))...)
+	}
+	return operations
+}
+
+// set returns the operation setting the value at the keys of the node found
+// at pointer, creating the missing objects. It returns nil if the value is
+// already set.
+func set(node map[string]interface{}, pointer string, value interface{}, keys ...string) *Operation {
+	for i, key := range keys {
+		child, ok := node[key]
+		if !ok {
+			return &Operation{Op: "add", Path: pointer + "/" + escape(key), Value: nest(value, keys[i+1:]...)}
+		}
+
+		pointer += "/" + escape(key)
+		if i == len(keys)-1 {
+			if reflect.DeepEqual(child, value) {
+				return nil
+			}
+			return &Operation{Op: "replace", Path: pointer, Value: value}
+		}
+
+		m, ok := child.(map[string]interface{})
+		if !ok {
+			return &Operation{Op: "replace", Path: pointer, Value: nest(value, keys[i+1:]...)}
+		}
+		node = m
+	}
+	return nil
+}
+
+// nest wraps the value in objects with the keys
+func nest(value interface{}, keys ...string) interface{} {
+	for i := len(keys) - 1; i >= 0; i-- {
+		value = map[string]interface{}{keys[i]: value}
+	}
+	return value
+}
+
+func ops(operations ...*Operation) []Operation {
+	var result []Operation
+	for _, op := range operations {
+		if op != nil {
+			result = append(result, *op)
+		}
+	}
+	return result
+}
+
+// get returns the value at the keys of a node, nil if missing
+func get(node interface{}, keys ...string) interface{} {
+	for _, key := range keys {
+		m, ok := node.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		node = m[key]
+	}
+	return node
+}
@@ -0,0 +1,84 @@
+package fix
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"gopkg.in/yaml.v3"
+)
+
+// Patch is the JSON patch of an object
+type Patch struct {
+	Target Target      `json:"target"`
+	Patch  []Operation `json:"patch"`
+}
+
+// Patches returns the JSON patches of the fixed documents
+func (r *Result) Patches() []Patch {
+	patches := make([]Patch, 0)
+	for _, d := range r.Documents {
+		if len(d.Operations) > 0 {
+			patches = append(patches, Patch{Target: d.Target, Patch: d.Operations})
+		}
+	}
+	return patches
+}
+
+// WriteJSONPatch writes the RFC 6902 JSON patch of every fixed object
+func (r *Result) WriteJSONPatch(w io.Writer) error {
+	out, err := json.MarshalIndent(r.Patches(), "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, string(out))
+	return err
+}
+
+// kustomizePatch is a patch of the patches field of a kustomization
+type kustomizePatch struct {
+	Target Target `yaml:"target"`
+	Patch  string `yaml:"patch"`
+}
+
+// WriteKustomize writes the patches field of a kustomization applying the fixes
+func (r *Result) WriteKustomize(w io.Writer) error {
+	patches := make([]kustomizePatch, 0)
+	for _, p := range r.Patches() {
+		var patch bytes.Buffer
+		if err := encode(&patch, p.Patch); err != nil {
+			return err
+		}
+		patches = append(patches, kustomizePatch{Target: p.Target, Patch: patch.String()})
+	}
+
+	return encode(w, map[string]interface{}{"patches": patches})
+}
+
+// encode writes the YAML of the value with the indentation of manifests
+func encode(w io.Writer, v interface{}) error {
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(v); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// WriteDiff writes the unified diff of the manifest and the fixed manifest
+func (r *Result) WriteDiff(w io.Writer, original []byte, fileName string) error {
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(string(original)),
+		B:        difflib.SplitLines(string(r.Manifest())),
+		FromFile: fileName,
+		ToFile:   fileName + " (fixed)",
+		Context:  3,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprint(w, diff)
+	return err
+}
@@ -0,0 +1,169 @@
+package input
+
+import (
+	"bufio"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	pathspec "github.com/shibumi/go-pathspec"
+)
+
+// DefaultInclude are the patterns of the files scanned in directories
+var DefaultInclude = []string{"*.yaml", "*.yml", "*.json"}
+
+// Options filter the files found in directories. Patterns follow the
+// .gitignore syntax and match the paths relative to the scanned directory,
+// e.g. *.yaml matches the YAML files at any depth and charts/**/templates/
+// matches the templates directories under charts.
+type Options struct {
+	// Include are the patterns of the files to scan, DefaultInclude if empty
+	Include []string
+	// Exclude are the patterns of the files and directories to skip
+	Exclude []string
+	// IgnoreFiles are .gitignore style files holding more patterns to skip
+	IgnoreFiles []string
+}
+
+// Collect returns the files to scan for the paths, in order and without
+// duplicates. Files are returned as given, directories are walked
+// recursively for the files matching the options, and glob patterns are
+// expanded to the files and directories they match.
+func Collect(paths []string, opts Options) ([]string, error) {
+	include := opts.Include
+	if len(include) == 0 {
+		include = DefaultInclude
+	}
+
+	exclude := append([]string{}, opts.Exclude...)
+	for _, f := range opts.IgnoreFiles {
+		patterns, err := readIgnoreFile(f)
+		if err != nil {
+			return nil, err
+		}
+		exclude = append(exclude, patterns...)
+	}
+
+	var files []string
+	seen := make(map[string]bool)
+	add := func(file string) {
+		if !seen[file] {
+			seen[file] = true
+			files = append(files, file)
+		}
+	}
+
+	for _, path := range paths {
+		matches := []string{path}
+		if isGlob(path) {
+			var err error
+			if matches, err = filepath.Glob(path); err != nil {
+				return nil, fmt.Errorf("%s: %w", path, err)
+			}
+			if len(matches) == 0 {
+				return nil, fmt.Errorf("%s: no files match the pattern", path)
+			}
+		}
+
+		for _, match := range matches {
+			info, err := os.Stat(match)
+			if err != nil {
+				return nil, err
+			}
+			if !info.IsDir() {
+				add(match)
+				continue
+			}
+
+			dirFiles, err := walk(match, include, exclude)
+			if err != nil {
+				return nil, err
+			}
+			for _, f := range dirFiles {
+				add(f)
+			}
+		}
+	}
+
+	return files, nil
+}
+
+// walk returns the files of a directory and its subdirectories matching the
+// include patterns and none of the exclude patterns, sorted by path
+func walk(dir string, include, exclude []string) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil || rel == "." {
+			return err
+		}
+
+		if d.IsDir() {
+			if d.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			if skip, err := pathspec.GitIgnore(exclude, rel+"/"); err != nil || skip {
+				if err == nil {
+					err = filepath.SkipDir
+				}
+				return err
+			}
+			return nil
+		}
+
+		if skip, err := pathspec.GitIgnore(exclude, rel); err != nil || skip {
+			return err
+		}
+		if ok, err := pathspec.GitIgnore(include, rel); err != nil || !ok {
+			return err
+		}
+		files = append(files, path)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Strings(files)
+	return files, nil
+}
+
+// readIgnoreFile returns the patterns of a .gitignore style file, without its
+// blank lines and comments
+func readIgnoreFile(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var patterns []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		patterns = append(patterns, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return patterns, nil
+}
+
+// isGlob reports whether a path holds glob metacharacters and does not exist
+// as is
+func isGlob(path string) bool {
+	if !strings.ContainsAny(path, "*?[") {
+		return false
+	}
+	_, err := os.Stat(path)
+	return err != nil
+}
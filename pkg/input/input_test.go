@@ -0,0 +1,119 @@
+package input
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestCollect(t *testing.T) {
+	dir := t.TempDir()
+	for _, f := range []string{
+		"app/deployment.yaml",
+		"app/service.yml",
+		"app/README.md",
+		"app/charts/values.yaml",
+		"app/charts/templates/pod.yaml",
+		"db/statefulset.json",
+		"db/secret.yaml",
+		".git/config.yaml",
+		"kubesecignore",
+	} {
+		path := filepath.Join(dir, f)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err.Error())
+		}
+		if err := os.WriteFile(path, []byte("kind: Pod\n"), 0644); err != nil {
+			t.Fatal(err.Error())
+		}
+	}
+	ignoreFile := filepath.Join(dir, "kubesecignore")
+	if err := os.WriteFile(ignoreFile, []byte("# secrets are not workloads\nsecret.yaml\n"), 0644); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	join := func(files ...string) []string {
+		for i, f := range files {
+			files[i] = filepath.Join(dir, f)
+		}
+		return files
+	}
+
+	var tests = []struct {
+		name     string
+		paths    []string
+		opts     Options
+		expected []string
+	}{
+		{
+			name:  "directory",
+			paths: []string{dir},
+			expected: join(
+				"app/charts/templates/pod.yaml",
+				"app/charts/values.yaml",
+				"app/deployment.yaml",
+				"app/service.yml",
+				"db/secret.yaml",
+				"db/statefulset.json",
+			),
+		},
+		{
+			name:     "include and exclude",
+			paths:    []string{dir},
+			opts:     Options{Include: []string{"*.yaml"}, Exclude: []string{"charts/"}},
+			expected: join("app/deployment.yaml", "db/secret.yaml"),
+		},
+		{
+			name:     "double star",
+			paths:    []string{dir},
+			opts:     Options{Include: []string{"app/**/templates/*.yaml"}},
+			expected: join("app/charts/templates/pod.yaml"),
+		},
+		{
+			name:     "ignore file",
+			paths:    []string{filepath.Join(dir, "db")},
+			opts:     Options{IgnoreFiles: []string{ignoreFile}},
+			expected: join("db/statefulset.json"),
+		},
+		{
+			name:     "files, globs and duplicates",
+			paths:    []string{filepath.Join(dir, "app/README.md"), filepath.Join(dir, "db/*.json"), filepath.Join(dir, "db")},
+			expected: join("app/README.md", "db/statefulset.json", "db/secret.yaml"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Collect(tt.paths, tt.opts)
+			if err != nil {
+				t.Fatal(err.Error())
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Got %v wanted %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCollect_Errors(t *testing.T) {
+	dir := t.TempDir()
+
+	var tests = []struct {
+		name  string
+		paths []string
+		opts  Options
+	}{
+		{"missing file", []string{filepath.Join(dir, "missing.yaml")}, Options{}},
+		{"glob without matches", []string{filepath.Join(dir, "*.yaml")}, Options{}},
+		{"missing ignore file", []string{dir}, Options{IgnoreFiles: []string{filepath.Join(dir, ".kubesecignore")}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Collect(tt.paths, tt.opts); err == nil {
+				t.Errorf("Got no error wanted error")
+			}
+		})
+	}
+}
@@ -0,0 +1,361 @@
+package pss
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/controlplaneio/kubesec/v2/pkg/rules"
+)
+
+// control is a Pod Security Standards control, it returns the fields of the
+// pod breaking it. Controls reuse the kubesec predicates where they have the
+// same semantics and only walk the pod spec to find the violating fields.
+type control struct {
+	id    string
+	name  string
+	level Level
+	check func(p *pod) []Violation
+}
+
+// controls are named after the checks of the Pod Security Admission controller
+var controls = []control{
+	{"hostProcess", "HostProcess", LevelBaseline, checkHostProcess},
+	{"hostNamespaces", "Host Namespaces", LevelBaseline, checkHostNamespaces},
+	{"privileged", "Privileged Containers", LevelBaseline, checkPrivileged},
+	{"capabilities_baseline", "Capabilities", LevelBaseline, checkCapabilitiesBaseline},
+	{"hostPathVolumes", "HostPath Volumes", LevelBaseline, checkHostPathVolumes},
+	{"hostPorts", "Host Ports", LevelBaseline, checkHostPorts},
+	{"appArmorProfile", "AppArmor", LevelBaseline, checkAppArmor},
+	{"seLinuxOptions", "SELinux", LevelBaseline, checkSELinux},
+	{"procMount", "/proc Mount Type", LevelBaseline, checkProcMount},
+	{"seccompProfile_baseline", "Seccomp", LevelBaseline, checkSeccompBaseline},
+	{"sysctls", "Sysctls", LevelBaseline, checkSysctls},
+	{"restrictedVolumes", "Volume Types", LevelRestricted, checkVolumeTypes},
+	{"allowPrivilegeEscalation", "Privilege Escalation", LevelRestricted, checkAllowPrivilegeEscalation},
+	{"runAsNonRoot", "Running as Non-root", LevelRestricted, checkRunAsNonRoot},
+	{"runAsUser", "Running as Non-root user", LevelRestricted, checkRunAsUser},
+	{"seccompProfile_restricted", "Seccomp", LevelRestricted, checkSeccompRestricted},
+	{"capabilities_restricted", "Capabilities", LevelRestricted, checkCapabilitiesRestricted},
+}
+
+// appArmorAnnotationPrefix followed by a container name sets its AppArmor profile
+const appArmorAnnotationPrefix = "container.apparmor.security.beta.kubernetes.io/"
+
+var (
+	baselineCapabilities = []string{
+		"AUDIT_WRITE", "CHOWN", "DAC_OVERRIDE", "FOWNER", "FSETID", "KILL", "MKNOD",
+		"NET_BIND_SERVICE", "SETFCAP", "SETGID", "SETPCAP", "SETUID", "SYS_CHROOT",
+	}
+	seLinuxTypes = []string{
+		"", "container_t", "container_init_t", "container_kvm_t", "container_engine_t",
+	}
+	safeSysctls = []string{
+		"kernel.shm_rmid_forced", "net.ipv4.ip_local_port_range", "net.ipv4.ip_unprivileged_port_start",
+		"net.ipv4.tcp_syncookies", "net.ipv4.ping_group_range", "net.ipv4.ip_local_reserved_ports",
+		"net.ipv4.tcp_keepalive_time", "net.ipv4.tcp_fin_timeout", "net.ipv4.tcp_keepalive_intvl",
+		"net.ipv4.tcp_keepalive_probes",
+	}
+	restrictedVolumeTypes = []string{
+		"configMap", "csi", "downwardAPI", "emptyDir", "ephemeral", "persistentVolumeClaim", "projected", "secret",
+	}
+)
+
+// securityContext is the security context of the pod or of a container
+type securityContext struct {
+	path string
+	node interface{}
+}
+
+// securityContexts returns the pod security context followed by the
+// security contexts of the containers
+func (p *pod) securityContexts() []securityContext {
+	contexts := []securityContext{{p.specPath + ".securityContext", p.spec["securityContext"]}}
+	return append(contexts, p.containerSecurityContexts()...)
+}
+
+func (p *pod) containerSecurityContexts() []securityContext {
+	var contexts []securityContext
+	for _, c := range p.containers {
+		contexts = append(contexts, securityContext{c.path + ".securityContext", c.node["securityContext"]})
+	}
+	return contexts
+}
+
+// checkTrue returns the fields set to true in the security contexts
+func checkTrue(contexts []securityContext, keys ...string) []Violation {
+	var violations []Violation
+	for _, sc := range contexts {
+		if v, ok := get(sc.node, keys...).(bool); ok && v {
+			violations = append(violations, Violation{sc.path + "." + strings.Join(keys, "."), "true"})
+		}
+	}
+	return violations
+}
+
+func checkHostProcess(p *pod) []Violation {
+	return checkTrue(p.securityContexts(), "windowsOptions", "hostProcess")
+}
+
+func checkHostNamespaces(p *pod) []Violation {
+	var violations []Violation
+	for _, ns := range []struct {
+		field     string
+		predicate rules.Predicate
+	}{
+		{"hostNetwork", rules.HostNetwork},
+		{"hostPID", rules.HostPID},
+		{"hostIPC", rules.HostIPC},
+	} {
+		if ns.predicate(p.json).Count > 0 {
+			violations = append(violations, Violation{p.specPath + "." + ns.field, "true"})
+		}
+	}
+	return violations
+}
+
+func checkPrivileged(p *pod) []Violation {
+	if rules.Privileged(p.json).Count == 0 {
+		return nil
+	}
+	return checkTrue(p.containerSecurityContexts(), "privileged")
+}
+
+// checkCapabilitiesAdded returns the added capabilities missing from allowed
+func checkCapabilitiesAdded(p *pod, allowed []string) []Violation {
+	var violations []Violation
+	for _, sc := range p.containerSecurityContexts() {
+		added, _ := get(sc.node, "capabilities", "add").([]interface{})
+		for i, c := range added {
+			if name, ok := c.(string); !ok || !contains(allowed, name) {
+				violations = append(violations, Violation{fmt.Sprintf("%s.capabilities.add[%d]", sc.path, i), format(c)})
+			}
+		}
+	}
+	return violations
+}
+
+func checkCapabilitiesBaseline(p *pod) []Violation {
+	return checkCapabilitiesAdded(p, baselineCapabilities)
+}
+
+func checkHostPathVolumes(p *pod) []Violation {
+	var violations []Violation
+	volumes, _ := p.spec["volumes"].([]interface{})
+	for i, v := range volumes {
+		if hostPath := get(v, "hostPath"); hostPath != nil {
+			violations = append(violations, Violation{
+				fmt.Sprintf("%s.volumes[%d].hostPath", p.specPath, i),
+				format(get(hostPath, "path")),
+			})
+		}
+	}
+	return violations
+}
+
+func checkHostPorts(p *pod) []Violation {
+	var violations []Violation
+	for _, c := range p.containers {
+		ports, _ := c.node["ports"].([]interface{})
+		for i, port := range ports {
+			if hostPort, ok := get(port, "hostPort").(float64); ok && hostPort != 0 {
+				violations = append(violations, Violation{fmt.Sprintf("%s.ports[%d].hostPort", c.path, i), format(hostPort)})
+			}
+		}
+	}
+	return violations
+}
+
+func checkAppArmor(p *pod) []Violation {
+	var violations []Violation
+	for _, sc := range p.securityContexts() {
+		t := get(sc.node, "appArmorProfile", "type")
+		if t != nil && t != "RuntimeDefault" && t != "Localhost" {
+			violations = append(violations, Violation{sc.path + ".appArmorProfile.type", format(t)})
+		}
+	}
+
+	annotations, _ := p.metadata["annotations"].(map[string]interface{})
+	for _, c := range p.containers {
+		key := appArmorAnnotationPrefix + c.name
+		value, ok := annotations[key].(string)
+		if !ok || value == "" || value == "runtime/default" || strings.HasPrefix(value, "localhost/") {
+			continue
+		}
+		violations = append(violations, Violation{fmt.Sprintf("%s.annotations[%q]", p.metadataPath, key), value})
+	}
+	return violations
+}
+
+func checkSELinux(p *pod) []Violation {
+	var violations []Violation
+	for _, sc := range p.securityContexts() {
+		options := get(sc.node, "seLinuxOptions")
+		if options == nil {
+			continue
+		}
+		if t := get(options, "type"); t != nil && !contains(seLinuxTypes, format(t)) {
+			violations = append(violations, Violation{sc.path + ".seLinuxOptions.type", format(t)})
+		}
+		for _, field := range []string{"user", "role"} {
+			if v := get(options, field); v != nil && v != "" {
+				violations = append(violations, Violation{sc.path + ".seLinuxOptions." + field, format(v)})
+			}
+		}
+	}
+	return violations
+}
+
+func checkProcMount(p *pod) []Violation {
+	var violations []Violation
+	for _, sc := range p.containerSecurityContexts() {
+		if v := get(sc.node, "procMount"); v != nil && v != "Default" {
+			violations = append(violations, Violation{sc.path + ".procMount", format(v)})
+		}
+	}
+	return violations
+}
+
+func checkSeccompBaseline(p *pod) []Violation {
+	var violations []Violation
+	for _, sc := range p.securityContexts() {
+		if t := get(sc.node, "seccompProfile", "type"); t == "Unconfined" {
+			violations = append(violations, Violation{sc.path + ".seccompProfile.type", format(t)})
+		}
+	}
+	return violations
+}
+
+func checkSysctls(p *pod) []Violation {
+	var violations []Violation
+	sysctls, _ := get(p.spec, "securityContext", "sysctls").([]interface{})
+	for i, s := range sysctls {
+		if name := format(get(s, "name")); !contains(safeSysctls, name) {
+			violations = append(violations, Violation{fmt.Sprintf("%s.securityContext.sysctls[%d].name", p.specPath, i), name})
+		}
+	}
+	return violations
+}
+
+func checkVolumeTypes(p *pod) []Violation {
+	var violations []Violation
+	volumes, _ := p.spec["volumes"].([]interface{})
+	for i, v := range volumes {
+		volume, _ := v.(map[string]interface{})
+		fields := make([]string, 0, len(volume))
+		for field := range volume {
+			fields = append(fields, field)
+		}
+		sort.Strings(fields)
+		for _, field := range fields {
+			if field != "name" && !contains(restrictedVolumeTypes, field) {
+				violations = append(violations, Violation{fmt.Sprintf("%s.volumes[%d].%s", p.specPath, i, field), format(volume["name"])})
+			}
+		}
+	}
+	return violations
+}
+
+// checkAllowPrivilegeEscalation requires allowPrivilegeEscalation to be set
+// to false, unlike the AllowPrivilegeEscalation predicate which only counts
+// the containers setting it to true.
+func checkAllowPrivilegeEscalation(p *pod) []Violation {
+	if p.isWindows() {
+		return nil
+	}
+	var violations []Violation
+	for _, sc := range p.containerSecurityContexts() {
+		if v, ok := get(sc.node, "allowPrivilegeEscalation").(bool); !ok || v {
+			violations = append(violations, Violation{sc.path + ".allowPrivilegeEscalation", format(get(sc.node, "allowPrivilegeEscalation"))})
+		}
+	}
+	return violations
+}
+
+func checkRunAsNonRoot(p *pod) []Violation {
+	podValue, podSet := get(p.spec, "securityContext", "runAsNonRoot").(bool)
+	if podSet && !podValue {
+		return []Violation{{p.specPath + ".securityContext.runAsNonRoot", "false"}}
+	}
+	if rules.RunAsNonRoot(p.json).Count == len(p.containers) {
+		return nil
+	}
+
+	var violations []Violation
+	for _, sc := range p.containerSecurityContexts() {
+		v, ok := get(sc.node, "runAsNonRoot").(bool)
+		if ok && !v || !ok && !podValue {
+			violations = append(violations, Violation{sc.path + ".runAsNonRoot", format(get(sc.node, "runAsNonRoot"))})
+		}
+	}
+	return violations
+}
+
+func checkRunAsUser(p *pod) []Violation {
+	var violations []Violation
+	for _, sc := range p.securityContexts() {
+		if v, ok := get(sc.node, "runAsUser").(float64); ok && v == 0 {
+			violations = append(violations, Violation{sc.path + ".runAsUser", "0"})
+		}
+	}
+	return violations
+}
+
+func checkSeccompRestricted(p *pod) []Violation {
+	if p.isWindows() {
+		return nil
+	}
+	valid := func(t interface{}) bool {
+		return t == "RuntimeDefault" || t == "Localhost"
+	}
+
+	podType := get(p.spec, "securityContext", "seccompProfile", "type")
+	if podType != nil && !valid(podType) {
+		return []Violation{{p.specPath + ".securityContext.seccompProfile.type", format(podType)}}
+	}
+	if rules.SeccompAny(p.json).Count == len(p.containers) {
+		return nil
+	}
+
+	var violations []Violation
+	for _, sc := range p.containerSecurityContexts() {
+		t := get(sc.node, "seccompProfile", "type")
+		if t != nil && !valid(t) || t == nil && podType == nil {
+			violations = append(violations, Violation{sc.path + ".seccompProfile.type", format(t)})
+		}
+	}
+	return violations
+}
+
+func checkCapabilitiesRestricted(p *pod) []Violation {
+	if p.isWindows() {
+		return nil
+	}
+	violations := checkCapabilitiesAdded(p, []string{"NET_BIND_SERVICE"})
+	if rules.CapDropAll(p.json).Count == len(p.containers) {
+		return violations
+	}
+
+	for _, sc := range p.containerSecurityContexts() {
+		dropped, _ := get(sc.node, "capabilities", "drop").([]interface{})
+		var all bool
+		for _, c := range dropped {
+			if c == "ALL" {
+				all = true
+			}
+		}
+		if !all {
+			violations = append(violations, Violation{sc.path + ".capabilities.drop", format(get(sc.node, "capabilities", "drop"))})
+		}
+	}
+	return violations
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
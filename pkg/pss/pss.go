@@ -0,0 +1,176 @@
+// Package pss evaluates objects against the Pod Security Standards
+// https://kubernetes.io/docs/concepts/security/pod-security-standards/
+package pss
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/controlplaneio/kubesec/v2/pkg/rules"
+)
+
+// Level is a Pod Security Standards level
+type Level string
+
+const (
+	LevelBaseline   Level = "baseline"
+	LevelRestricted Level = "restricted"
+)
+
+// ParseLevel returns the level of its name
+func ParseLevel(s string) (Level, error) {
+	switch Level(s) {
+	case LevelBaseline, LevelRestricted:
+		return Level(s), nil
+	}
+	return "", fmt.Errorf("unknown Pod Security Standards level %q, expected baseline or restricted", s)
+}
+
+// includes reports whether the controls of level l are part of level other
+func (l Level) includes(other Level) bool {
+	return l == other || l == LevelRestricted && other == LevelBaseline
+}
+
+// Unset is the value of the violations of fields required to be set
+const Unset = "<unset>"
+
+// Violation is a field breaking a control
+type Violation struct {
+	Path  string `json:"path"`
+	Value string `json:"value"`
+}
+
+// ControlResult is the outcome of a control
+type ControlResult struct {
+	ID         string      `json:"id"`
+	Name       string      `json:"name"`
+	Level      Level       `json:"level"`
+	Passed     bool        `json:"passed"`
+	Violations []Violation `json:"violations,omitempty"`
+}
+
+// Result is the outcome of the controls of a level
+type Result struct {
+	Level    Level           `json:"level"`
+	Allowed  bool            `json:"allowed"`
+	Controls []ControlResult `json:"controls"`
+}
+
+// Evaluate checks the object against the controls of the level. The second
+// return value is false if the object has no pod spec.
+func Evaluate(data []byte, level Level) (*Result, bool) {
+	p, ok := newPod(data)
+	if !ok {
+		return nil, false
+	}
+
+	result := &Result{Level: level, Allowed: true, Controls: make([]ControlResult, 0)}
+	for _, c := range controls {
+		if !level.includes(c.level) {
+			continue
+		}
+		violations := c.check(p)
+		result.Controls = append(result.Controls, ControlResult{
+			ID:         c.id,
+			Name:       c.name,
+			Level:      c.level,
+			Passed:     len(violations) == 0,
+			Violations: violations,
+		})
+		if len(violations) > 0 {
+			result.Allowed = false
+		}
+	}
+
+	return result, true
+}
+
+// pod is the pod spec of an object with the paths of its fields
+type pod struct {
+	json         []byte
+	specPath     string
+	spec         map[string]interface{}
+	metadataPath string
+	metadata     map[string]interface{}
+	containers   []container
+}
+
+type container struct {
+	path string
+	name string
+	node map[string]interface{}
+}
+
+func newPod(data []byte) (*pod, bool) {
+	var object map[string]interface{}
+	if err := json.Unmarshal(data, &object); err != nil {
+		return nil, false
+	}
+
+	specPath := rules.SpecSelector(data)
+	spec, ok := get(object, strings.Split(specPath, ".")...).(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+
+	// the metadata is the sibling of the pod spec
+	metadataPath := "metadata"
+	if i := strings.LastIndex(specPath, "."); i >= 0 {
+		metadataPath = specPath[:i] + ".metadata"
+	}
+	metadata, _ := get(object, strings.Split(metadataPath, ".")...).(map[string]interface{})
+
+	p := &pod{
+		json:         data,
+		specPath:     specPath,
+		spec:         spec,
+		metadataPath: metadataPath,
+		metadata:     metadata,
+	}
+	for _, field := range []string{"initContainers", "containers", "ephemeralContainers"} {
+		items, _ := spec[field].([]interface{})
+		for i, item := range items {
+			node, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			name, _ := node["name"].(string)
+			p.containers = append(p.containers, container{
+				path: fmt.Sprintf("%s.%s[%d]", specPath, field, i),
+				name: name,
+				node: node,
+			})
+		}
+	}
+	if len(p.containers) == 0 {
+		return nil, false
+	}
+
+	return p, true
+}
+
+// isWindows reports whether the pod targets Windows nodes, which are exempt
+// from some restricted controls
+func (p *pod) isWindows() bool {
+	return get(p.spec, "os", "name") == "windows"
+}
+
+// get returns the value at the keys of a node, nil if missing
+func get(node interface{}, keys ...string) interface{} {
+	for _, key := range keys {
+		m, ok := node.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		node = m[key]
+	}
+	return node
+}
+
+func format(v interface{}) string {
+	if v == nil {
+		return Unset
+	}
+	return fmt.Sprintf("%v", v)
+}
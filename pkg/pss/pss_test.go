@@ -0,0 +1,233 @@
+package pss
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ghodss/yaml"
+)
+
+const restrictedPod = `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: app
+spec:
+  securityContext:
+    runAsNonRoot: true
+    seccompProfile:
+      type: RuntimeDefault
+  containers:
+    - name: app
+      securityContext:
+        allowPrivilegeEscalation: false
+        capabilities:
+          drop: [ALL]
+`
+
+func TestEvaluate(t *testing.T) {
+	tests := []struct {
+		name               string
+		manifest           string
+		level              Level
+		expectedAllowed    bool
+		expectedViolations map[string][]Violation
+	}{
+		{
+			name:            "restricted pod",
+			manifest:        restrictedPod,
+			level:           LevelRestricted,
+			expectedAllowed: true,
+		},
+		{
+			name: "baseline allows defaults",
+			manifest: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+spec:
+  template:
+    spec:
+      containers:
+        - name: app
+`,
+			level:           LevelBaseline,
+			expectedAllowed: true,
+		},
+		{
+			name: "baseline violations",
+			manifest: `
+apiVersion: apps/v1
+kind: DaemonSet
+metadata:
+  name: app
+spec:
+  template:
+    metadata:
+      annotations:
+        container.apparmor.security.beta.kubernetes.io/app: unconfined
+    spec:
+      hostNetwork: true
+      volumes:
+        - name: docker
+          hostPath:
+            path: /var/run/docker.sock
+      containers:
+        - name: app
+          ports:
+            - containerPort: 80
+              hostPort: 80
+          securityContext:
+            privileged: true
+            capabilities:
+              add: [NET_BIND_SERVICE, SYS_ADMIN]
+`,
+			level:           LevelBaseline,
+			expectedAllowed: false,
+			expectedViolations: map[string][]Violation{
+				"hostNamespaces": {{"spec.template.spec.hostNetwork", "true"}},
+				"privileged":     {{"spec.template.spec.containers[0].securityContext.privileged", "true"}},
+				"capabilities_baseline": {
+					{"spec.template.spec.containers[0].securityContext.capabilities.add[1]", "SYS_ADMIN"},
+				},
+				"hostPathVolumes": {{"spec.template.spec.volumes[0].hostPath", "/var/run/docker.sock"}},
+				"hostPorts":       {{"spec.template.spec.containers[0].ports[0].hostPort", "80"}},
+				"appArmorProfile": {
+					{`spec.template.metadata.annotations["container.apparmor.security.beta.kubernetes.io/app"]`, "unconfined"},
+				},
+			},
+		},
+		{
+			name: "restricted violations",
+			manifest: `
+apiVersion: batch/v1
+kind: CronJob
+metadata:
+  name: app
+spec:
+  jobTemplate:
+    spec:
+      template:
+        spec:
+          securityContext:
+            runAsUser: 0
+          volumes:
+            - name: data
+              nfs:
+                server: nfs.local
+                path: /data
+          containers:
+            - name: app
+`,
+			level:           LevelRestricted,
+			expectedAllowed: false,
+			expectedViolations: map[string][]Violation{
+				"restrictedVolumes": {{"spec.jobTemplate.spec.template.spec.volumes[0].nfs", "data"}},
+				"allowPrivilegeEscalation": {
+					{"spec.jobTemplate.spec.template.spec.containers[0].securityContext.allowPrivilegeEscalation", Unset},
+				},
+				"runAsNonRoot": {
+					{"spec.jobTemplate.spec.template.spec.containers[0].securityContext.runAsNonRoot", Unset},
+				},
+				"runAsUser": {{"spec.jobTemplate.spec.template.spec.securityContext.runAsUser", "0"}},
+				"seccompProfile_restricted": {
+					{"spec.jobTemplate.spec.template.spec.containers[0].securityContext.seccompProfile.type", Unset},
+				},
+				"capabilities_restricted": {
+					{"spec.jobTemplate.spec.template.spec.containers[0].securityContext.capabilities.drop", Unset},
+				},
+			},
+		},
+		{
+			name: "pod level runAsNonRoot set to false",
+			manifest: `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: app
+spec:
+  securityContext:
+    runAsNonRoot: false
+    seccompProfile:
+      type: Unconfined
+  containers:
+    - name: app
+      securityContext:
+        runAsNonRoot: true
+        allowPrivilegeEscalation: false
+        seccompProfile:
+          type: RuntimeDefault
+        capabilities:
+          drop: [ALL]
+`,
+			level:           LevelRestricted,
+			expectedAllowed: false,
+			expectedViolations: map[string][]Violation{
+				"seccompProfile_baseline":   {{"spec.securityContext.seccompProfile.type", "Unconfined"}},
+				"runAsNonRoot":              {{"spec.securityContext.runAsNonRoot", "false"}},
+				"seccompProfile_restricted": {{"spec.securityContext.seccompProfile.type", "Unconfined"}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := yaml.YAMLToJSON([]byte(tt.manifest))
+			if err != nil {
+				t.Fatal(err.Error())
+			}
+
+			result, ok := Evaluate(data, tt.level)
+			if !ok {
+				t.Fatalf("Got no result for %s", tt.name)
+			}
+			if result.Allowed != tt.expectedAllowed {
+				t.Errorf("Got allowed %v wanted %v", result.Allowed, tt.expectedAllowed)
+			}
+
+			for _, c := range result.Controls {
+				expected := tt.expectedViolations[c.ID]
+				if len(c.Violations) == 0 && len(expected) == 0 {
+					continue
+				}
+				if !reflect.DeepEqual(c.Violations, expected) {
+					t.Errorf("Got %v violations %v wanted %v", c.ID, c.Violations, expected)
+				}
+				if c.Passed != (len(expected) == 0) {
+					t.Errorf("Got %v passed %v", c.ID, c.Passed)
+				}
+			}
+		})
+	}
+}
+
+func TestEvaluate_Levels(t *testing.T) {
+	data, err := yaml.YAMLToJSON([]byte(restrictedPod))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	baseline, _ := Evaluate(data, LevelBaseline)
+	restricted, _ := Evaluate(data, LevelRestricted)
+	if len(baseline.Controls) != 11 || len(restricted.Controls) != 17 {
+		t.Errorf("Got %v baseline and %v restricted controls, wanted 11 and 17",
+			len(baseline.Controls), len(restricted.Controls))
+	}
+}
+
+func TestEvaluate_NoPodSpec(t *testing.T) {
+	data := []byte(`{"apiVersion":"v1","kind":"Service","metadata":{"name":"app"},"spec":{"ports":[]}}`)
+	if _, ok := Evaluate(data, LevelBaseline); ok {
+		t.Errorf("Got a result for a Service, wanted none")
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	if _, err := ParseLevel("privileged"); err == nil {
+		t.Errorf("Got no error for level privileged")
+	}
+	if level, err := ParseLevel("restricted"); err != nil || level != LevelRestricted {
+		t.Errorf("Got %v, %v wanted %v", level, err, LevelRestricted)
+	}
+}
@@ -0,0 +1,79 @@
+package report
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/controlplaneio/kubesec/v2/pkg/ruler"
+	"github.com/pterm/pterm"
+)
+
+// WriteComplianceReports writes the per-control compliance view of the reports
+func WriteComplianceReports(format string, output io.Writer, reports []ruler.ComplianceReport) error {
+	switch format {
+	case "json":
+		out, err := json.Marshal(reports)
+		if err != nil {
+			return err
+		}
+		formattedOutput, err := PrettyJSON(out)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprint(output, string(formattedOutput))
+		return err
+	case "table":
+		return writeComplianceTable(output, reports)
+	default:
+		return errors.New("Unrecognized format specified for the compliance view, use json or table")
+	}
+}
+
+func writeComplianceTable(output io.Writer, reports []ruler.ComplianceReport) error {
+	pterm.SetDefaultOutput(output)
+
+	pterm.Println()
+	pterm.DefaultHeader.WithFullWidth().
+		WithBackgroundStyle(pterm.NewStyle(pterm.BgCyan)).
+		WithMargin(1).
+		Println("🛡️  Kubesec Compliance Report")
+
+	for _, r := range reports {
+		pterm.DefaultSection.Printf("%s controls for %s", strings.ToUpper(r.Framework), pterm.LightCyan(r.Object))
+		pterm.Printf("File: %s\n", pterm.Gray(r.FileName))
+		if r.Message != "" {
+			pterm.Info.Println(r.Message)
+		}
+		if len(r.Controls) == 0 {
+			continue
+		}
+
+		data := [][]string{{"Status", "Control", "Title", "Rules"}}
+		for _, c := range r.Controls {
+			status := pterm.LightGreen("🟢 Passed")
+			rules := c.Rules
+			if !c.Passed {
+				status = pterm.LightRed("🔴 Failed")
+				rules = c.FailedRules
+			}
+			data = append(data, []string{status, c.ID, c.Title, strings.Join(rules, "\n")})
+		}
+
+		err := pterm.DefaultTable.
+			WithHasHeader().
+			WithBoxed(true).
+			WithRowSeparator("-").
+			WithHeaderStyle(pterm.NewStyle(pterm.FgWhite, pterm.Bold)).
+			WithData(data).
+			Render()
+		if err != nil {
+			return err
+		}
+		pterm.Println()
+	}
+
+	return nil
+}
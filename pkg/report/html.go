@@ -0,0 +1,314 @@
+package report
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/controlplaneio/kubesec/v2/pkg/ruler"
+	"github.com/controlplaneio/kubesec/v2/pkg/rules"
+)
+
+//go:embed html
+var htmlAssets embed.FS
+
+// snippetContext is the number of lines shown around the line of a finding
+const snippetContext = 3
+
+// HTMLWriter implements result Writer for a self-contained HTML report, to
+// be opened offline. It holds the tables of the objects, findings and rules
+// of the scan and the rollups of the files and namespaces.
+type HTMLWriter struct {
+	Output io.Writer
+	// Rules are described in the report, as printed by print-rules
+	Rules []ruler.Rule
+	// Sources are the contents of the scanned files by name, the findings
+	// show a snippet of their file when set
+	Sources map[string][]byte
+	// Policy decides which objects pass, the default policy applies if nil
+	Policy *ruler.ExitPolicy
+}
+
+type htmlReport struct {
+	Generated  string
+	Objects    []htmlObject
+	Findings   []htmlFinding
+	Files      []htmlRollup
+	Namespaces []htmlRollup
+	Rules      []htmlRule
+	Failed     int
+	Style      htmltemplate.CSS
+	Script     htmltemplate.JS
+}
+
+type htmlObject struct {
+	Status    string
+	Object    string
+	Kind      string
+	Namespace string
+	File      string
+	Score     int
+	Critical  int
+	Advise    int
+	Passed    int
+	// Suppressed is the number of suppressed findings
+	Suppressed int
+	Message    string
+}
+
+type htmlFinding struct {
+	Status     string
+	Severity   ruler.Severity
+	Rule       string
+	Object     string
+	File       string
+	Line       int
+	Reason     string
+	Points     int
+	Findings   []string
+	Snippet    []htmlSnippetLine
+	Suppressed string
+}
+
+type htmlSnippetLine struct {
+	Number int
+	Text   string
+	Match  bool
+}
+
+type htmlRollup struct {
+	Name     string
+	Objects  int
+	Failed   int
+	Critical int
+	Advise   int
+	MinScore int
+}
+
+type htmlRule struct {
+	ID         string
+	Severity   ruler.Severity
+	Points     int
+	Kinds      string
+	Source     string
+	Reason     string
+	Selector   string
+	Compliance string
+	Link       string
+}
+
+// Write writes the reports as a single HTML page with its style and script
+// inlined
+func (hw HTMLWriter) Write(reports reports) error {
+	tmpl, err := htmltemplate.New("report.html").Funcs(htmltemplate.FuncMap{
+		"rollup": func(id, label string, rollups []htmlRollup) map[string]interface{} {
+			return map[string]interface{}{"ID": id, "Label": label, "Rollups": rollups}
+		},
+		"severityRank": severityRank,
+	}).ParseFS(htmlAssets, "html/report.html")
+	if err != nil {
+		return err
+	}
+	style, err := htmlAssets.ReadFile("html/report.css")
+	if err != nil {
+		return err
+	}
+	script, err := htmlAssets.ReadFile("html/report.js")
+	if err != nil {
+		return err
+	}
+
+	view := htmlReport{
+		Generated: Now().UTC().Format(time.RFC3339),
+		Style:     htmltemplate.CSS(style),
+		Script:    htmltemplate.JS(script),
+		Rules:     htmlRules(hw.Rules),
+	}
+
+	files := make(map[string]*htmlRollup)
+	namespaces := make(map[string]*htmlRollup)
+	rollup := func(rollups map[string]*htmlRollup, name string, o htmlObject) {
+		r, ok := rollups[name]
+		if !ok {
+			r = &htmlRollup{Name: name, MinScore: o.Score}
+			rollups[name] = r
+		}
+		r.Objects++
+		if o.Status != "passed" {
+			r.Failed++
+		}
+		r.Critical += o.Critical
+		r.Advise += o.Advise
+		r.MinScore = min(r.MinScore, o.Score)
+	}
+
+	decisions := decideReports(hw.Policy, reports)
+	for i, r := range reports {
+		o := htmlObjectOf(r, decisions[i])
+		view.Objects = append(view.Objects, o)
+		if o.Status != "passed" {
+			view.Failed++
+		}
+		rollup(files, r.FileName, o)
+		rollup(namespaces, o.Namespace, o)
+
+		groups := []struct {
+			status string
+			refs   []ruler.RuleRef
+		}{
+			{"critical", r.Scoring.Critical},
+			{"advise", r.Scoring.Advise},
+			{"suppressed", r.Scoring.Suppressed},
+		}
+		for _, g := range groups {
+			for _, ref := range g.refs {
+				view.Findings = append(view.Findings, hw.htmlFinding(r, ref, g.status))
+			}
+		}
+	}
+
+	view.Files = sortedRollups(files)
+	view.Namespaces = sortedRollups(namespaces)
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, view); err != nil {
+		return err
+	}
+	_, err = hw.Output.Write(buf.Bytes())
+	return err
+}
+
+// htmlObjectOf returns the row of a report, with the status of the decision
+// of the policy for it. Objects without a namespace are listed as such, the
+// namespace they are created in is not known.
+func htmlObjectOf(r ruler.Report, d ruler.ObjectDecision) htmlObject {
+	namespace := "(unset)"
+	if r.Identity.Namespace != nil {
+		namespace = *r.Identity.Namespace
+	}
+
+	o := htmlObject{
+		Status:     "passed",
+		Object:     r.Object,
+		Kind:       r.Identity.Kind,
+		Namespace:  namespace,
+		File:       reportFile(r),
+		Score:      r.Score,
+		Critical:   len(r.Scoring.Critical),
+		Advise:     len(r.Scoring.Advise),
+		Passed:     len(r.Scoring.Passed),
+		Suppressed: len(r.Scoring.Suppressed),
+		Message:    strings.TrimSpace(r.Message),
+	}
+
+	switch d.Outcome {
+	case ruler.OutcomeInvalidInput:
+		o.Status = "error"
+		o.Message = fmt.Sprintf("%s: %s", r.Error.Reason, r.Error.Message)
+	case ruler.OutcomeSchemaInvalid:
+		o.Status = "invalid"
+	case ruler.OutcomePolicyFailed:
+		o.Status = "failed"
+		o.Message = strings.Join(d.Reasons, "; ")
+	}
+	return o
+}
+
+func (hw HTMLWriter) htmlFinding(r ruler.Report, ref ruler.RuleRef, status string) htmlFinding {
+	f := htmlFinding{
+		Status:   status,
+		Severity: ref.Severity,
+		Rule:     ref.ID,
+		Object:   r.Object,
+		File:     reportFile(r),
+		Reason:   ref.Reason,
+		Points:   ref.Points,
+	}
+
+	var location *rules.Location
+	for _, finding := range ref.Findings {
+		f.Findings = append(f.Findings, finding.String())
+		if location == nil {
+			location = finding.Location
+		}
+	}
+	if location != nil {
+		f.Line = location.Line
+		f.Snippet = snippet(hw.Sources[r.FileName], location.Line)
+	}
+	if s := ref.Suppression; s != nil {
+		f.Suppressed = s.Justification
+		if s.Expired {
+			f.Suppressed = fmt.Sprintf("exception expired on %s (owner: %s)", s.Expires, s.Owner)
+		}
+	}
+	return f
+}
+
+// snippet returns the lines of a file around a line, from 1
+func snippet(source []byte, line int) []htmlSnippetLine {
+	if len(source) == 0 || line < 1 {
+		return nil
+	}
+	lines := strings.Split(string(source), "\n")
+	if line > len(lines) {
+		return nil
+	}
+
+	var out []htmlSnippetLine
+	for n := max(1, line-snippetContext); n <= min(len(lines), line+snippetContext); n++ {
+		out = append(out, htmlSnippetLine{Number: n, Text: strings.TrimRight(lines[n-1], "\r"), Match: n == line})
+	}
+	return out
+}
+
+// severityRank sorts the severities from the most severe
+func severityRank(severity ruler.Severity) int {
+	for i, s := range []ruler.Severity{ruler.SeverityCritical, ruler.SeverityHigh, ruler.SeverityMedium, ruler.SeverityLow, ruler.SeverityInfo} {
+		if severity == s {
+			return i
+		}
+	}
+	return 5
+}
+
+func sortedRollups(rollups map[string]*htmlRollup) []htmlRollup {
+	out := make([]htmlRollup, 0, len(rollups))
+	for _, r := range rollups {
+		out = append(out, *r)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+func htmlRules(rs []ruler.Rule) []htmlRule {
+	out := make([]htmlRule, 0, len(rs))
+	for _, r := range rs {
+		source := r.Source
+		if source == "" {
+			source = "builtin"
+		}
+		var compliance []string
+		for _, c := range r.Compliance {
+			compliance = append(compliance, c.String())
+		}
+		out = append(out, htmlRule{
+			ID:         r.ID,
+			Severity:   r.Severity,
+			Points:     r.Points,
+			Kinds:      strings.Join(r.Kinds, ", "),
+			Source:     source,
+			Reason:     r.Reason,
+			Selector:   r.Selector,
+			Compliance: strings.Join(compliance, ", "),
+			Link:       r.Link,
+		})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out
+}
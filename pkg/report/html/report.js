@@ -0,0 +1,57 @@
+(function () {
+  "use strict";
+
+  // cellValue is the value a cell sorts by, data-sort overriding its text
+  function cellValue(row, index, numeric) {
+    var cell = row.cells[index];
+    var value = cell.hasAttribute("data-sort") ? cell.getAttribute("data-sort") : cell.textContent.trim();
+    if (numeric) {
+      var number = parseFloat(value);
+      return isNaN(number) ? Number.POSITIVE_INFINITY : number;
+    }
+    return value.toLowerCase();
+  }
+
+  // sort the rows of a table by a column when its header is clicked
+  document.querySelectorAll("table.sortable").forEach(function (table) {
+    var headers = table.tHead.rows[0].cells;
+    Array.prototype.forEach.call(headers, function (header, index) {
+      header.addEventListener("click", function () {
+        var numeric = header.getAttribute("data-type") === "number";
+        var ascending = !header.classList.contains("asc");
+        Array.prototype.forEach.call(headers, function (h) {
+          h.classList.remove("asc", "desc");
+        });
+        header.classList.add(ascending ? "asc" : "desc");
+
+        var body = table.tBodies[0];
+        var rows = Array.prototype.slice.call(body.rows);
+        rows.sort(function (a, b) {
+          var x = cellValue(a, index, numeric);
+          var y = cellValue(b, index, numeric);
+          if (x === y) {
+            return 0;
+          }
+          return (x < y ? -1 : 1) * (ascending ? 1 : -1);
+        });
+        rows.forEach(function (row) {
+          body.appendChild(row);
+        });
+      });
+    });
+  });
+
+  // hide the rows of a table which do not hold every word of its filter
+  document.querySelectorAll("input.filter").forEach(function (input) {
+    var table = document.getElementById(input.getAttribute("data-table"));
+    input.addEventListener("input", function () {
+      var words = input.value.toLowerCase().split(/\s+/).filter(Boolean);
+      Array.prototype.forEach.call(table.tBodies[0].rows, function (row) {
+        var text = row.textContent.toLowerCase();
+        row.hidden = !words.every(function (word) {
+          return text.indexOf(word) !== -1;
+        });
+      });
+    });
+  });
+})();
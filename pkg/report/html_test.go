@@ -0,0 +1,84 @@
+package report
+
+import (
+	"bytes"
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/controlplaneio/kubesec/v2/pkg/ruler"
+)
+
+func TestHTMLObjectOf(t *testing.T) {
+	threshold := 5
+	prod := "prod"
+	reports := []ruler.Report{
+		{Object: "Deployment/zero.default", FileName: "zero.yaml", Valid: true, Score: 0},
+		{Object: "Deployment/scored.prod", FileName: "scored.yaml", Valid: true, Score: 3, Identity: ruler.Identity{Namespace: &prod}},
+		{Object: "Deployment/profiled.default", FileName: "profiled.yaml", Valid: true, Score: 3, Threshold: &threshold, Profile: "restricted"},
+		{Object: "Deployment/invalid.default", FileName: "invalid.yaml", Message: "Failed to validate against schema"},
+		ruler.NewErrorReport("broken.yaml", 1, 7, ruler.ErrorReasonParse, errors.New("mapping values are not allowed")),
+	}
+
+	var tests = []struct {
+		status    string
+		namespace string
+	}{
+		// a score not above zero fails the default policy, as it fails the
+		// exit code of the scan
+		{status: "failed", namespace: "(unset)"},
+		{status: "passed", namespace: "prod"},
+		{status: "failed", namespace: "(unset)"},
+		{status: "invalid", namespace: "(unset)"},
+		{status: "error", namespace: "(unset)"},
+	}
+
+	decisions := decideReports(nil, reports)
+	for i, tt := range tests {
+		t.Run(reports[i].Object, func(t *testing.T) {
+			o := htmlObjectOf(reports[i], decisions[i])
+			if o.Status != tt.status || o.Namespace != tt.namespace {
+				t.Errorf("Got status %s in namespace %s wanted %s in %s", o.Status, o.Namespace, tt.status, tt.namespace)
+			}
+		})
+	}
+}
+
+func TestHTMLWriter_Namespaces(t *testing.T) {
+	const defaultManifest = `apiVersion: v1
+kind: Pod
+metadata:
+  name: batch
+  namespace: default
+spec:
+  containers:
+    - name: batch
+      image: batch
+`
+	reports := scan(t, "pod.yaml", podManifest, "workloads.yaml", workloadsManifest, "default.yaml", defaultManifest)
+
+	var buf bytes.Buffer
+	if err := (HTMLWriter{Output: &buf}).Write(reports); err != nil {
+		t.Fatal(err.Error())
+	}
+	out := buf.String()
+	start := strings.Index(out, `<table id="namespaces-table"`)
+	if start < 0 {
+		t.Fatalf("Got %s wanted the namespaces table", out)
+	}
+	table := out[start : start+strings.Index(out[start:], "</table>")]
+
+	row := regexp.MustCompile(`<tr>\s*<td>([^<]*)</td>\s*<td>(\d+)</td>\s*<td>(\d+)</td>`)
+	var got []string
+	for _, m := range row.FindAllStringSubmatch(table, -1) {
+		got = append(got, m[1]+" "+m[2]+" "+m[3])
+	}
+
+	// the deployment sets no namespace, it is not rolled up with the objects
+	// of the default namespace
+	wanted := []string{"(unset) 1 0", "default 1 1", "kube-system 1 1", "prod 1 1"}
+	if strings.Join(got, ", ") != strings.Join(wanted, ", ") {
+		t.Errorf("Got namespaces %v wanted %v", got, wanted)
+	}
+}
@@ -0,0 +1,218 @@
+package report
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/controlplaneio/kubesec/v2/pkg/ruler"
+)
+
+// JUnitWriter implements result Writer for the JUnit XML format, as rendered
+// by the test tabs of CI systems. Each object is a test suite and each rule
+// applied to it a test case: critical rules fail, suppressed rules are
+// skipped, and objects which could not be scanned or are invalid error. The
+// ExitPolicy test case of an object fails as the object fails the exit code.
+type JUnitWriter struct {
+	Output io.Writer
+	// Policy decides which objects pass, the default policy applies if nil
+	Policy *ruler.ExitPolicy
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name       string          `xml:"name,attr"`
+	Tests      int             `xml:"tests,attr"`
+	Failures   int             `xml:"failures,attr"`
+	Errors     int             `xml:"errors,attr"`
+	Skipped    int             `xml:"skipped,attr"`
+	Timestamp  string          `xml:"timestamp,attr"`
+	Properties []junitProperty `xml:"properties>property,omitempty"`
+	TestCases  []junitTestCase `xml:"testcase"`
+	SystemOut  string          `xml:"system-out,omitempty"`
+}
+
+type junitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	File      string        `xml:"file,attr,omitempty"`
+	Line      int           `xml:"line,attr,omitempty"`
+	Failure   *junitResult  `xml:"failure,omitempty"`
+	Error     *junitResult  `xml:"error,omitempty"`
+	Skipped   *junitSkipped `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitResult struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",cdata"`
+}
+
+type junitSkipped struct {
+	Message string `xml:"message,attr"`
+}
+
+// Write writes the reports as JUnit test suites
+func (jw JUnitWriter) Write(reports reports) error {
+	timestamp := Now().UTC().Format(time.RFC3339)
+
+	decisions := decideReports(jw.Policy, reports)
+
+	suites := junitTestSuites{Name: "kubesec"}
+	for i, r := range reports {
+		suite := junitSuite(r, decisions[i])
+		suite.Timestamp = timestamp
+
+		suites.Tests += suite.Tests
+		suites.Failures += suite.Failures
+		suites.Errors += suite.Errors
+		suites.Skipped += suite.Skipped
+		suites.Suites = append(suites.Suites, suite)
+	}
+
+	out, err := xml.MarshalIndent(suites, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(jw.Output, "%s%s\n", xml.Header, out)
+	return err
+}
+
+// junitSuite returns the test suite of a report and the decision of the
+// policy for it
+func junitSuite(r ruler.Report, d ruler.ObjectDecision) junitTestSuite {
+	suite := junitTestSuite{
+		Name: fmt.Sprintf("%s (%s)", r.Object, reportFile(r)),
+		Properties: []junitProperty{
+			{Name: "file", Value: reportFile(r)},
+			{Name: "object", Value: r.Object},
+			{Name: "score", Value: fmt.Sprintf("%d", r.Score)},
+		},
+		SystemOut: r.Message,
+	}
+	if r.Profile != "" {
+		suite.Properties = append(suite.Properties, junitProperty{Name: "profile", Value: r.Profile})
+	}
+
+	testCase := func(name string) junitTestCase {
+		tc := junitTestCase{Name: name, ClassName: r.Object, File: r.FileName}
+		if r.Location != nil {
+			tc.Line = r.Location.Line
+		}
+		return tc
+	}
+
+	switch {
+	case r.Error != nil:
+		tc := testCase(r.Error.Reason)
+		if r.Error.Location != nil {
+			tc.Line = r.Error.Location.Line
+		}
+		tc.Error = &junitResult{Message: r.Error.Message, Type: r.Error.Reason}
+		suite.TestCases = append(suite.TestCases, tc)
+		suite.Tests, suite.Errors = 1, 1
+		return suite
+	case !r.Valid:
+		var lines []string
+		for _, e := range r.SchemaErrors {
+			line := fmt.Sprintf("%s: %s", e.Path, e.Message)
+			if e.Location != nil {
+				line += fmt.Sprintf(" (line %d, column %d)", e.Location.Line, e.Location.Column)
+			}
+			lines = append(lines, line)
+		}
+		tc := testCase("SchemaValidation")
+		tc.Error = &junitResult{Message: strings.TrimSpace(r.Message), Type: "SchemaInvalid", Text: strings.Join(lines, "\n")}
+		suite.TestCases = append(suite.TestCases, tc)
+		suite.Tests, suite.Errors = 1, 1
+		return suite
+	}
+
+	critical := refsByID(r.Scoring.Critical)
+	advise := refsByID(r.Scoring.Advise)
+	suppressed := refsByID(r.Scoring.Suppressed)
+
+	applied := append([]ruler.RuleRef{}, r.Rules...)
+	sort.Slice(applied, func(i, j int) bool { return applied[i].ID < applied[j].ID })
+
+	for _, ref := range applied {
+		tc := testCase(ref.ID)
+		switch {
+		case critical[ref.ID] != nil:
+			ref = *critical[ref.ID]
+			if line := firstFindingLine(ref); line > 0 {
+				tc.Line = line
+			}
+			tc.Failure = &junitResult{Message: ref.Reason, Type: string(ref.Severity), Text: junitDetails(ref)}
+			suite.Failures++
+		case suppressed[ref.ID] != nil:
+			ref = *suppressed[ref.ID]
+			tc.Skipped = &junitSkipped{Message: fmt.Sprintf("suppressed by %s: %s", ref.Suppression.Source, ref.Suppression.Justification)}
+			suite.Skipped++
+		case advise[ref.ID] != nil:
+			tc.SystemOut = fmt.Sprintf("advise: %s (%d points)", ref.Reason, ref.Points)
+		}
+		suite.TestCases = append(suite.TestCases, tc)
+		suite.Tests++
+	}
+
+	tc := testCase("ExitPolicy")
+	if d.Outcome == ruler.OutcomePolicyFailed {
+		tc.Failure = &junitResult{Message: strings.Join(d.Reasons, "; "), Type: "PolicyFailed", Text: strings.Join(d.Reasons, "\n")}
+		suite.Failures++
+	}
+	suite.TestCases = append(suite.TestCases, tc)
+	suite.Tests++
+	return suite
+}
+
+// junitDetails describes the selector and findings of a failed rule
+func junitDetails(ref ruler.RuleRef) string {
+	lines := []string{fmt.Sprintf("%s (%d points)", ref.Selector, ref.Points)}
+	for _, f := range ref.Findings {
+		finding := f.String()
+		if f.Location != nil {
+			finding += fmt.Sprintf(" (line %d, column %d)", f.Location.Line, f.Location.Column)
+		}
+		lines = append(lines, finding)
+	}
+	if s := ref.Suppression; s != nil && s.Expired {
+		lines = append(lines, fmt.Sprintf("Exception expired on %s (owner: %s)", s.Expires, s.Owner))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func refsByID(refs []ruler.RuleRef) map[string]*ruler.RuleRef {
+	byID := make(map[string]*ruler.RuleRef, len(refs))
+	for i := range refs {
+		byID[refs[i].ID] = &refs[i]
+	}
+	return byID
+}
+
+func firstFindingLine(ref ruler.RuleRef) int {
+	for _, f := range ref.Findings {
+		if f.Location != nil {
+			return f.Location.Line
+		}
+	}
+	return 0
+}
@@ -0,0 +1,117 @@
+package report
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"testing"
+
+	"github.com/controlplaneio/kubesec/v2/pkg/ruler"
+)
+
+func TestJUnitWriter(t *testing.T) {
+	reports := scan(t, "pod.yaml", podManifest, "workloads.yaml", workloadsManifest)
+
+	invalid := reports[1]
+	invalid.Valid = false
+	invalid.Message = "Failed to validate against schema"
+	reports = append(reports, invalid, ruler.NewErrorReport("broken.yaml", 1, 7, ruler.ErrorReasonParse, errors.New("mapping values are not allowed")))
+
+	var buf bytes.Buffer
+	if err := (JUnitWriter{Output: &buf}).Write(reports); err != nil {
+		t.Fatal(err.Error())
+	}
+	var suites junitTestSuites
+	if err := xml.Unmarshal(buf.Bytes(), &suites); err != nil {
+		t.Fatalf("Got invalid XML: %v\n%s", err, buf.String())
+	}
+
+	if len(suites.Suites) != len(reports) {
+		t.Fatalf("Got %d test suites wanted %d", len(suites.Suites), len(reports))
+	}
+
+	// every rule applied to a scanned object and the exit policy are test
+	// cases, the invalid objects and documents a single erroring one
+	type totals struct{ tests, failures, errors, skipped int }
+	var wanted totals
+	var policyFailed int
+	for i, d := range (&ruler.ExitPolicy{}).Decide(reports, ruler.ExitCodes{}).Objects {
+		r := reports[i]
+		switch d.Outcome {
+		case ruler.OutcomeInvalidInput, ruler.OutcomeSchemaInvalid:
+			wanted.tests++
+			wanted.errors++
+			continue
+		case ruler.OutcomePolicyFailed:
+			policyFailed++
+		}
+		wanted.tests += len(r.Rules) + 1
+		wanted.failures += len(r.Scoring.Critical)
+		wanted.skipped += len(r.Scoring.Suppressed)
+	}
+	wanted.failures += policyFailed
+	if got := (totals{suites.Tests, suites.Failures, suites.Errors, suites.Skipped}); got != wanted {
+		t.Errorf("Got totals %+v wanted %+v", got, wanted)
+	}
+
+	var cases = []struct {
+		name     string
+		failures int
+		errors   int
+		skipped  int
+		errType  string
+		policy   string
+	}{
+		// HostNetwork is suppressed by the annotations of the pod
+		{name: "Pod/web.prod (pod.yaml)", failures: 3, skipped: 1, policy: "PolicyFailed"},
+		{name: "Deployment/api.default (workloads.yaml)", policy: "passed"},
+		{name: "DaemonSet/agent.kube-system (workloads.yaml)", failures: 2, policy: "PolicyFailed"},
+		{name: "Deployment/api.default (workloads.yaml)", errors: 1, errType: "SchemaInvalid"},
+		{name: "Unknown (broken.yaml)", errors: 1, errType: ruler.ErrorReasonParse},
+	}
+	for i, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			suite := suites.Suites[i]
+			if suite.Name != tt.name {
+				t.Errorf("Got test suite %s wanted %s", suite.Name, tt.name)
+			}
+			if suite.Failures != tt.failures || suite.Errors != tt.errors || suite.Skipped != tt.skipped {
+				t.Errorf("Got %d failures, %d errors and %d skipped wanted %d, %d and %d",
+					suite.Failures, suite.Errors, suite.Skipped, tt.failures, tt.errors, tt.skipped)
+			}
+			if tt.errType != "" {
+				if len(suite.TestCases) != 1 || suite.TestCases[0].Error == nil || suite.TestCases[0].Error.Type != tt.errType {
+					t.Errorf("Got test cases %+v wanted a single %s error", suite.TestCases, tt.errType)
+				}
+			}
+			if tt.policy != "" {
+				tc := suite.TestCases[len(suite.TestCases)-1]
+				got := "passed"
+				if tc.Failure != nil {
+					got = tc.Failure.Type
+				}
+				if tc.Name != "ExitPolicy" || got != tt.policy {
+					t.Errorf("Got test case %s %s wanted ExitPolicy %s", tc.Name, got, tt.policy)
+				}
+			}
+		})
+	}
+
+	t.Run("policy", func(t *testing.T) {
+		ignoreScore := true
+		policy := &ruler.ExitPolicy{PolicyConditions: ruler.PolicyConditions{IgnoreScore: &ignoreScore}}
+
+		var buf bytes.Buffer
+		if err := (JUnitWriter{Output: &buf, Policy: policy}).Write(reports); err != nil {
+			t.Fatal(err.Error())
+		}
+		var suites junitTestSuites
+		if err := xml.Unmarshal(buf.Bytes(), &suites); err != nil {
+			t.Fatal(err.Error())
+		}
+		// the critical rules still fail, the objects pass the policy
+		if suites.Failures != wanted.failures-policyFailed {
+			t.Errorf("Got %d failures wanted %d", suites.Failures, wanted.failures-policyFailed)
+		}
+	})
+}
@@ -0,0 +1,280 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/controlplaneio/kubesec/v2/pkg/ruler"
+	"github.com/controlplaneio/kubesec/v2/pkg/rules"
+)
+
+// DefaultMarkdownMaxLength is the length limit of the comments of GitHub
+// pull requests
+const DefaultMarkdownMaxLength = 65536
+
+// MarkdownWriter implements result Writer for the Markdown format, to post
+// the reports as pull request comments
+type MarkdownWriter struct {
+	Output io.Writer
+	// Baseline are the reports of a previous scan, the scores are compared
+	// to theirs when set
+	Baseline []ruler.Report
+	// MaxLength truncates the output to fit the length limit of comments,
+	// the output is not truncated if 0
+	MaxLength int
+	// Policy decides which objects pass, the default policy applies if nil
+	Policy *ruler.ExitPolicy
+}
+
+// LoadBaseline reads the reports of a previous scan in the JSON format
+func LoadBaseline(path string) ([]ruler.Report, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var baseline []ruler.Report
+	if err := json.Unmarshal(data, &baseline); err != nil {
+		return nil, fmt.Errorf("baseline %s: %w", path, err)
+	}
+	return baseline, nil
+}
+
+// Write writes a summary table of the objects and a collapsible section of
+// the findings of each object, dropping the sections which do not fit the
+// maximum length
+func (mw MarkdownWriter) Write(reports reports) error {
+	baseline := make(map[string]ruler.Report, len(mw.Baseline))
+	for _, r := range mw.Baseline {
+		if _, ok := baseline[markdownKey(r)]; !ok {
+			baseline[markdownKey(r)] = r
+		}
+	}
+
+	decisions := decideReports(mw.Policy, reports)
+
+	var failed int
+	scanned := make(map[string]bool, len(reports))
+	for i, r := range reports {
+		scanned[markdownKey(r)] = true
+		if decisions[i].Outcome != ruler.OutcomePassed {
+			failed++
+		}
+	}
+
+	var head strings.Builder
+	head.WriteString("## 🛡️ Kubesec Scan Report\n\n")
+	fmt.Fprintf(&head, "%d of %d objects failed.\n\n", failed, len(reports))
+
+	header := "| | Object | File | Score | Critical | Advise |\n| --- | --- | --- | --- | --- | --- |\n"
+	if mw.Baseline != nil {
+		header = "| | Object | File | Score | Change | Critical | Advise |\n| --- | --- | --- | --- | --- | --- | --- |\n"
+	}
+
+	rows := make([]string, 0, len(reports))
+	sections := make([]string, 0, len(reports))
+	for i, r := range reports {
+		previous, ok := baseline[markdownKey(r)]
+		change := ""
+		if mw.Baseline != nil {
+			change = "new"
+			if ok {
+				change = scoreChange(r.Score - previous.Score)
+			}
+		}
+		rows = append(rows, markdownRow(r, decisions[i], change, mw.Baseline != nil))
+		sections = append(sections, markdownSection(r, decisions[i], change))
+	}
+
+	var removed []string
+	for _, r := range mw.Baseline {
+		if !scanned[markdownKey(r)] {
+			removed = append(removed, fmt.Sprintf("%s (%s)", markdownCode(r.Object), markdownEscape(reportFile(r))))
+		}
+	}
+	var tail string
+	if len(removed) > 0 {
+		tail = fmt.Sprintf("No longer scanned: %s.\n", strings.Join(removed, ", "))
+	}
+
+	out := truncateMarkdown(head.String(), header, rows, sections, tail, mw.MaxLength)
+	_, err := fmt.Fprint(mw.Output, out)
+	return err
+}
+
+// truncateMarkdown joins the parts of the output, leaving out the rows and
+// sections past the maximum length in characters along with a notice
+func truncateMarkdown(head, header string, rows, sections []string, tail string, maxLength int) string {
+	full := head + header + strings.Join(rows, "") + "\n" + strings.Join(sections, "") + tail
+	if maxLength <= 0 || utf8.RuneCountInString(full) <= maxLength {
+		return full
+	}
+
+	notice := func(n int) string {
+		return fmt.Sprintf("> [!NOTE]\n> The findings of %d objects were left out to fit the length limit, see the full report in the JSON output.\n\n", n)
+	}
+
+	var b strings.Builder
+	length := 0
+	write := func(s string) {
+		b.WriteString(s)
+		length += utf8.RuneCountInString(s)
+	}
+	// fits reports whether s fits along with the notice of the objects left
+	// out and the tail
+	fits := func(s string, left int) bool {
+		return length+utf8.RuneCountInString(s+notice(left)+tail) <= maxLength
+	}
+
+	write(head)
+	write(header)
+
+	// at most every object is left out, the row ends the table with a newline
+	shown := 0
+	for _, row := range rows {
+		if !fits(row+"\n", len(rows)) {
+			break
+		}
+		write(row)
+		shown++
+	}
+	write("\n")
+
+	// a section is left out with the rows of the objects after it
+	details := 0
+	for details < shown && fits(sections[details], len(rows)-details-1) {
+		write(sections[details])
+		details++
+	}
+
+	write(notice(len(rows) - details))
+	write(tail)
+	return b.String()
+}
+
+func markdownRow(r ruler.Report, d ruler.ObjectDecision, change string, withChange bool) string {
+	cells := []string{
+		markdownStatus(d.Outcome),
+		markdownCode(r.Object),
+		markdownEscape(reportFile(r)),
+		fmt.Sprintf("%d", r.Score),
+	}
+	if withChange {
+		cells = append(cells, change)
+	}
+	cells = append(cells, fmt.Sprintf("%d", len(r.Scoring.Critical)), fmt.Sprintf("%d", len(r.Scoring.Advise)))
+	return "| " + strings.Join(cells, " | ") + " |\n"
+}
+
+// markdownSection returns the collapsible findings of an object, critical
+// findings first, with the reasons it failed the policy
+func markdownSection(r ruler.Report, d ruler.ObjectDecision, change string) string {
+	var b strings.Builder
+
+	summary := fmt.Sprintf("%s <code>%s</code> (%s), score %d", markdownStatus(d.Outcome), markdownEscape(r.Object), markdownEscape(reportFile(r)), r.Score)
+	if change != "" {
+		summary += fmt.Sprintf(" (%s)", change)
+	}
+	fmt.Fprintf(&b, "<details>\n<summary>%s</summary>\n\n", summary)
+	if d.Outcome == ruler.OutcomePolicyFailed {
+		fmt.Fprintf(&b, "Failed: %s.\n\n", markdownEscape(strings.Join(d.Reasons, "; ")))
+	}
+
+	switch {
+	case r.Error != nil:
+		fmt.Fprintf(&b, "%s: %s\n", r.Error.Reason, markdownEscape(r.Error.Message))
+	case !r.Valid:
+		fmt.Fprintf(&b, "%s\n", markdownEscape(strings.TrimSpace(r.Message)))
+		if len(r.SchemaErrors) > 0 {
+			b.WriteString("\n| Path | Error | Line |\n| --- | --- | --- |\n")
+			for _, e := range r.SchemaErrors {
+				fmt.Fprintf(&b, "| %s | %s | %s |\n", markdownCode(e.Path), markdownEscape(e.Message), markdownLine(e.Location))
+			}
+		}
+	case len(r.Scoring.Critical)+len(r.Scoring.Advise)+len(r.Scoring.Suppressed) == 0:
+		fmt.Fprintf(&b, "%s\n", markdownEscape(r.Message))
+	default:
+		b.WriteString("| Status | Severity | Rule | Reason | Points | Line |\n| --- | --- | --- | --- | --- | --- |\n")
+		groups := []struct {
+			status string
+			refs   []ruler.RuleRef
+		}{
+			{"🔴 Critical", r.Scoring.Critical},
+			{"🟡 Advise", r.Scoring.Advise},
+			{"⚪ Suppressed", r.Scoring.Suppressed},
+		}
+		for _, g := range groups {
+			for _, ref := range g.refs {
+				reason := markdownEscape(ref.Reason)
+				for _, f := range ref.Findings {
+					reason += "<br>" + markdownCode(f.String())
+				}
+				if s := ref.Suppression; s != nil {
+					if s.Expired {
+						reason += "<br>Exception expired on " + markdownEscape(s.Expires)
+					} else {
+						reason += "<br>Suppressed: " + markdownEscape(s.Justification)
+					}
+				}
+
+				var line string
+				if len(ref.Findings) > 0 {
+					line = markdownLine(ref.Findings[0].Location)
+				}
+				fmt.Fprintf(&b, "| %s | %s | %s | %s | %d | %s |\n", g.status, ref.Severity, markdownCode(ref.ID), reason, ref.Points, line)
+			}
+		}
+	}
+
+	b.WriteString("\n</details>\n\n")
+	return b.String()
+}
+
+// markdownStatus returns the status of the outcome of an object
+func markdownStatus(outcome ruler.Outcome) string {
+	switch outcome {
+	case ruler.OutcomePassed:
+		return "✅"
+	case ruler.OutcomePolicyFailed:
+		return "❌"
+	default:
+		return "⚠️"
+	}
+}
+
+// markdownKey identifies an object across scans
+func markdownKey(r ruler.Report) string {
+	return reportFile(r) + "\x00" + r.Object
+}
+
+func scoreChange(delta int) string {
+	switch {
+	case delta > 0:
+		return fmt.Sprintf("⬆️ +%d", delta)
+	case delta < 0:
+		return fmt.Sprintf("⬇️ %d", delta)
+	default:
+		return "±0"
+	}
+}
+
+func markdownLine(location *rules.Location) string {
+	if location == nil {
+		return ""
+	}
+	return fmt.Sprintf("%d", location.Line)
+}
+
+// markdownEscape keeps text on one line and out of the table and HTML syntax
+func markdownEscape(s string) string {
+	return strings.NewReplacer("|", "\\|", "\r\n", "<br>", "\n", "<br>", "<", "&lt;", ">", "&gt;").Replace(s)
+}
+
+// markdownCode returns text as a code span of a table cell
+func markdownCode(s string) string {
+	return "`" + strings.NewReplacer("|", "\\|", "\r\n", " ", "\n", " ", "`", "'").Replace(s) + "`"
+}
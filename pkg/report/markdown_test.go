@@ -0,0 +1,138 @@
+package report
+
+import (
+	"bytes"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/controlplaneio/kubesec/v2/pkg/ruler"
+)
+
+func TestMarkdownWriter_Status(t *testing.T) {
+	threshold := 5
+	ignoreScore := true
+	reports := []ruler.Report{
+		{Object: "Deployment/zero.default", FileName: "zero.yaml", Valid: true, Score: 0},
+		{Object: "Deployment/scored.default", FileName: "scored.yaml", Valid: true, Score: 3},
+		{Object: "Deployment/profiled.default", FileName: "profiled.yaml", Valid: true, Score: 3, Threshold: &threshold, Profile: "restricted"},
+		{Object: "Deployment/invalid.default", FileName: "invalid.yaml", Message: "Failed to validate against schema"},
+	}
+
+	var tests = []struct {
+		name   string
+		policy *ruler.ExitPolicy
+		status []string
+		failed int
+	}{
+		{
+			// a score not above zero fails the default policy, as it fails
+			// the exit code of the scan
+			name:   "default policy",
+			status: []string{"❌", "✅", "❌", "⚠️"},
+			failed: 3,
+		},
+		{
+			name:   "ignored scores",
+			policy: &ruler.ExitPolicy{PolicyConditions: ruler.PolicyConditions{IgnoreScore: &ignoreScore}},
+			status: []string{"✅", "✅", "✅", "⚠️"},
+			failed: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := (MarkdownWriter{Output: &buf, Policy: tt.policy}).Write(reports); err != nil {
+				t.Fatal(err.Error())
+			}
+			out := buf.String()
+
+			if summary := strconv.Itoa(tt.failed) + " of 4 objects failed."; !strings.Contains(out, summary) {
+				t.Errorf("Got %s wanted the summary %s", out, summary)
+			}
+			for i, r := range reports {
+				row := "| " + tt.status[i] + " | " + markdownCode(r.Object) + " |"
+				if !strings.Contains(out, row) {
+					t.Errorf("Got %s wanted the row %s", out, row)
+				}
+			}
+		})
+	}
+}
+
+func TestTruncateMarkdown(t *testing.T) {
+	head := "## 🛡️ Kubesec Scan Report\n\n"
+	header := "| | Object |\n| --- | --- |\n"
+	tail := "No longer scanned: `Pod/gone.default` (gone.yaml).\n"
+	var rows, sections []string
+	for _, name := range []string{"äpi", "wéb", "ägent"} {
+		rows = append(rows, "| ❌ | "+name+" |\n")
+		sections = append(sections, "<details>\n<summary>❌ "+name+"</summary>\n\n"+strings.Repeat("🔴 ", 80)+"\n</details>\n\n")
+	}
+
+	full := utf8.RuneCountInString(truncateMarkdown(head, header, rows, sections, tail, 0))
+	// the head, table header and notice of the objects left out
+	minimum := utf8.RuneCountInString(head + header + "\n" + tail +
+		"> [!NOTE]\n> The findings of 3 objects were left out to fit the length limit, see the full report in the JSON output.\n\n")
+
+	var tests = []struct {
+		name      string
+		maxLength int
+		left      int
+		rows      int
+	}{
+		{name: "unlimited", maxLength: 0, left: 0, rows: 3},
+		{name: "full length", maxLength: full, left: 0, rows: 3},
+		{name: "a character short", maxLength: full - 1, left: 1, rows: 3},
+		{name: "minimum length", maxLength: minimum, left: 3, rows: 0},
+		{name: "a row", maxLength: minimum + utf8.RuneCountInString(rows[0]), left: 3, rows: 1},
+	}
+
+	notice := regexp.MustCompile(`The findings of (\d+) objects were left out`)
+	check := func(t *testing.T, maxLength int) (int, int) {
+		t.Helper()
+		out := truncateMarkdown(head, header, rows, sections, tail, maxLength)
+		if maxLength > 0 && utf8.RuneCountInString(out) > maxLength {
+			t.Errorf("Got %d characters wanted at most %d", utf8.RuneCountInString(out), maxLength)
+		}
+		if !strings.HasSuffix(out, tail) {
+			t.Errorf("Got %s wanted the tail %s", out, tail)
+		}
+
+		var left int
+		if m := notice.FindStringSubmatch(out); m != nil {
+			left, _ = strconv.Atoi(m[1])
+		}
+		var shown, details int
+		for i := range rows {
+			if strings.Contains(out, rows[i]) {
+				shown++
+			}
+			if strings.Contains(out, sections[i]) {
+				details++
+			}
+		}
+		if left != len(rows)-details {
+			t.Errorf("Got a notice of %d objects wanted %d", left, len(rows)-details)
+		}
+		return left, shown
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			left, shown := check(t, tt.maxLength)
+			if left != tt.left || shown != tt.rows {
+				t.Errorf("Got %d objects left out and %d rows wanted %d and %d", left, shown, tt.left, tt.rows)
+			}
+		})
+	}
+
+	t.Run("every length", func(t *testing.T) {
+		for maxLength := minimum; maxLength <= full; maxLength++ {
+			check(t, maxLength)
+		}
+	})
+}
@@ -0,0 +1,415 @@
+package report
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/controlplaneio/kubesec/v2/pkg/ruler"
+	"github.com/controlplaneio/kubesec/v2/pkg/rules"
+)
+
+const (
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifVersion = "2.1.0"
+	// sarifFingerprint is the key of the partial fingerprints of the results,
+	// its version changes if the fingerprints are computed differently
+	sarifFingerprint = "kubesecFinding/v1"
+	// sarifSourceRoot is the base of the relative URIs of the scanned files
+	sarifSourceRoot = "%SRCROOT%"
+)
+
+// SARIFWriter implements result Writer for the SARIF 2.1.0 format, as read
+// by code scanning dashboards
+type SARIFWriter struct {
+	Output io.Writer
+}
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool        sarifTool         `json:"tool"`
+	Invocations []sarifInvocation `json:"invocations"`
+	Results     []sarifResult     `json:"results"`
+	ColumnKind  string            `json:"columnKind"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	FullName       string      `json:"fullName"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string                 `json:"id"`
+	ShortDescription     sarifMessage           `json:"shortDescription"`
+	FullDescription      sarifMessage           `json:"fullDescription"`
+	Help                 sarifMessage           `json:"help"`
+	HelpURI              string                 `json:"helpUri,omitempty"`
+	DefaultConfiguration sarifConfiguration     `json:"defaultConfiguration"`
+	Properties           map[string]interface{} `json:"properties"`
+}
+
+type sarifConfiguration struct {
+	Level string `json:"level"`
+}
+
+type sarifMessage struct {
+	Text     string `json:"text"`
+	Markdown string `json:"markdown,omitempty"`
+}
+
+type sarifInvocation struct {
+	ExecutionSuccessful        bool                `json:"executionSuccessful"`
+	ToolExecutionNotifications []sarifNotification `json:"toolExecutionNotifications,omitempty"`
+}
+
+type sarifNotification struct {
+	Descriptor sarifDescriptor `json:"descriptor"`
+	Level      string          `json:"level"`
+	Message    sarifMessage    `json:"message"`
+	Locations  []sarifLocation `json:"locations,omitempty"`
+}
+
+type sarifDescriptor struct {
+	ID string `json:"id"`
+}
+
+type sarifResult struct {
+	RuleID              string                 `json:"ruleId"`
+	RuleIndex           int                    `json:"ruleIndex"`
+	Level               string                 `json:"level"`
+	Message             sarifMessage           `json:"message"`
+	Locations           []sarifLocation        `json:"locations"`
+	PartialFingerprints map[string]string      `json:"partialFingerprints"`
+	Suppressions        []sarifSuppression     `json:"suppressions,omitempty"`
+	Properties          map[string]interface{} `json:"properties,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation  `json:"physicalLocation"`
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations,omitempty"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI       string `json:"uri"`
+	URIBaseID string `json:"uriBaseId,omitempty"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+}
+
+type sarifLogicalLocation struct {
+	Name               string `json:"name,omitempty"`
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+	Kind               string `json:"kind"`
+}
+
+type sarifSuppression struct {
+	Kind          string `json:"kind"`
+	Status        string `json:"status"`
+	Justification string `json:"justification,omitempty"`
+}
+
+// Write writes the reports as a single SARIF run, with the catalogue of the
+// rules applied and a result per finding of the failed and suppressed rules
+func (sw SARIFWriter) Write(reports reports) error {
+	catalogue := sarifCatalogue(reports)
+	index := make(map[string]int, len(catalogue))
+	for i, rule := range catalogue {
+		index[rule.ID] = i
+	}
+
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           "Kubesec",
+			FullName:       "Kubesec Kubernetes Resource Security Policy Validator",
+			InformationURI: "https://github.com/controlplaneio/kubesec",
+			Rules:          catalogue,
+		}},
+		Invocations: []sarifInvocation{{ExecutionSuccessful: true}},
+		Results:     []sarifResult{},
+		ColumnKind:  "unicodeCodePoints",
+	}
+
+	for _, r := range reports {
+		if r.Error != nil || !r.Valid {
+			run.Invocations[0].ToolExecutionNotifications = append(run.Invocations[0].ToolExecutionNotifications, sarifNotifications(r)...)
+			// invalid objects are scanned, documents which could not be
+			// read or parsed are not
+			if r.Error != nil {
+				run.Invocations[0].ExecutionSuccessful = false
+			}
+			continue
+		}
+
+		for _, group := range [][]ruler.RuleRef{r.Scoring.Critical, r.Scoring.Advise, r.Scoring.Suppressed} {
+			for _, ref := range group {
+				run.Results = append(run.Results, sarifResults(r, ref, index[ref.ID])...)
+			}
+		}
+	}
+
+	out, err := json.MarshalIndent(sarifLog{Schema: sarifSchema, Version: sarifVersion, Runs: []sarifRun{run}}, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(sw.Output, string(out))
+	return err
+}
+
+// sarifCatalogue returns the rules applied to the reports, sorted by ID
+func sarifCatalogue(reports reports) []sarifRule {
+	seen := make(map[string]bool)
+	var refs []ruler.RuleRef
+	add := func(rs []ruler.RuleRef) {
+		for _, ref := range rs {
+			if !seen[ref.ID] {
+				seen[ref.ID] = true
+				refs = append(refs, ref)
+			}
+		}
+	}
+	for _, r := range reports {
+		add(r.Rules)
+		add(r.Scoring.Critical)
+		add(r.Scoring.Advise)
+		add(r.Scoring.Passed)
+		add(r.Scoring.Suppressed)
+	}
+	sort.Slice(refs, func(i, j int) bool { return refs[i].ID < refs[j].ID })
+
+	catalogue := make([]sarifRule, 0, len(refs))
+	for _, ref := range refs {
+		catalogue = append(catalogue, sarifCatalogueRule(ref))
+	}
+	return catalogue
+}
+
+func sarifCatalogueRule(ref ruler.RuleRef) sarifRule {
+	text := []string{endWithPeriod(ref.Reason), "Selector: " + ref.Selector}
+	markdown := []string{endWithPeriod(ref.Reason), "Selector: `" + ref.Selector + "`"}
+	tags := []string{"security", "kubernetes"}
+
+	if len(ref.Compliance) > 0 {
+		var controls []string
+		for _, c := range ref.Compliance {
+			control := c.String()
+			if c.Title != "" {
+				control += " " + c.Title
+			}
+			controls = append(controls, control)
+			tags = append(tags, c.String())
+		}
+		text = append(text, "Compliance: "+strings.Join(controls, ", "))
+		markdown = append(markdown, "Compliance:\n\n- "+strings.Join(controls, "\n- "))
+	}
+	if ref.Link != "" {
+		markdown = append(markdown, fmt.Sprintf("[Documentation](%s)", ref.Link))
+	}
+
+	properties := map[string]interface{}{
+		"points":   ref.Points,
+		"severity": ref.Severity,
+		"tags":     tags,
+	}
+	if score := sarifSecuritySeverity(ref.Severity); score != "" {
+		properties["security-severity"] = score
+	}
+
+	return sarifRule{
+		ID:                   ref.ID,
+		ShortDescription:     sarifMessage{Text: ref.ID},
+		FullDescription:      sarifMessage{Text: endWithPeriod(ref.Reason)},
+		Help:                 sarifMessage{Text: strings.Join(text, "\n\n"), Markdown: strings.Join(markdown, "\n\n")},
+		HelpURI:              ref.Link,
+		DefaultConfiguration: sarifConfiguration{Level: sarifLevel(ref.Severity)},
+		Properties:           properties,
+	}
+}
+
+// sarifResults returns a result per finding of a rule, or a single result at
+// the object for rules without findings
+func sarifResults(r ruler.Report, ref ruler.RuleRef, ruleIndex int) []sarifResult {
+	findings := ref.Findings
+	if len(findings) == 0 {
+		findings = []rules.Finding{{Location: r.Location}}
+	}
+
+	results := make([]sarifResult, 0, len(findings))
+	for _, f := range findings {
+		message := fmt.Sprintf("%s %s.", endWithPeriod(ref.Reason), r.Object)
+		if f.Path != "" {
+			message = fmt.Sprintf("%s %s, %s.", endWithPeriod(ref.Reason), r.Object, f.String())
+		}
+
+		location := sarifFileLocation(r, f.Location)
+		location.LogicalLocations = []sarifLogicalLocation{{FullyQualifiedName: r.Object, Kind: "resource"}}
+		if f.Path != "" {
+			location.LogicalLocations = append(location.LogicalLocations, sarifLogicalLocation{
+				Name:               f.Container,
+				FullyQualifiedName: r.Object + "/" + f.Path,
+				Kind:               "member",
+			})
+		}
+
+		result := sarifResult{
+			RuleID:              ref.ID,
+			RuleIndex:           ruleIndex,
+			Level:               sarifLevel(ref.Severity),
+			Message:             sarifMessage{Text: message},
+			Locations:           []sarifLocation{location},
+			PartialFingerprints: map[string]string{sarifFingerprint: sarifFingerprintOf(r, ref.ID, f.Path)},
+			Properties: map[string]interface{}{
+				"object":   r.Object,
+				"points":   ref.Points,
+				"selector": ref.Selector,
+			},
+		}
+		if s := ref.Suppression; s != nil {
+			suppression := sarifSuppression{Kind: "external", Status: "accepted", Justification: s.Justification}
+			if s.Source == "annotation" {
+				suppression.Kind = "inSource"
+			}
+			if s.Expired {
+				suppression.Status = "rejected"
+			}
+			result.Suppressions = []sarifSuppression{suppression}
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// sarifNotifications returns the errors of the reports which could not be
+// scanned, as they have no results
+func sarifNotifications(r ruler.Report) []sarifNotification {
+	if r.Error != nil {
+		location := r.Error.Location
+		if location == nil {
+			location = r.Location
+		}
+		return []sarifNotification{{
+			Descriptor: sarifDescriptor{ID: r.Error.Reason},
+			Level:      "error",
+			Message:    sarifMessage{Text: endWithPeriod(fmt.Sprintf("%s: %s", reportFile(r), r.Error.Message))},
+			Locations:  []sarifLocation{sarifFileLocation(r, location)},
+		}}
+	}
+
+	if len(r.SchemaErrors) == 0 {
+		return []sarifNotification{{
+			Descriptor: sarifDescriptor{ID: "SchemaInvalid"},
+			Level:      "error",
+			Message:    sarifMessage{Text: endWithPeriod(fmt.Sprintf("%s: %s", r.Object, strings.TrimSpace(r.Message)))},
+			Locations:  []sarifLocation{sarifFileLocation(r, r.Location)},
+		}}
+	}
+	notifications := make([]sarifNotification, 0, len(r.SchemaErrors))
+	for _, e := range r.SchemaErrors {
+		notifications = append(notifications, sarifNotification{
+			Descriptor: sarifDescriptor{ID: "SchemaInvalid"},
+			Level:      "error",
+			Message:    sarifMessage{Text: endWithPeriod(fmt.Sprintf("%s %s: %s", r.Object, e.Path, e.Message))},
+			Locations:  []sarifLocation{sarifFileLocation(r, e.Location)},
+		})
+	}
+	return notifications
+}
+
+// sarifFileLocation returns the location of a position in the file of a
+// report, relative paths being resolved against the source root
+func sarifFileLocation(r ruler.Report, location *rules.Location) sarifLocation {
+	name := filepath.ToSlash(r.FileName)
+	artifact := sarifArtifactLocation{URI: (&url.URL{Path: name}).String(), URIBaseID: sarifSourceRoot}
+	if filepath.IsAbs(r.FileName) {
+		artifact = sarifArtifactLocation{URI: (&url.URL{Scheme: "file", Path: name}).String()}
+	}
+
+	physical := sarifPhysicalLocation{ArtifactLocation: artifact}
+	if location != nil && location.Line > 0 {
+		physical.Region = &sarifRegion{StartLine: location.Line, StartColumn: location.Column}
+	}
+	return sarifLocation{PhysicalLocation: physical}
+}
+
+// sarifFingerprintOf identifies a finding by its file, object, rule and
+// path, so that it keeps its fingerprint when the lines of the file move or
+// other fields of the object change
+func sarifFingerprintOf(r ruler.Report, ruleID, path string) string {
+	id := r.Identity
+	name := id.Name
+	if name == "" {
+		name = id.GenerateName
+	}
+	namespace := ""
+	if id.Namespace != nil {
+		namespace = *id.Namespace
+	}
+	if id.Kind == "" {
+		name = r.Object
+	}
+
+	sum := sha256.Sum256([]byte(strings.Join([]string{
+		filepath.ToSlash(r.FileName), id.Kind, namespace, name, ruleID, path,
+	}, "\x00")))
+	return hex.EncodeToString(sum[:])
+}
+
+// sarifLevel maps the severity of a rule to the level of its results
+func sarifLevel(severity ruler.Severity) string {
+	switch severity {
+	case ruler.SeverityCritical, ruler.SeverityHigh:
+		return "error"
+	case ruler.SeverityMedium:
+		return "warning"
+	default:
+		return "note"
+	}
+}
+
+// sarifSecuritySeverity is the score code scanning dashboards rank the
+// security results of a severity by
+func sarifSecuritySeverity(severity ruler.Severity) string {
+	switch severity {
+	case ruler.SeverityCritical:
+		return "9.5"
+	case ruler.SeverityHigh:
+		return "8.0"
+	case ruler.SeverityMedium:
+		return "5.5"
+	case ruler.SeverityLow:
+		return "2.0"
+	default:
+		return ""
+	}
+}
+
+func endWithPeriod(input string) string {
+	if !strings.HasSuffix(input, ".") {
+		input += "."
+	}
+	return input
+}
@@ -0,0 +1,198 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/controlplaneio/kubesec/v2/pkg/ruler"
+	"github.com/santhosh-tekuri/jsonschema/v6"
+)
+
+func writeSARIF(t *testing.T, reports []ruler.Report) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if err := (SARIFWriter{Output: &buf}).Write(reports); err != nil {
+		t.Fatal(err.Error())
+	}
+	return buf.Bytes()
+}
+
+func TestSARIFWriter_Schema(t *testing.T) {
+	// the schema is the 2.1.0 errata01 schema of OASIS
+	f, err := os.Open("testdata/sarif-schema-2.1.0.json")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer f.Close()
+	doc, err := jsonschema.UnmarshalJSON(f)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	compiler := jsonschema.NewCompiler()
+	if err := compiler.AddResource("sarif-schema-2.1.0.json", doc); err != nil {
+		t.Fatal(err.Error())
+	}
+	schema, err := compiler.Compile("sarif-schema-2.1.0.json")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	reports := scan(t, "pod.yaml", podManifest, "/manifests/workloads.yaml", workloadsManifest)
+	reports = append(reports, ruler.NewErrorReport("broken.yaml", 0, 1, ruler.ErrorReasonParse, os.ErrInvalid))
+
+	out := writeSARIF(t, reports)
+	log, err := jsonschema.UnmarshalJSON(bytes.NewReader(out))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if err := schema.Validate(log); err != nil {
+		t.Errorf("Got invalid SARIF log: %v\n%s", err, out)
+	}
+}
+
+func TestSARIFWriter_Results(t *testing.T) {
+	var log sarifLog
+	if err := json.Unmarshal(writeSARIF(t, scan(t, "pod.yaml", podManifest)), &log); err != nil {
+		t.Fatal(err.Error())
+	}
+	if len(log.Runs) != 1 {
+		t.Fatalf("Got %d runs wanted %d", len(log.Runs), 1)
+	}
+	run := log.Runs[0]
+
+	severities := make(map[string]ruler.Severity)
+	for _, rule := range run.Tool.Driver.Rules {
+		severities[rule.ID] = ruler.Severity(rule.Properties["severity"].(string))
+	}
+
+	results := make(map[string]sarifResult)
+	for _, r := range run.Results {
+		if r.Level != sarifLevel(severities[r.RuleID]) {
+			t.Errorf("Got level %s for %s wanted %s", r.Level, r.RuleID, sarifLevel(severities[r.RuleID]))
+		}
+		if run.Tool.Driver.Rules[r.RuleIndex].ID != r.RuleID {
+			t.Errorf("Got rule index %d for %s", r.RuleIndex, r.RuleID)
+		}
+		results[r.RuleID] = r
+	}
+
+	privileged, ok := results["Privileged"]
+	if !ok {
+		t.Fatalf("Got no result for Privileged in %v", run.Results)
+	}
+	location := privileged.Locations[0].PhysicalLocation
+	if location.ArtifactLocation != (sarifArtifactLocation{URI: "pod.yaml", URIBaseID: "%SRCROOT%"}) {
+		t.Errorf("Got artifact %+v", location.ArtifactLocation)
+	}
+	if location.Region == nil || *location.Region != (sarifRegion{StartLine: 16, StartColumn: 9}) {
+		t.Errorf("Got region %+v wanted line 16 column 9", location.Region)
+	}
+	if privileged.Level != "error" {
+		t.Errorf("Got level %s for Privileged wanted error", privileged.Level)
+	}
+
+	hostNetwork, ok := results["HostNetwork"]
+	if !ok || len(hostNetwork.Suppressions) != 1 || hostNetwork.Suppressions[0].Kind != "inSource" {
+		t.Errorf("Got HostNetwork result %+v wanted an inSource suppression", hostNetwork)
+	}
+}
+
+func TestSARIFWriter_Invocation(t *testing.T) {
+	reports := scan(t, "pod.yaml", podManifest)
+	invalid := reports[0]
+	invalid.Valid = false
+	invalid.Message = "Failed to validate against schema"
+
+	var tests = []struct {
+		name          string
+		reports       []ruler.Report
+		successful    bool
+		notifications int
+	}{
+		{name: "scanned", reports: reports, successful: true},
+		{name: "schema invalid", reports: []ruler.Report{invalid}, successful: true, notifications: 1},
+		{
+			name:          "parse error",
+			reports:       append(reports, ruler.NewErrorReport("broken.yaml", 0, 1, ruler.ErrorReasonParse, os.ErrInvalid)),
+			successful:    false,
+			notifications: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var log sarifLog
+			if err := json.Unmarshal(writeSARIF(t, tt.reports), &log); err != nil {
+				t.Fatal(err.Error())
+			}
+			invocation := log.Runs[0].Invocations[0]
+			if invocation.ExecutionSuccessful != tt.successful {
+				t.Errorf("Got executionSuccessful %v wanted %v", invocation.ExecutionSuccessful, tt.successful)
+			}
+			if len(invocation.ToolExecutionNotifications) != tt.notifications {
+				t.Errorf("Got %d notifications wanted %d", len(invocation.ToolExecutionNotifications), tt.notifications)
+			}
+		})
+	}
+}
+
+func TestSARIFLevel(t *testing.T) {
+	var tests = []struct {
+		severity ruler.Severity
+		level    string
+	}{
+		{ruler.SeverityCritical, "error"},
+		{ruler.SeverityHigh, "error"},
+		{ruler.SeverityMedium, "warning"},
+		{ruler.SeverityLow, "note"},
+		{ruler.SeverityInfo, "note"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.severity), func(t *testing.T) {
+			if got := sarifLevel(tt.severity); got != tt.level {
+				t.Errorf("Got level %s wanted %s", got, tt.level)
+			}
+		})
+	}
+}
+
+func TestSARIFWriter_Fingerprints(t *testing.T) {
+	fingerprints := func(manifest string) map[string]string {
+		var log sarifLog
+		if err := json.Unmarshal(writeSARIF(t, scan(t, "pod.yaml", manifest)), &log); err != nil {
+			t.Fatal(err.Error())
+		}
+		out := make(map[string]string)
+		for _, r := range log.Runs[0].Results {
+			out[r.RuleID] = r.PartialFingerprints[sarifFingerprint]
+		}
+		return out
+	}
+
+	first, second := fingerprints(podManifest), fingerprints(podManifest)
+	if len(first) == 0 {
+		t.Fatalf("Got no fingerprints")
+	}
+	for id, fingerprint := range first {
+		if second[id] != fingerprint {
+			t.Errorf("Got fingerprint %s for %s wanted %s", second[id], id, fingerprint)
+		}
+	}
+
+	// lines moving keep the fingerprints, another object changes them
+	moved := fingerprints("# the web server\n" + podManifest)
+	renamed := fingerprints(strings.Replace(podManifest, "name: web", "name: api", 1))
+	for id, fingerprint := range first {
+		if moved[id] != fingerprint {
+			t.Errorf("Got fingerprint %s for %s once moved wanted %s", moved[id], id, fingerprint)
+		}
+		if renamed[id] == fingerprint {
+			t.Errorf("Got the fingerprint %s for %s of another object", fingerprint, id)
+		}
+	}
+}
@@ -13,6 +13,7 @@ import (
 	"text/template"
 	"time"
 
+	"github.com/controlplaneio/kubesec/v2/pkg/pss"
 	"github.com/controlplaneio/kubesec/v2/pkg/ruler"
 	"github.com/pterm/pterm"
 )
@@ -24,20 +25,54 @@ var Now = time.Now
 
 type reports ruler.Reports
 
+// Options configure the formats which need more than the reports
+type Options struct {
+	// Template is the template of the template format, or the file holding it
+	Template string
+	// Baseline are the reports of a previous scan the markdown format
+	// compares the scores to
+	Baseline []ruler.Report
+	// MaxLength is the maximum length of the markdown format, unlimited if 0
+	MaxLength int
+	// Rules are described by the html format
+	Rules []ruler.Rule
+	// Sources are the contents of the scanned files by name, the html format
+	// shows the snippets of the findings
+	Sources map[string][]byte
+	// Policy decides which objects pass in the junit, markdown and html
+	// formats, as it decides the exit code of the scan. The default policy
+	// applies if nil.
+	Policy *ruler.ExitPolicy
+}
+
 // WriteReports writes the result to output, format as passed in argument
 func WriteReports(format string, output io.Writer, reports reports, outputTemplate string) error {
+	return WriteReportsWithOptions(format, output, reports, Options{Template: outputTemplate})
+}
+
+// WriteReportsWithOptions writes the result to output like WriteReports,
+// with the options of the formats which need more than the reports
+func WriteReportsWithOptions(format string, output io.Writer, reports reports, opts Options) error {
 	var writer Writer
 	switch format {
 	case "table":
 		writer = &TableWriter{Output: output}
 	case "json":
 		writer = &JSONWriter{Output: output}
+	case "sarif":
+		writer = &SARIFWriter{Output: output}
+	case "junit":
+		writer = &JUnitWriter{Output: output, Policy: opts.Policy}
+	case "markdown":
+		writer = &MarkdownWriter{Output: output, Baseline: opts.Baseline, MaxLength: opts.MaxLength, Policy: opts.Policy}
+	case "html":
+		writer = &HTMLWriter{Output: output, Rules: opts.Rules, Sources: opts.Sources, Policy: opts.Policy}
 	case "template":
 		var err error
-		if len(outputTemplate) == 0 {
+		if len(opts.Template) == 0 {
 			return errors.New("template is unset, please specify with --template")
 		}
-		if writer, err = NewTemplateWriter(output, outputTemplate); err != nil {
+		if writer, err = NewTemplateWriter(output, opts.Template); err != nil {
 			return err
 		}
 	default:
@@ -105,12 +140,7 @@ func NewTemplateWriter(output io.Writer, outputTemplate string) (*TemplateWriter
 	}
 
 	tmpl, err := template.New("output template").Funcs(template.FuncMap{
-		"endWithPeriod": func(input string) string {
-			if !strings.HasSuffix(input, ".") {
-				input += "."
-			}
-			return input
-		},
+		"endWithPeriod": endWithPeriod,
 		"toLower": func(input string) string {
 			return strings.ToLower(input)
 		},
@@ -120,6 +150,10 @@ func NewTemplateWriter(output io.Writer, outputTemplate string) (*TemplateWriter
 		"getCurrentTime": func() string {
 			return Now().UTC().Format(time.RFC3339Nano)
 		},
+		"toJSON": func(v interface{}) (string, error) {
+			out, err := json.Marshal(v)
+			return string(out), err
+		},
 		"joinSlices": func(slices ...[]ruler.RuleRef) []ruler.RuleRef {
 			var resultSlice []ruler.RuleRef
 			for _, slice := range slices {
@@ -169,7 +203,7 @@ func (tw TableWriter) Write(reports reports) error {
 		}
 
 		summaryData = append(summaryData, []string{
-			r.FileName,
+			reportFile(r),
 			r.Object,
 			isValid,
 			pterm.Cyan(strconv.Itoa(r.Score)),
@@ -194,42 +228,77 @@ func (tw TableWriter) Write(reports reports) error {
 	// Detailed Tables for Each Report
 	for _, r := range reports {
 		// Skip if there are no rules to display for this report
-		if len(r.Scoring.Critical) == 0 && len(r.Scoring.Advise) == 0 && len(r.Scoring.Passed) == 0 {
+		if len(r.Scoring.Critical) == 0 && len(r.Scoring.Advise) == 0 && len(r.Scoring.Passed) == 0 &&
+			len(r.Scoring.Suppressed) == 0 && r.PodSecurity == nil && len(r.SchemaErrors) == 0 && r.Error == nil {
 			continue
 		}
 
 		// Section header for the specific resource
 		pterm.DefaultSection.Printf("Details for %s", pterm.LightCyan(r.Object))
-		pterm.Printf("File: %s\n", pterm.Gray(r.FileName))
+		pterm.Printf("File: %s\n", pterm.Gray(reportFile(r)))
 		if r.Message != "" {
 			pterm.Info.Println(r.Message)
 		}
 
+		if r.Error != nil {
+			if r.Error.Location != nil {
+				pterm.Error.Printf("%s at %s\n", r.Error.Reason, r.Error.Location)
+			} else {
+				pterm.Error.Println(r.Error.Reason)
+			}
+			continue
+		}
+
+		if len(r.SchemaErrors) > 0 {
+			if err := writeSchemaErrors(r.SchemaErrors); err != nil {
+				return err
+			}
+			continue
+		}
+
 		var detailData [][]string
-		detailData = append(detailData, []string{"Severity", "Rule ID", "Selector", "Reason", "Points"})
+		detailData = append(detailData, []string{"Status", "Severity", "Rule ID", "Selector", "Reason", "Points"})
 
 		// Helper function to append rules to the table
-		appendRules := func(rules []ruler.RuleRef, severityText string) {
+		appendRules := func(rules []ruler.RuleRef, statusText string) {
 			selector := ""
 
 			for _, rule := range rules {
 				// split long selectors into multiple lines for table to render correctly
 				selector = strings.ReplaceAll(rule.Selector, " | ", " |\n")
 
+				reason := rule.Reason
+				for _, f := range rule.Findings {
+					finding := f.String()
+					if f.Location != nil {
+						finding += fmt.Sprintf(" (line %d, column %d)", f.Location.Line, f.Location.Column)
+					}
+					reason += "\n" + pterm.Gray(finding)
+				}
+				if s := rule.Suppression; s != nil {
+					if s.Expired {
+						reason += "\n" + pterm.LightRed(fmt.Sprintf("Exception expired on %s (owner: %s)", s.Expires, s.Owner))
+					} else {
+						reason += "\n" + pterm.Gray("Suppressed: "+s.Justification)
+					}
+				}
+
 				detailData = append(detailData, []string{
-					severityText,
+					statusText,
+					severityStyle(rule.Severity),
 					rule.ID,
 					selector,
-					rule.Reason,
+					reason,
 					fmt.Sprintf("%d", rule.Points),
 				})
 			}
 		}
 
-		// Append all rules grouped by severity
+		// Append all rules grouped by status
 		appendRules(r.Scoring.Critical, pterm.LightRed("🔴 Critical"))
 		appendRules(r.Scoring.Advise, pterm.LightYellow("🟡 Advise"))
 		appendRules(r.Scoring.Passed, pterm.LightGreen("🟢 Passed"))
+		appendRules(r.Scoring.Suppressed, pterm.Gray("⚪ Suppressed"))
 
 		// Render the detailed table
 		err = pterm.DefaultTable.
@@ -243,7 +312,112 @@ func (tw TableWriter) Write(reports reports) error {
 			return err
 		}
 		pterm.Println()
+
+		if r.PodSecurity != nil {
+			if err := writePodSecurity(r.PodSecurity); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// severityStyle colours a severity by its level
+func severityStyle(severity ruler.Severity) string {
+	text := string(severity)
+	switch severity {
+	case ruler.SeverityCritical:
+		return pterm.Red(text)
+	case ruler.SeverityHigh:
+		return pterm.LightRed(text)
+	case ruler.SeverityMedium:
+		return pterm.LightYellow(text)
+	case ruler.SeverityLow:
+		return pterm.LightBlue(text)
+	default:
+		return pterm.Gray(text)
+	}
+}
+
+// writeSchemaErrors renders the schema validation errors as a table
+func writeSchemaErrors(schemaErrors []ruler.SchemaError) error {
+	data := [][]string{{"Line", "Column", "Path", "Error"}}
+	for _, e := range schemaErrors {
+		line, column := "", ""
+		if e.Location != nil {
+			line, column = strconv.Itoa(e.Location.Line), strconv.Itoa(e.Location.Column)
+		}
+		data = append(data, []string{line, column, e.Path, e.Message})
+	}
+
+	err := pterm.DefaultTable.
+		WithHasHeader().
+		WithBoxed(true).
+		WithHeaderStyle(pterm.NewStyle(pterm.FgWhite, pterm.Bold)).
+		WithData(data).
+		Render()
+	if err != nil {
+		return err
+	}
+	pterm.Println()
+
+	return nil
+}
+
+// writePodSecurity renders the Pod Security Standards controls as a table
+func writePodSecurity(result *pss.Result) error {
+	if result.Allowed {
+		pterm.Success.Printf("Pod Security Standards %s: allowed\n", result.Level)
+	} else {
+		pterm.Error.Printf("Pod Security Standards %s: violated\n", result.Level)
+	}
+
+	data := [][]string{{"Status", "Control", "Level", "Violations"}}
+	for _, c := range result.Controls {
+		status := pterm.LightGreen("🟢 Passed")
+		if !c.Passed {
+			status = pterm.LightRed("🔴 Failed")
+		}
+
+		var violations []string
+		for _, v := range c.Violations {
+			violations = append(violations, fmt.Sprintf("%s: %s", v.Path, v.Value))
+		}
+
+		data = append(data, []string{status, c.Name, string(c.Level), strings.Join(violations, "\n")})
+	}
+
+	err := pterm.DefaultTable.
+		WithHasHeader().
+		WithBoxed(true).
+		WithRowSeparator("-").
+		WithHeaderStyle(pterm.NewStyle(pterm.FgWhite, pterm.Bold)).
+		WithData(data).
+		Render()
+	if err != nil {
+		return err
 	}
+	pterm.Println()
 
 	return nil
 }
+
+// reportFile returns the file name of a report with the index of its item
+// for the items of a list, e.g. deployments.json[3]
+func reportFile(r ruler.Report) string {
+	if r.ItemIndex == nil {
+		return r.FileName
+	}
+	return fmt.Sprintf("%s[%d]", r.FileName, *r.ItemIndex)
+}
+
+// decideReports returns the decision of the policy for each report, so that
+// the formats show the objects passing as the exit code of the scan does.
+// The default policy applies if nil.
+func decideReports(policy *ruler.ExitPolicy, reports []ruler.Report) []ruler.ObjectDecision {
+	if policy == nil {
+		policy = &ruler.ExitPolicy{}
+	}
+	return policy.Decide(reports, ruler.ExitCodes{}).Objects
+}
@@ -0,0 +1,89 @@
+package report
+
+import (
+	"testing"
+
+	"github.com/controlplaneio/kubesec/v2/pkg/ruler"
+	"go.uber.org/zap"
+)
+
+const podManifest = `apiVersion: v1
+kind: Pod
+metadata:
+  name: web
+  namespace: prod
+  annotations:
+    kubesec.io/ignore: HostNetwork
+    kubesec.io/ignore-justification: the web server binds the node port
+spec:
+  hostNetwork: true
+  hostPID: true
+  containers:
+    - name: app
+      image: nginx
+      securityContext:
+        privileged: true
+`
+
+const workloadsManifest = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: api
+spec:
+  selector:
+    matchLabels:
+      app: api
+  template:
+    metadata:
+      labels:
+        app: api
+    spec:
+      containers:
+        - name: api
+          image: api
+          securityContext:
+            runAsNonRoot: true
+            readOnlyRootFilesystem: true
+---
+apiVersion: apps/v1
+kind: DaemonSet
+metadata:
+  name: agent
+  namespace: kube-system
+spec:
+  selector:
+    matchLabels:
+      app: agent
+  template:
+    metadata:
+      labels:
+        app: agent
+    spec:
+      hostPID: true
+      containers:
+        - name: agent
+          image: agent
+`
+
+// scan returns the reports of the files, by name, without validating their
+// schemas
+func scan(t *testing.T, files ...string) []ruler.Report {
+	t.Helper()
+
+	ruleset, err := ruler.NewRuleset(zap.NewNop().Sugar())
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	config := ruler.NewDefaultSchemaConfig()
+	config.DisableValidation = true
+
+	var reports []ruler.Report
+	for i := 0; i+1 < len(files); i += 2 {
+		r, err := ruleset.Run(files[i], []byte(files[i+1]), config)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		reports = append(reports, r...)
+	}
+	return reports
+}
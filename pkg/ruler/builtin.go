@@ -0,0 +1,333 @@
+package ruler
+
+import (
+	"slices"
+
+	"github.com/controlplaneio/kubesec/v2/pkg/rules"
+)
+
+func init() {
+	MustRegisterRule(builtinRules...)
+}
+
+// podSpecKinds are the built-in kinds holding a pod spec, each rule holds
+// its own copy
+var podSpecKinds = []string{
+	"Pod",
+	"PodTemplate",
+	"Deployment",
+	"StatefulSet",
+	"DaemonSet",
+	"ReplicaSet",
+	"ReplicationController",
+	"Job",
+	"CronJob",
+}
+
+// builtinRules are the rules shipped with kubesec, registered at init
+var builtinRules = []Rule{
+	{
+		Predicate:  rules.HostNetwork,
+		ID:         "HostNetwork",
+		Selector:   ".spec .hostNetwork == true",
+		Reason:     "Sharing the host's network namespace permits processes in the pod to communicate with processes bound to the host's loopback adapter",
+		Kinds:      slices.Clone(podSpecKinds),
+		Points:     -9,
+		Severity:   SeverityHigh,
+		Compliance: []Compliance{cis("5.2.5"), nsa("pod-security"), mitre("T1611")},
+	},
+	{
+		Predicate:  rules.HostPID,
+		ID:         "HostPID",
+		Selector:   ".spec .hostPID == true",
+		Reason:     "Sharing the host's PID namespace allows visibility of processes on the host, potentially leaking information such as environment variables and configuration",
+		Kinds:      slices.Clone(podSpecKinds),
+		Points:     -9,
+		Severity:   SeverityHigh,
+		Compliance: []Compliance{cis("5.2.3"), nsa("pod-security"), mitre("T1611")},
+	},
+	{
+		Predicate:  rules.HostIPC,
+		ID:         "HostIPC",
+		Selector:   ".spec .hostIPC == true",
+		Reason:     "Sharing the host's IPC namespace allows container processes to communicate with processes on the host",
+		Kinds:      slices.Clone(podSpecKinds),
+		Points:     -9,
+		Severity:   SeverityHigh,
+		Compliance: []Compliance{cis("5.2.4"), nsa("pod-security"), mitre("T1611")},
+	},
+	{
+		Predicate:  rules.ReadOnlyRootFilesystem,
+		ID:         "ReadOnlyRootFilesystem",
+		Selector:   "containers[] .securityContext .readOnlyRootFilesystem == true",
+		Reason:     "An immutable root filesystem can prevent malicious binaries being added to PATH and increase attack cost",
+		Kinds:      slices.Clone(podSpecKinds),
+		Points:     1,
+		Severity:   SeverityMedium,
+		Advise:     3,
+		Compliance: []Compliance{cis("5.7.3"), nsa("immutable-filesystems")},
+	},
+	{
+		Predicate:  rules.RunAsNonRoot,
+		ID:         "RunAsNonRoot",
+		Selector:   ".spec, .spec.containers[] | .securityContext .runAsNonRoot == true",
+		Reason:     "Force the running image to run as a non-root user to ensure least privilege",
+		Kinds:      slices.Clone(podSpecKinds),
+		Points:     1,
+		Severity:   SeverityMedium,
+		Advise:     10,
+		Compliance: []Compliance{cis("5.2.7"), nsa("non-root-containers"), mitre("T1611")},
+	},
+	{
+		Predicate:  rules.RunAsUser,
+		ID:         "RunAsUser",
+		Selector:   ".spec, .spec.containers[] | .securityContext .runAsUser -gt 10000",
+		Reason:     "Run as a high-UID user to avoid conflicts with the host's users",
+		Kinds:      slices.Clone(podSpecKinds),
+		Points:     1,
+		Severity:   SeverityLow,
+		Advise:     4,
+		Compliance: []Compliance{cis("5.2.7"), nsa("non-root-containers")},
+	},
+	{
+		Predicate:  rules.RunAsGroup,
+		ID:         "RunAsGroup",
+		Selector:   ".spec, .spec.containers[] | .securityContext .runAsGroup -gt 10000",
+		Reason:     "Run as a high-UID group to avoid conflicts with the host's groups",
+		Kinds:      slices.Clone(podSpecKinds),
+		Points:     1,
+		Severity:   SeverityLow,
+		Advise:     4,
+		Compliance: []Compliance{cis("5.7.3"), nsa("non-root-containers")},
+	},
+	{
+		Predicate:  rules.Privileged,
+		ID:         "Privileged",
+		Selector:   "containers[] .securityContext .privileged == true",
+		Reason:     "Privileged containers can allow almost completely unrestricted host access",
+		Kinds:      slices.Clone(podSpecKinds),
+		Points:     -30,
+		Severity:   SeverityCritical,
+		Compliance: []Compliance{cis("5.2.2"), nsa("pod-security"), mitre("T1611")},
+	},
+	{
+		Predicate:  rules.CapSysAdmin,
+		ID:         "CapSysAdmin",
+		Selector:   "containers[] .securityContext .capabilities .add == SYS_ADMIN",
+		Reason:     "CAP_SYS_ADMIN is the most privileged capability and should always be avoided",
+		Kinds:      slices.Clone(podSpecKinds),
+		Points:     -30,
+		Severity:   SeverityCritical,
+		Compliance: []Compliance{cis("5.2.9"), nsa("pod-security"), mitre("T1611")},
+	},
+	{
+		Predicate:  rules.CapDropAny,
+		ID:         "CapDropAny",
+		Selector:   "containers[] .securityContext .capabilities .drop",
+		Reason:     "Reducing kernel capabilities available to a container limits its attack surface",
+		Kinds:      slices.Clone(podSpecKinds),
+		Points:     1,
+		Severity:   SeverityLow,
+		Compliance: []Compliance{cis("5.2.10"), nsa("pod-security")},
+	},
+	{
+		Predicate:  rules.CapDropAll,
+		ID:         "CapDropAll",
+		Selector:   "containers[] .securityContext .capabilities .drop | index(\"ALL\")",
+		Reason:     "Drop all capabilities and add only those required to reduce syscall attack surface",
+		Kinds:      slices.Clone(podSpecKinds),
+		Points:     1,
+		Severity:   SeverityMedium,
+		Compliance: []Compliance{cis("5.2.8"), cis("5.2.10"), nsa("pod-security")},
+	},
+	{
+		Predicate:  rules.DockerSock,
+		ID:         "DockerSock",
+		Selector:   "volumes[] .hostPath .path == /var/run/docker.sock",
+		Reason:     "Mounting the docker.socket leaks information about other containers and can allow container breakout",
+		Kinds:      slices.Clone(podSpecKinds),
+		Points:     -9,
+		Severity:   SeverityCritical,
+		Compliance: []Compliance{cis("5.2.12"), nsa("pod-security"), mitre("T1610"), mitre("T1611")},
+	},
+	{
+		Predicate:  rules.ProcMount,
+		ID:         "ProcMount",
+		Selector:   "volumes[] .hostPath .path == /proc",
+		Reason:     "Mounting the proc directory from the host system into a container gives access to information about other containers running on the same host and can allow container breakout",
+		Kinds:      slices.Clone(podSpecKinds),
+		Points:     -9,
+		Severity:   SeverityHigh,
+		Compliance: []Compliance{cis("5.7.3"), nsa("pod-security"), mitre("T1611")},
+	},
+	{
+		Predicate:  rules.RequestsCPU,
+		ID:         "RequestsCPU",
+		Selector:   "containers[] .resources .requests .cpu",
+		Reason:     "Enforcing CPU requests aids a fair balancing of resources across the cluster",
+		Kinds:      slices.Clone(podSpecKinds),
+		Points:     1,
+		Severity:   SeverityLow,
+		Compliance: []Compliance{nsa("resource-policies"), mitre("T1499")},
+	},
+	{
+		Predicate:  rules.LimitsCPU,
+		ID:         "LimitsCPU",
+		Selector:   "containers[] .resources .limits .cpu",
+		Reason:     "Enforcing CPU limits prevents DOS via resource exhaustion",
+		Kinds:      slices.Clone(podSpecKinds),
+		Points:     1,
+		Severity:   SeverityLow,
+		Compliance: []Compliance{nsa("resource-policies"), mitre("T1496"), mitre("T1499")},
+	},
+	{
+		Predicate:  rules.RequestsMemory,
+		ID:         "RequestsMemory",
+		Selector:   "containers[] .resources .requests .memory",
+		Reason:     "Enforcing memory requests aids a fair balancing of resources across the cluster",
+		Kinds:      slices.Clone(podSpecKinds),
+		Points:     1,
+		Severity:   SeverityLow,
+		Compliance: []Compliance{nsa("resource-policies"), mitre("T1499")},
+	},
+	{
+		Predicate:  rules.LimitsMemory,
+		ID:         "LimitsMemory",
+		Selector:   "containers[] .resources .limits .memory",
+		Reason:     "Enforcing memory limits prevents DOS via resource exhaustion",
+		Kinds:      slices.Clone(podSpecKinds),
+		Points:     1,
+		Severity:   SeverityLow,
+		Compliance: []Compliance{nsa("resource-policies"), mitre("T1499")},
+	},
+	{
+		Predicate:  rules.ServiceAccountName,
+		ID:         "ServiceAccountName",
+		Selector:   ".spec .serviceAccountName",
+		Reason:     "Service accounts restrict Kubernetes API access and should be configured with least privilege",
+		Kinds:      slices.Clone(podSpecKinds),
+		Points:     3,
+		Severity:   SeverityLow,
+		Compliance: []Compliance{cis("5.1.5"), nsa("service-account-tokens"), mitre("T1528")},
+	},
+	{
+		Predicate:  rules.HostAliases,
+		ID:         "HostAliases",
+		Selector:   ".spec .hostAliases",
+		Reason:     "Managing /etc/hosts aliases can prevent the container from modifying the file after a pod's containers have already been started. DNS should be managed by the orchestrator",
+		Kinds:      slices.Clone(podSpecKinds),
+		Points:     -3,
+		Severity:   SeverityLow,
+		Compliance: []Compliance{nsa("pod-security"), mitre("T1557")},
+	},
+	{
+		Predicate:  rules.SeccompAny,
+		ID:         "SeccompAny",
+		Selector:   ".spec .securityContext .seccompProfile .type | .spec .containers[] .securityContext .seccompProfile .type | .spec .initContainers[] .securityContext .seccompProfile .type | .spec .ephemeralContainers[] .securityContext .seccompProfile .type",
+		Reason:     "Seccomp profiles set minimum privilege and secure against unknown threats",
+		Kinds:      slices.Clone(podSpecKinds),
+		Points:     1,
+		Severity:   SeverityMedium,
+		Compliance: []Compliance{cis("5.7.2"), nsa("pod-security"), mitre("T1611")},
+	},
+	{
+		Predicate:  rules.SeccompUnconfined,
+		ID:         "SeccompUnconfined",
+		Selector:   ".spec .securityContext .seccompProfile .type | .spec .containers[] .securityContext .seccompProfile .type | .spec .initContainers[] .securityContext .seccompProfile .type | .spec .ephemeralContainers[] .securityContext .seccompProfile .type",
+		Reason:     "Unconfined Seccomp profiles have full system call access",
+		Kinds:      slices.Clone(podSpecKinds),
+		Points:     -1,
+		Severity:   SeverityMedium,
+		Compliance: []Compliance{cis("5.7.2"), nsa("pod-security"), mitre("T1611")},
+	},
+	{
+		Predicate:  rules.ApparmorAny,
+		ID:         "ApparmorAny",
+		Selector:   ".spec .securityContext .appArmorProfile .type | .spec .containers[] .securityContext .appArmorProfile .type | .spec .initContainers[] .securityContext .appArmorProfile .type | .spec .ephemeralContainers[] .securityContext .appArmorProfile .type",
+		Reason:     "Well defined AppArmor policies may provide greater protection from unknown threats.",
+		Kinds:      slices.Clone(podSpecKinds),
+		Points:     3,
+		Severity:   SeverityLow,
+		Compliance: []Compliance{cis("5.7.3"), nsa("pod-security"), mitre("T1611")},
+	},
+	{
+		Predicate:  rules.ApparmorUnconfined,
+		ID:         "ApparmorUnconfined",
+		Selector:   ".spec .securityContext .appArmorProfile .type | .spec .containers[] .securityContext .appArmorProfile .type | .spec .initContainers[] .securityContext .appArmorProfile .type | .spec .ephemeralContainers[] .securityContext .appArmorProfile .type",
+		Reason:     "Unconfined AppArmor profiles disable AppArmor enforcement on the workloads",
+		Kinds:      slices.Clone(podSpecKinds),
+		Points:     -1,
+		Severity:   SeverityMedium,
+		Compliance: []Compliance{cis("5.7.3"), nsa("pod-security"), mitre("T1611")},
+	},
+	{
+		Predicate:  rules.VolumeClaimAccessModeReadWriteOnce,
+		ID:         "VolumeClaimAccessModeReadWriteOnce",
+		Selector:   ".spec .volumeClaimTemplates[] .spec .accessModes | index(\"ReadWriteOnce\")",
+		Reason:     "Setting the access mode of ReadWriteOnce on volumeClaimTemplates (if any exist) allows only one node to mount the persistentVolume",
+		Kinds:      []string{"StatefulSet"},
+		Points:     1,
+		Severity:   SeverityInfo,
+		Compliance: []Compliance{nsa("resource-policies"), mitre("T1565")},
+	},
+	{
+		Predicate:  rules.VolumeClaimRequestsStorage,
+		ID:         "VolumeClaimRequestsStorage",
+		Selector:   ".spec .volumeClaimTemplates[] .spec .resources .requests .storage",
+		Reason:     "Setting a storage request on volumeClaimTemplates (if any exist) allows for the StatefulSet's PVCs to be bound to appropriately sized PVs.",
+		Kinds:      []string{"StatefulSet"},
+		Points:     1,
+		Severity:   SeverityInfo,
+		Compliance: []Compliance{nsa("resource-policies"), mitre("T1499")},
+	},
+	{
+		Predicate:  rules.AllowPrivilegeEscalation,
+		ID:         "AllowPrivilegeEscalation",
+		Selector:   "containers[] .securityContext .allowPrivilegeEscalation == true",
+		Reason:     "Ensure a non-root process can not gain more privileges",
+		Kinds:      slices.Clone(podSpecKinds),
+		Points:     -7,
+		Severity:   SeverityHigh,
+		Compliance: []Compliance{cis("5.2.6"), nsa("pod-security"), mitre("T1548.001")},
+	},
+	{
+		Predicate:  rules.AutomountServiceAccountToken,
+		ID:         "AutomountServiceAccountToken",
+		Selector:   ".spec .automountServiceAccountToken == false",
+		Reason:     "Disabling the automounting of Service Account Token reduces the attack surface of the API server",
+		Kinds:      slices.Clone(podSpecKinds),
+		Points:     1,
+		Severity:   SeverityLow,
+		Compliance: []Compliance{cis("5.1.6"), nsa("service-account-tokens"), mitre("T1528")},
+	},
+	{
+		Predicate:  rules.HostUsers,
+		ID:         "HostUsers",
+		Selector:   ".spec .hostUsers == false",
+		Reason:     "A user namespace for a Pod is enabled by setting the hostUsers field of Pod .spec, which can prevent various attacks",
+		Kinds:      slices.Clone(podSpecKinds),
+		Points:     1,
+		Severity:   SeverityMedium,
+		Compliance: []Compliance{nsa("non-root-containers"), mitre("T1611")},
+	},
+	{
+		Predicate:  rules.SecretsAsEnvironmentVariables,
+		ID:         "SecretsAsEnvironmentVariables",
+		Selector:   ".spec .containers[] .env[] .valueFrom .secretKeyRef | .spec .initContainers[] .env[] .valueFrom .secretKeyRef | .spec .ephemeralContainers[] .env[] .valueFrom .secretKeyRef | .spec .containers[] .envFrom[] .secretRef | .spec .initContainers[] .envFrom[] .secretRef | .spec .ephemeralContainers[] .envFrom[] .secretRef",
+		Reason:     "Secrets passed as environment variables can be easily exposed through application logs, crash dumps, and system process inspection",
+		Kinds:      slices.Clone(podSpecKinds),
+		Points:     -5,
+		Severity:   SeverityMedium,
+		Compliance: []Compliance{cis("5.4.1"), nsa("secrets"), mitre("T1552")},
+	},
+	{
+		Predicate:  rules.BindingsToSystemAnonymous,
+		ID:         "BindingsToSystemAnonymous",
+		Selector:   ".subjects[] .name == \"system:anonymous\"",
+		Reason:     "Binding a Role or a ClusterRole to user system:anonymous gives any unauthenticated user the permissions granted by that role",
+		Kinds:      []string{"RoleBinding", "ClusterRoleBinding"},
+		Points:     -30,
+		Severity:   SeverityCritical,
+		Compliance: []Compliance{nsa("authn-authz"), mitre("T1078.001")},
+	},
+}
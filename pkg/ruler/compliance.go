@@ -0,0 +1,218 @@
+package ruler
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Compliance frameworks the built-in rules are mapped to
+const (
+	// FrameworkCIS is the CIS Kubernetes Benchmark
+	FrameworkCIS = "cis"
+	// FrameworkNSA is the NSA/CISA Kubernetes Hardening Guidance
+	FrameworkNSA = "nsa"
+	// FrameworkMITRE is MITRE ATT&CK for Containers
+	FrameworkMITRE = "mitre"
+)
+
+// Compliance references a control of a compliance framework
+type Compliance struct {
+	Framework string `json:"framework" yaml:"framework"`
+	ID        string `json:"id" yaml:"id"`
+	Title     string `json:"title,omitempty" yaml:"title,omitempty"`
+}
+
+func (c Compliance) String() string {
+	return c.Framework + ":" + c.ID
+}
+
+// complianceCatalog lists the controls of the known frameworks, in the order
+// of their documents
+var complianceCatalog = map[string][]Compliance{
+	FrameworkCIS: {
+		{FrameworkCIS, "5.1.5", "Ensure that default service accounts are not actively used"},
+		{FrameworkCIS, "5.1.6", "Ensure that Service Account Tokens are only mounted where necessary"},
+		{FrameworkCIS, "5.2.2", "Minimize the admission of privileged containers"},
+		{FrameworkCIS, "5.2.3", "Minimize the admission of containers wishing to share the host process ID namespace"},
+		{FrameworkCIS, "5.2.4", "Minimize the admission of containers wishing to share the host IPC namespace"},
+		{FrameworkCIS, "5.2.5", "Minimize the admission of containers wishing to share the host network namespace"},
+		{FrameworkCIS, "5.2.6", "Minimize the admission of containers with allowPrivilegeEscalation"},
+		{FrameworkCIS, "5.2.7", "Minimize the admission of root containers"},
+		{FrameworkCIS, "5.2.8", "Minimize the admission of containers with the NET_RAW capability"},
+		{FrameworkCIS, "5.2.9", "Minimize the admission of containers with added capabilities"},
+		{FrameworkCIS, "5.2.10", "Minimize the admission of containers with capabilities assigned"},
+		{FrameworkCIS, "5.2.12", "Minimize the admission of HostPath volumes"},
+		{FrameworkCIS, "5.4.1", "Prefer using secrets as files over secrets as environment variables"},
+		{FrameworkCIS, "5.7.2", "Ensure that the seccomp profile is set to docker/default in your pod definitions"},
+		{FrameworkCIS, "5.7.3", "Apply Security Context to Your Pods and Containers"},
+	},
+	FrameworkNSA: {
+		{FrameworkNSA, "non-root-containers", "Non-root containers and rootless container engines"},
+		{FrameworkNSA, "immutable-filesystems", "Immutable container file systems"},
+		{FrameworkNSA, "pod-security", "Pod security enforcement"},
+		{FrameworkNSA, "service-account-tokens", "Protecting Pod service account tokens"},
+		{FrameworkNSA, "resource-policies", "Resource policies"},
+		{FrameworkNSA, "secrets", "Secrets"},
+		{FrameworkNSA, "authn-authz", "Authentication and authorization"},
+	},
+	FrameworkMITRE: {
+		{FrameworkMITRE, "T1078.001", "Valid Accounts: Default Accounts"},
+		{FrameworkMITRE, "T1496", "Resource Hijacking"},
+		{FrameworkMITRE, "T1499", "Endpoint Denial of Service"},
+		{FrameworkMITRE, "T1528", "Steal Application Access Token"},
+		{FrameworkMITRE, "T1548.001", "Abuse Elevation Control Mechanism: Setuid and Setgid"},
+		{FrameworkMITRE, "T1552", "Unsecured Credentials"},
+		{FrameworkMITRE, "T1557", "Adversary-in-the-Middle"},
+		{FrameworkMITRE, "T1565", "Data Manipulation"},
+		{FrameworkMITRE, "T1610", "Deploy Container"},
+		{FrameworkMITRE, "T1611", "Escape to Host"},
+	},
+}
+
+// catalogControl returns the control of a known framework
+func catalogControl(framework, id string) (Compliance, bool) {
+	for _, c := range complianceCatalog[framework] {
+		if c.ID == id {
+			return c, true
+		}
+	}
+	return Compliance{}, false
+}
+
+// mustControl returns the control of a known framework, for the built-in rules
+func mustControl(framework, id string) Compliance {
+	c, ok := catalogControl(framework, id)
+	if !ok {
+		panic(fmt.Sprintf("unknown %s control %s", framework, id))
+	}
+	return c
+}
+
+func cis(id string) Compliance   { return mustControl(FrameworkCIS, id) }
+func nsa(id string) Compliance   { return mustControl(FrameworkNSA, id) }
+func mitre(id string) Compliance { return mustControl(FrameworkMITRE, id) }
+
+// Frameworks returns the names of the known compliance frameworks, sorted
+func Frameworks() []string {
+	frameworks := make([]string, 0, len(complianceCatalog))
+	for f := range complianceCatalog {
+		frameworks = append(frameworks, f)
+	}
+	sort.Strings(frameworks)
+	return frameworks
+}
+
+// ValidateFramework checks the framework is a known compliance framework
+func ValidateFramework(framework string) error {
+	if _, ok := complianceCatalog[framework]; !ok {
+		return fmt.Errorf("unknown compliance framework %q, expected one of %s", framework, strings.Join(Frameworks(), ", "))
+	}
+	return nil
+}
+
+// HasFramework reports whether the rule references a control of the framework
+func (r *Rule) HasFramework(framework string) bool {
+	for _, c := range r.Compliance {
+		if c.Framework == framework {
+			return true
+		}
+	}
+	return false
+}
+
+// validateCompliance checks the references of a custom rule and fills in
+// the titles of the controls of known frameworks
+func validateCompliance(refs []Compliance) error {
+	for i, c := range refs {
+		if c.Framework == "" || c.ID == "" {
+			return fmt.Errorf("compliance framework and id are required")
+		}
+		if known, ok := catalogControl(c.Framework, c.ID); ok && c.Title == "" {
+			refs[i].Title = known.Title
+		}
+	}
+	return nil
+}
+
+// ComplianceReport is the per-control view of a report for a framework
+type ComplianceReport struct {
+	Object    string              `json:"object"`
+	Valid     bool                `json:"valid"`
+	FileName  string              `json:"fileName"`
+	Message   string              `json:"message,omitempty"`
+	Framework string              `json:"framework"`
+	Passed    bool                `json:"passed"`
+	Controls  []ComplianceControl `json:"controls"`
+}
+
+// ComplianceControl is the status of a control, it fails when any of the
+// rules mapped to it failed and was not suppressed
+type ComplianceControl struct {
+	ID          string   `json:"id"`
+	Title       string   `json:"title,omitempty"`
+	Passed      bool     `json:"passed"`
+	Rules       []string `json:"rules"`
+	FailedRules []string `json:"failedRules,omitempty"`
+}
+
+// NewComplianceReport summarises the rules evaluated for a report by the
+// controls of the framework they map to
+func NewComplianceReport(report Report, framework string) ComplianceReport {
+	cr := ComplianceReport{
+		Object:    report.Object,
+		Valid:     report.Valid,
+		FileName:  report.FileName,
+		Framework: framework,
+		Passed:    report.Valid,
+		Controls:  make([]ComplianceControl, 0),
+	}
+	if !report.Valid {
+		cr.Message = report.Message
+	}
+
+	controls := make(map[string]*ComplianceControl)
+	for _, ref := range report.Rules {
+		for _, c := range ref.Compliance {
+			if c.Framework != framework {
+				continue
+			}
+			control, ok := controls[c.ID]
+			if !ok {
+				control = &ComplianceControl{ID: c.ID, Title: c.Title, Passed: true}
+				controls[c.ID] = control
+			}
+			control.Rules = append(control.Rules, ref.ID)
+			if ref.failed() && (ref.Suppression == nil || ref.Suppression.Expired) {
+				control.Passed = false
+				control.FailedRules = append(control.FailedRules, ref.ID)
+				cr.Passed = false
+			}
+		}
+	}
+
+	// controls of known frameworks are listed in catalog order, others by ID
+	order := make(map[string]int)
+	for i, c := range complianceCatalog[framework] {
+		order[c.ID] = i + 1
+	}
+	for _, control := range controls {
+		sort.Strings(control.Rules)
+		sort.Strings(control.FailedRules)
+		cr.Controls = append(cr.Controls, *control)
+	}
+	sort.Slice(cr.Controls, func(i, j int) bool {
+		oi, oj := order[cr.Controls[i].ID], order[cr.Controls[j].ID]
+		if oi != oj {
+			return oi != 0 && (oj == 0 || oi < oj)
+		}
+		return cr.Controls[i].ID < cr.Controls[j].ID
+	})
+
+	return cr
+}
+
+// failed reports whether a negative rule matched or a positive rule did not
+func (r RuleRef) failed() bool {
+	return (r.Containers > 0) == (r.Points < 0)
+}
@@ -0,0 +1,116 @@
+package ruler
+
+import (
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestBuiltinRules_Compliance(t *testing.T) {
+	for _, rule := range builtinRules {
+		if len(rule.Compliance) == 0 {
+			t.Errorf("Got no compliance references for rule %s", rule.ID)
+		}
+		for _, c := range rule.Compliance {
+			if c.Title == "" {
+				t.Errorf("Got no title for %s of rule %s", c, rule.ID)
+			}
+		}
+	}
+}
+
+func TestValidateFramework(t *testing.T) {
+	for _, framework := range []string{FrameworkCIS, FrameworkNSA, FrameworkMITRE} {
+		if err := ValidateFramework(framework); err != nil {
+			t.Errorf("Got error %v for framework %s", err, framework)
+		}
+	}
+	for _, framework := range []string{"cis2", "NSA", ""} {
+		if err := ValidateFramework(framework); err == nil {
+			t.Errorf("Got no error for framework %q wanted error", framework)
+		}
+	}
+}
+
+func TestNewComplianceReport(t *testing.T) {
+	data := `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+  annotations:
+    kubesec.io/ignore: HostPID
+    kubesec.io/ignore-justification: debugging tools
+spec:
+  template:
+    spec:
+      hostNetwork: true
+      hostPID: true
+      containers:
+        - name: c1
+          securityContext:
+            runAsNonRoot: true
+`
+	schemaConfig := NewDefaultSchemaConfig()
+	schemaConfig.DisableValidation = true
+
+	ruleset, err := NewRuleset(zap.NewNop().Sugar(), "HostNetwork", "HostPID", "HostIPC", "RunAsNonRoot", "RunAsUser")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	reports, err := ruleset.Run("kube.yaml", []byte(data), schemaConfig)
+	if err != nil || len(reports) == 0 {
+		t.Fatal(err)
+	}
+
+	cr := NewComplianceReport(reports[0], FrameworkCIS)
+	if cr.Passed {
+		t.Errorf("Got passed, wanted failed")
+	}
+
+	expected := []struct {
+		id     string
+		passed bool
+		rules  []string
+	}{
+		{"5.2.3", true, []string{"HostPID"}},
+		{"5.2.4", true, []string{"HostIPC"}},
+		{"5.2.5", false, []string{"HostNetwork"}},
+		{"5.2.7", false, []string{"RunAsNonRoot", "RunAsUser"}},
+	}
+	if len(cr.Controls) != len(expected) {
+		t.Fatalf("Got %v controls wanted %v", cr.Controls, expected)
+	}
+	for i, e := range expected {
+		c := cr.Controls[i]
+		if c.ID != e.id || c.Passed != e.passed || !equalIDs(c.Rules, e.rules) {
+			t.Errorf("Got control %v wanted %v", c, e)
+		}
+	}
+	if failed := cr.Controls[3].FailedRules; !equalIDs(failed, []string{"RunAsUser"}) {
+		t.Errorf("Got failed rules %v wanted [RunAsUser]", failed)
+	}
+}
+
+func TestLoadRulesFiles_Compliance(t *testing.T) {
+	content := `
+rules:
+  - id: NoLatestTag
+    reason: Images should be pinned
+    kinds: [Pod]
+    points: -1
+    cel: "allContainers(object).exists(c, c.image.endsWith(':latest'))"
+    compliance:
+      - framework: cis
+        id: "5.7.3"
+      - framework: internal
+        id: SEC-12
+`
+	loaded, err := LoadRulesFiles(writeRulesFile(t, t.TempDir(), "rules.yaml", content))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if !loaded[0].HasFramework("internal") || loaded[0].Compliance[0].Title == "" {
+		t.Errorf("Got compliance %v, wanted the cis title filled in and the internal framework", loaded[0].Compliance)
+	}
+}
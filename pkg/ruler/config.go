@@ -0,0 +1,60 @@
+package ruler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/controlplaneio/kubesec/v2/pkg/rules"
+	"github.com/ghodss/yaml"
+)
+
+// Config is the format of the kubesec configuration file
+type Config struct {
+	Profiles []Profile `json:"profiles,omitempty"`
+	// PodSpecs locate the pod specs of custom resources
+	PodSpecs []rules.PodSpecMapping `json:"podSpecs,omitempty"`
+	// ExitPolicy decides whether the objects of a scan pass
+	ExitPolicy *ExitPolicy `json:"exitPolicy,omitempty"`
+}
+
+// LoadConfig reads and validates a kubesec configuration file
+func LoadConfig(path string) (Config, error) {
+	var config Config
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return config, err
+	}
+
+	jsonData, err := yaml.YAMLToJSON(data)
+	if err != nil {
+		return config, fmt.Errorf("%s: %w", path, err)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(jsonData))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&config); err != nil {
+		return config, fmt.Errorf("%s: %w", path, err)
+	}
+
+	names := make(map[string]bool, len(config.Profiles))
+	for _, p := range config.Profiles {
+		if p.Name == "" {
+			return config, fmt.Errorf("%s: profile name is required", path)
+		}
+		if names[p.Name] {
+			return config, fmt.Errorf("%s: duplicate profile %s", path, p.Name)
+		}
+		names[p.Name] = true
+	}
+
+	for _, m := range config.PodSpecs {
+		if err := m.Validate(); err != nil {
+			return config, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+
+	return config, nil
+}
@@ -0,0 +1,142 @@
+package ruler
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+)
+
+// yamlDocument is a document of a YAML file, the line it starts at and its
+// byte offset in the file
+type yamlDocument struct {
+	data   []byte
+	line   int
+	offset int
+}
+
+// documentDecoder reads the documents of a YAML stream one at a time.
+//
+// Documents start at a "---" marker, which may be followed by a comment or by
+// the content of the document, and end at the next marker or at a "..." end
+// marker. Markers are only recognised at the start of a line, where YAML
+// forbids them inside content, so block scalars are never split. Lines end
+// with LF or CRLF.
+type documentDecoder struct {
+	r      *bufio.Reader
+	line   int
+	offset int
+	// next is the first line of the next document, read with the end of
+	// the previous one
+	next []byte
+	eof  bool
+}
+
+func newDocumentDecoder(r io.Reader) *documentDecoder {
+	return &documentDecoder{r: bufio.NewReader(r)}
+}
+
+// Decode returns the next document of the stream, with its markers,
+// directives and comments, or io.EOF at the end of the stream. Empty
+// documents followed by a start marker are skipped, others holding no
+// content are returned.
+func (d *documentDecoder) Decode() (yamlDocument, error) {
+	if d.eof && d.next == nil {
+		return yamlDocument{}, io.EOF
+	}
+
+	doc := yamlDocument{line: d.line + 1, offset: d.offset}
+	// prelude is set while the document holds no content, a start marker
+	// then belongs to the document instead of starting the next one
+	prelude, started := true, false
+	if d.next != nil {
+		doc.data = d.next
+		doc.line = d.line
+		doc.offset = d.offset - len(d.next)
+		prelude, started = isPrelude(d.next), true
+		d.next = nil
+	}
+
+	for !d.eof {
+		l, err := d.r.ReadBytes('\n')
+		if err == io.EOF {
+			d.eof = true
+			if len(l) == 0 {
+				break
+			}
+		} else if err != nil {
+			return doc, err
+		}
+		d.line++
+		d.offset += len(l)
+
+		switch {
+		case isMarker(l, "---") && !prelude:
+			d.next = l
+			return doc, nil
+		case isMarker(l, "---") && started:
+			// the empty document before the marker is dropped
+			doc = yamlDocument{data: l, line: d.line, offset: d.offset - len(l)}
+			prelude = isPrelude(l)
+			continue
+		case isMarker(l, "---"):
+			started = true
+		case isMarker(l, "..."):
+			doc.data = append(doc.data, l...)
+			return doc, nil
+		}
+
+		doc.data = append(doc.data, l...)
+		if prelude && !isPrelude(l) {
+			prelude = false
+		}
+	}
+
+	return doc, nil
+}
+
+// isMarker reports whether a line is a document marker, alone or followed by
+// a space, a tab or a line break
+func isMarker(line []byte, marker string) bool {
+	if !bytes.HasPrefix(line, []byte(marker)) {
+		return false
+	}
+	rest := line[len(marker):]
+	return len(rest) == 0 || rest[0] == ' ' || rest[0] == '\t' || rest[0] == '\r' || rest[0] == '\n'
+}
+
+// isPrelude reports whether a line holds no content of a document: a blank
+// line, a comment, a directive, or a start marker with no content
+func isPrelude(line []byte) bool {
+	l := bytes.TrimSpace(line)
+	if isMarker(l, "---") {
+		l = bytes.TrimSpace(l[3:])
+	}
+	return len(l) == 0 || l[0] == '#' || l[0] == '%'
+}
+
+// Document is a document of a YAML stream
+type Document struct {
+	// Data is the document with its markers, directives and comments
+	Data []byte
+	// Line is the line the document starts at, from 1
+	Line int
+	// Offset is the byte offset of the document in the stream
+	Offset int
+}
+
+// SplitDocuments splits a YAML stream into its documents the way scan reads
+// them. The bytes between the documents are the empty documents it skips.
+func SplitDocuments(data []byte) ([]Document, error) {
+	var docs []Document
+	dec := newDocumentDecoder(bytes.NewReader(data))
+	for {
+		d, err := dec.Decode()
+		if err == io.EOF {
+			return docs, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, Document{Data: d.data, Line: d.line, Offset: d.offset})
+	}
+}
@@ -0,0 +1,210 @@
+package ruler
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/controlplaneio/kubesec/v2/pkg/rules"
+	"go.uber.org/zap"
+)
+
+func TestDocumentDecoder(t *testing.T) {
+	type document struct {
+		data string
+		line int
+	}
+
+	tests := []struct {
+		name     string
+		data     string
+		expected []document
+	}{
+		{
+			name:     "single document",
+			data:     "kind: Pod\n",
+			expected: []document{{"kind: Pod\n", 1}},
+		},
+		{
+			name: "leading markers and comments",
+			data: "# manifests\n---\n---\nkind: Pod\n",
+			expected: []document{
+				{"---\nkind: Pod\n", 3},
+			},
+		},
+		{
+			name: "separators with comments",
+			data: "kind: Pod\n--- # app\nkind: Service\n---\t# db\nkind: Secret",
+			expected: []document{
+				{"kind: Pod\n", 1},
+				{"--- # app\nkind: Service\n", 2},
+				{"---\t# db\nkind: Secret", 4},
+			},
+		},
+		{
+			name: "separators followed by content",
+			data: "--- {kind: Pod}\n--- !!map\nkind: Service\n",
+			expected: []document{
+				{"--- {kind: Pod}\n", 1},
+				{"--- !!map\nkind: Service\n", 2},
+			},
+		},
+		{
+			name: "end markers and directives",
+			data: "%YAML 1.1\n---\nkind: Pod\n...\n---\nkind: Service\n",
+			expected: []document{
+				{"%YAML 1.1\n---\nkind: Pod\n...\n", 1},
+				{"---\nkind: Service\n", 5},
+			},
+		},
+		{
+			name: "markers inside block scalars",
+			data: "kind: ConfigMap\ndata:\n  config: |\n    ---\n    key: value\n----\n",
+			expected: []document{
+				{"kind: ConfigMap\ndata:\n  config: |\n    ---\n    key: value\n----\n", 1},
+			},
+		},
+		{
+			name: "CRLF",
+			data: "kind: Pod\r\n--- # app\r\nkind: Service\r\n",
+			expected: []document{
+				{"kind: Pod\r\n", 1},
+				{"--- # app\r\nkind: Service\r\n", 2},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []document
+			dec := newDocumentDecoder(strings.NewReader(tt.data))
+			for {
+				d, err := dec.Decode()
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					t.Fatal(err.Error())
+				}
+				if tt.data[d.offset:d.offset+len(d.data)] != string(d.data) {
+					t.Errorf("Got document %q at offset %d", d.data, d.offset)
+				}
+				got = append(got, document{string(d.data), d.line})
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Got documents %q wanted %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSplitDocuments(t *testing.T) {
+	data := "# manifests\n---\n---\nkind: Pod\n...\n--- # app\r\nkind: Service\r\n"
+	docs, err := SplitDocuments([]byte(data))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	expected := []Document{
+		{Data: []byte("---\nkind: Pod\n...\n"), Line: 3, Offset: 16},
+		{Data: []byte("--- # app\r\nkind: Service\r\n"), Line: 6, Offset: 34},
+	}
+	if !reflect.DeepEqual(docs, expected) {
+		t.Errorf("Got documents %+v wanted %+v", docs, expected)
+	}
+}
+
+func TestRuleset_Run_InvalidDocument(t *testing.T) {
+	data := `apiVersion: v1
+kind: Pod
+metadata:
+  name: first
+--- # broken
+apiVersion: v1
+kind: Pod
+metadata: [name
+--- # only a comment
+---
+apiVersion: v1
+kind: Pod
+metadata:
+  name: last
+`
+	config := NewDefaultSchemaConfig()
+	config.DisableValidation = true
+
+	ruleset, err := NewRuleset(zap.NewNop().Sugar())
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	for _, eol := range []string{"\n", "\r\n"} {
+		reports, err := ruleset.Run("pods.yaml", bytes.ReplaceAll([]byte(data), []byte("\n"), []byte(eol)), config)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+
+		var got []string
+		for _, r := range reports {
+			got = append(got, r.Object)
+		}
+		if wanted := []string{"Pod/first.default", "Unknown", "Pod/last.default"}; !reflect.DeepEqual(got, wanted) {
+			t.Fatalf("Got objects %v wanted %v", got, wanted)
+		}
+		for i, r := range reports {
+			if r.DocumentIndex != i {
+				t.Errorf("Got document index %d for report %d", r.DocumentIndex, i)
+			}
+		}
+
+		broken := reports[1]
+		if broken.Valid || broken.Error == nil || broken.Error.Reason != ErrorReasonParse {
+			t.Fatalf("Got report %+v wanted an invalid report with a parse error", broken)
+		}
+		// the error points at the line of the file, not of the document
+		wanted := rules.Location{File: "pods.yaml", DocumentIndex: 1, Line: 8, Column: 1}
+		if broken.Error.Location == nil || *broken.Error.Location != wanted {
+			t.Errorf("Got error location %v wanted %v", broken.Error.Location, wanted)
+		}
+		if !strings.HasPrefix(broken.Error.Message, "yaml: line 8: ") || broken.Message != broken.Error.Message {
+			t.Errorf("Got error message %q wanted the line of the file", broken.Error.Message)
+		}
+		if reports[0].Error != nil || reports[2].Error != nil {
+			t.Errorf("Got errors on the valid documents")
+		}
+	}
+}
+
+func TestDocumentError(t *testing.T) {
+	tests := []struct {
+		err             string
+		expectedLine    int
+		expectedMessage string
+	}{
+		{
+			err:             "yaml: line 4: did not find expected ',' or ']'",
+			expectedLine:    243,
+			expectedMessage: "yaml: line 243: did not find expected ',' or ']'",
+		},
+		{
+			err:             "error converting YAML to JSON: yaml: line 1: mapping values are not allowed in this context",
+			expectedLine:    240,
+			expectedMessage: "error converting YAML to JSON: yaml: line 240: mapping values are not allowed in this context",
+		},
+		{
+			err:             "yaml: found character that cannot start any token",
+			expectedLine:    240,
+			expectedMessage: "yaml: found character that cannot start any token",
+		},
+	}
+
+	for _, tt := range tests {
+		line, err := documentError(errors.New(tt.err), 240)
+		if line != tt.expectedLine || err.Error() != tt.expectedMessage {
+			t.Errorf("Got line %d and %q wanted line %d and %q", line, err, tt.expectedLine, tt.expectedMessage)
+		}
+	}
+}
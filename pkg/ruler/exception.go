@@ -0,0 +1,248 @@
+package ruler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ghodss/yaml"
+)
+
+// SuppressionSourceException is the source of suppressions set by an exceptions file
+const SuppressionSourceException = "exception"
+
+// ExceptionDateFormat is the format of exception expiry dates
+const ExceptionDateFormat = "2006-01-02"
+
+// now returns the current time, exceptions expire relative to it
+var now = time.Now
+
+// ExceptionsFile is the format of an exceptions file
+type ExceptionsFile struct {
+	Exceptions []Exception `json:"exceptions"`
+}
+
+// Exception accepts the findings of rules for the objects it matches. Kinds,
+// namespaces, names and files are matched when set, namespaces, names and
+// files being glob patterns. An exception applies until the end of its expiry
+// date, in UTC.
+type Exception struct {
+	Rules         []string `json:"rules"`
+	Kinds         []string `json:"kinds,omitempty"`
+	Namespaces    []string `json:"namespaces,omitempty"`
+	Names         []string `json:"names,omitempty"`
+	Files         []string `json:"files,omitempty"`
+	Owner         string   `json:"owner"`
+	Expires       string   `json:"expires"`
+	Justification string   `json:"justification"`
+
+	expires time.Time
+}
+
+// exceptionTarget is the finding an exception is matched against
+type exceptionTarget struct {
+	ruleID, kind, namespace, name, fileName string
+}
+
+// Exceptions are the exceptions of a file. They record which of them matched
+// a finding and are safe for concurrent use.
+type Exceptions struct {
+	Path string
+
+	items []Exception
+	mu    sync.Mutex
+	used  []bool
+}
+
+// LoadExceptions reads and validates an exceptions file
+func LoadExceptions(path string) (*Exceptions, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	jsonData, err := yaml.YAMLToJSON(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	var file ExceptionsFile
+	dec := json.NewDecoder(bytes.NewReader(jsonData))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&file); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	exceptions, err := NewExceptions(file.Exceptions)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	exceptions.Path = path
+
+	return exceptions, nil
+}
+
+// NewExceptions validates the exceptions and returns them ready to be matched
+func NewExceptions(items []Exception) (*Exceptions, error) {
+	exceptions := &Exceptions{
+		items: make([]Exception, len(items)),
+		used:  make([]bool, len(items)),
+	}
+
+	for i, e := range items {
+		if err := e.validate(); err != nil {
+			return nil, fmt.Errorf("exception %d: %w", i, err)
+		}
+		exceptions.items[i] = e
+	}
+
+	return exceptions, nil
+}
+
+func (e *Exception) validate() error {
+	if len(e.Rules) == 0 {
+		return fmt.Errorf("rules are required")
+	}
+	if e.Owner == "" {
+		return fmt.Errorf("owner is required")
+	}
+	if e.Justification == "" {
+		return fmt.Errorf("justification is required")
+	}
+	if e.Expires == "" {
+		return fmt.Errorf("expires is required")
+	}
+	expires, err := time.Parse(ExceptionDateFormat, e.Expires)
+	if err != nil {
+		return fmt.Errorf("invalid expiry date %q, expected YYYY-MM-DD", e.Expires)
+	}
+	e.expires = expires
+
+	for _, k := range e.Kinds {
+		if !IsKnownKind(k) {
+			return fmt.Errorf("unknown kind %q", k)
+		}
+	}
+	for _, patterns := range [][]string{e.Namespaces, e.Names, e.Files} {
+		for _, p := range patterns {
+			if _, err := path.Match(p, ""); err != nil {
+				return fmt.Errorf("invalid pattern %q", p)
+			}
+		}
+	}
+	return nil
+}
+
+// Expired reports whether the exception no longer applies at the given time,
+// the expiry date being the last day it applies
+func (e *Exception) Expired(t time.Time) bool {
+	return !t.Before(e.expires.AddDate(0, 0, 1))
+}
+
+func (e *Exception) matches(target exceptionTarget) bool {
+	if !contains(e.Rules, target.ruleID) {
+		return false
+	}
+	if len(e.Kinds) > 0 && !contains(e.Kinds, target.kind) {
+		return false
+	}
+	return matchesAny(e.Namespaces, target.namespace) &&
+		matchesAny(e.Names, target.name) &&
+		matchesFile(e.Files, target.fileName)
+}
+
+// matchesAny reports whether the value matches one of the patterns, any
+// value matching an empty list
+func matchesAny(patterns []string, value string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, value); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesFile matches the file name of a scan, patterns without a separator
+// being matched against its base name
+func matchesFile(patterns []string, fileName string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	fileName = filepath.ToSlash(fileName)
+	for _, p := range patterns {
+		value := fileName
+		if !strings.Contains(p, "/") {
+			value = path.Base(fileName)
+		}
+		if ok, _ := path.Match(p, value); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// suppress returns the suppression of the first exception matching the
+// finding, preferring exceptions which have not expired. It returns nil if
+// no exception matches. Every matching exception is marked as used.
+func (es *Exceptions) suppress(target exceptionTarget) *Suppression {
+	es.mu.Lock()
+	defer es.mu.Unlock()
+
+	t := now()
+	match := -1
+	for i := range es.items {
+		if !es.items[i].matches(target) {
+			continue
+		}
+		es.used[i] = true
+		if match < 0 || (es.items[match].Expired(t) && !es.items[i].Expired(t)) {
+			match = i
+		}
+	}
+	if match < 0 {
+		return nil
+	}
+
+	e := es.items[match]
+	return &Suppression{
+		Source:        SuppressionSourceException,
+		Justification: e.Justification,
+		Owner:         e.Owner,
+		Expires:       e.Expires,
+		Expired:       e.Expired(t),
+	}
+}
+
+// validate checks the rule IDs referenced by the exceptions exist
+func (es *Exceptions) validate(ruleExists func(string) bool) error {
+	for i, e := range es.items {
+		for _, id := range e.Rules {
+			if !ruleExists(id) {
+				return fmt.Errorf("%s: exception %d: unknown rule ID %s", es.Path, i, id)
+			}
+		}
+	}
+	return nil
+}
+
+// List returns the exceptions in file order
+func (es *Exceptions) List() []Exception {
+	return append([]Exception{}, es.items...)
+}
+
+// Used reports whether the exception at index i matched a finding
+func (es *Exceptions) Used(i int) bool {
+	es.mu.Lock()
+	defer es.mu.Unlock()
+
+	return es.used[i]
+}
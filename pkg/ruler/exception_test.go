@@ -0,0 +1,297 @@
+package ruler
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const exceptionsFile = `
+exceptions:
+  - rules: [HostNetwork]
+    kinds: [DaemonSet]
+    namespaces: [kube-*]
+    names: [calico-*]
+    owner: platform-team
+    expires: "2030-01-01"
+    justification: CNI plugin needs the host network
+  - rules: [Privileged]
+    files: [vendor/*]
+    owner: platform-team
+    expires: "2020-01-01"
+    justification: vendored chart
+  - rules: [HostPID]
+    owner: platform-team
+    expires: "2030-01-01"
+    justification: never used
+`
+
+func TestRuleset_Exceptions(t *testing.T) {
+	defer func(f func() time.Time) { now = f }(now)
+	now = func() time.Time { return time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC) }
+
+	tests := []struct {
+		name, namespace, objectName, fileName string
+		expectedScore                         int
+		expectedSuppressed                    []string
+		expectedExpired                       []string
+	}{
+		{
+			name:               "exception matches kind, namespace and name",
+			namespace:          "kube-system",
+			objectName:         "calico-node",
+			fileName:           "cni.yaml",
+			expectedScore:      -30,
+			expectedSuppressed: []string{"HostNetwork"},
+		},
+		{
+			name:          "exception does not match other names",
+			namespace:     "kube-system",
+			objectName:    "cilium",
+			fileName:      "cni.yaml",
+			expectedScore: -39,
+		},
+		{
+			name:            "expired exception is reported as a failure",
+			namespace:       "default",
+			objectName:      "cilium",
+			fileName:        "vendor/cni.yaml",
+			expectedScore:   -39,
+			expectedExpired: []string{"Privileged"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exceptions, err := LoadExceptions(writeRulesFile(t, t.TempDir(), "exceptions.yaml", exceptionsFile))
+			if err != nil {
+				t.Fatal(err.Error())
+			}
+
+			data := `
+apiVersion: apps/v1
+kind: DaemonSet
+metadata:
+  name: ` + tt.objectName + `
+  namespace: ` + tt.namespace + `
+spec:
+  template:
+    spec:
+      hostNetwork: true
+      containers:
+        - name: cni
+          securityContext:
+            privileged: true
+`
+			schemaConfig := NewDefaultSchemaConfig()
+			schemaConfig.DisableValidation = true
+
+			ruleset, err := NewRulesetWithConfig(zap.NewNop().Sugar(),
+				RulesetConfig{Exceptions: exceptions}, "HostNetwork", "Privileged")
+			if err != nil {
+				t.Fatal(err.Error())
+			}
+			reports, err := ruleset.Run(tt.fileName, []byte(data), schemaConfig)
+			if err != nil || len(reports) == 0 {
+				t.Fatal(err)
+			}
+
+			report := reports[0]
+			if report.Score != tt.expectedScore {
+				t.Errorf("Got score %v wanted %v", report.Score, tt.expectedScore)
+			}
+			if got := ruleIDs(report.Scoring.Suppressed); !equalIDs(got, tt.expectedSuppressed) {
+				t.Errorf("Got suppressed %v wanted %v", got, tt.expectedSuppressed)
+			}
+
+			var expired []string
+			for _, ruleRef := range report.Scoring.Critical {
+				if ruleRef.Suppression != nil && ruleRef.Suppression.Expired {
+					expired = append(expired, ruleRef.ID)
+				}
+			}
+			if !equalIDs(expired, tt.expectedExpired) {
+				t.Errorf("Got expired %v wanted %v", expired, tt.expectedExpired)
+			}
+
+			if exceptions.Used(2) {
+				t.Errorf("Got exception 2 used, wanted unused")
+			}
+		})
+	}
+}
+
+func TestException_Expired(t *testing.T) {
+	exceptions, err := NewExceptions([]Exception{{
+		Rules:         []string{"HostNetwork"},
+		Owner:         "platform-team",
+		Expires:       "2025-06-01",
+		Justification: "CNI",
+	}})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	e := exceptions.List()[0]
+
+	tests := []struct {
+		time     time.Time
+		expected bool
+	}{
+		{time: time.Date(2025, 5, 31, 23, 59, 59, 0, time.UTC), expected: false},
+		{time: time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC), expected: false},
+		{time: time.Date(2025, 6, 1, 23, 59, 59, 0, time.UTC), expected: false},
+		{time: time.Date(2025, 6, 2, 0, 0, 0, 0, time.UTC), expected: true},
+	}
+
+	// the exception applies through its expiry date
+	for _, tt := range tests {
+		if expired := e.Expired(tt.time); expired != tt.expected {
+			t.Errorf("Got expired %v at %s wanted %v", expired, tt.time, tt.expected)
+		}
+	}
+}
+
+func TestRuleset_Exceptions_Used(t *testing.T) {
+	defer func(f func() time.Time) { now = f }(now)
+	now = func() time.Time { return time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC) }
+
+	exceptions, err := LoadExceptions(writeRulesFile(t, t.TempDir(), "exceptions.yaml", `
+exceptions:
+  - rules: [HostNetwork]
+    owner: platform-team
+    expires: "2020-01-01"
+    justification: expired
+  - rules: [HostNetwork]
+    kinds: [DaemonSet]
+    owner: platform-team
+    expires: "2030-01-01"
+    justification: CNI plugin needs the host network
+  - rules: [Privileged]
+    owner: platform-team
+    expires: "2030-01-01"
+    justification: superseded by the annotation
+  - rules: [HostPID]
+    owner: platform-team
+    expires: "2030-01-01"
+    justification: never used
+`))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	data := `
+apiVersion: apps/v1
+kind: DaemonSet
+metadata:
+  name: cni
+  annotations:
+    kubesec.io/ignore: Privileged
+    kubesec.io/ignore-justification: CNI plugin configures the host
+spec:
+  template:
+    spec:
+      hostNetwork: true
+      containers:
+        - name: cni
+          securityContext:
+            privileged: true
+`
+	schemaConfig := NewDefaultSchemaConfig()
+	schemaConfig.DisableValidation = true
+
+	ruleset, err := NewRulesetWithConfig(zap.NewNop().Sugar(),
+		RulesetConfig{Exceptions: exceptions}, "HostNetwork", "Privileged", "HostPID")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	reports, err := ruleset.Run("cni.yaml", []byte(data), schemaConfig)
+	if err != nil || len(reports) == 0 {
+		t.Fatal(err)
+	}
+
+	// the exception which has not expired suppresses HostNetwork, the
+	// annotation Privileged
+	for _, ruleRef := range reports[0].Scoring.Suppressed {
+		wanted := map[string]string{
+			"HostNetwork": "CNI plugin needs the host network",
+			"Privileged":  "CNI plugin configures the host",
+		}[ruleRef.ID]
+		if ruleRef.Suppression.Justification != wanted {
+			t.Errorf("Got %s suppressed by %q wanted %q", ruleRef.ID, ruleRef.Suppression.Justification, wanted)
+		}
+	}
+	if got := ruleIDs(reports[0].Scoring.Suppressed); !equalIDs(got, []string{"HostNetwork", "Privileged"}) {
+		t.Errorf("Got suppressed %v wanted %v", got, []string{"HostNetwork", "Privileged"})
+	}
+
+	// every matching exception is used, even when another exception or an
+	// annotation suppresses the finding
+	for i, expected := range []bool{true, true, true, false} {
+		if used := exceptions.Used(i); used != expected {
+			t.Errorf("Got exception %d used %v wanted %v", i, used, expected)
+		}
+	}
+}
+
+func TestLoadExceptions_Invalid(t *testing.T) {
+	tests := []struct {
+		name, content, expectedError string
+	}{
+		{
+			name: "missing owner",
+			content: `
+exceptions:
+  - rules: [HostNetwork]
+    expires: "2030-01-01"
+    justification: CNI
+`,
+			expectedError: "exception 0: owner is required",
+		},
+		{
+			name: "invalid expiry date",
+			content: `
+exceptions:
+  - rules: [HostNetwork]
+    owner: platform-team
+    expires: next year
+    justification: CNI
+`,
+			expectedError: `invalid expiry date "next year"`,
+		},
+		{
+			name: "unknown field",
+			content: `
+exceptions:
+  - rule: [HostNetwork]
+`,
+			expectedError: `unknown field "rule"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadExceptions(writeRulesFile(t, t.TempDir(), "exceptions.yaml", tt.content))
+			if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+				t.Errorf("Got error %v, expected: %v", err, tt.expectedError)
+			}
+		})
+	}
+}
+
+func TestRuleset_Exceptions_UnknownRule(t *testing.T) {
+	exceptions, err := NewExceptions([]Exception{{
+		Rules:         []string{"HostNetwrok"},
+		Owner:         "platform-team",
+		Expires:       "2030-01-01",
+		Justification: "CNI",
+	}})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	_, err = NewRulesetWithConfig(zap.NewNop().Sugar(), RulesetConfig{Exceptions: exceptions})
+	if err == nil || !strings.Contains(err.Error(), "unknown rule ID HostNetwrok") {
+		t.Errorf("Got error %v, expected unknown rule ID", err)
+	}
+}
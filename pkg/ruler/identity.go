@@ -0,0 +1,59 @@
+package ruler
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+)
+
+// Identity identifies the scanned object, to join reports with other
+// inventories of a cluster or repository
+type Identity struct {
+	APIVersion   string `json:"apiVersion,omitempty"`
+	Kind         string `json:"kind,omitempty"`
+	Name         string `json:"name,omitempty"`
+	GenerateName string `json:"generateName,omitempty"`
+	// Namespace is null when the object sets no namespace, it is then
+	// created in the namespace of the client applying it
+	Namespace *string           `json:"namespace"`
+	Labels    map[string]string `json:"labels,omitempty"`
+	// Hash is the sha256 of the canonical JSON of the object, with sorted
+	// keys and no whitespace
+	Hash string `json:"hash,omitempty"`
+}
+
+// getIdentity returns the identity of the JSON of an object, empty if it is
+// not an object
+func getIdentity(data []byte) Identity {
+	var identity Identity
+
+	var object interface{}
+	if err := json.Unmarshal(data, &object); err != nil {
+		return identity
+	}
+	// encoding/json sorts the keys of maps
+	if canonical, err := json.Marshal(object); err == nil {
+		identity.Hash = fmt.Sprintf("sha256:%x", sha256.Sum256(canonical))
+	}
+
+	var meta struct {
+		APIVersion string `json:"apiVersion"`
+		Kind       string `json:"kind"`
+		Metadata   struct {
+			Name         string            `json:"name"`
+			GenerateName string            `json:"generateName"`
+			Namespace    *string           `json:"namespace"`
+			Labels       map[string]string `json:"labels"`
+		} `json:"metadata"`
+	}
+	// the fields of an object of unexpected types are left unset
+	_ = json.Unmarshal(data, &meta)
+
+	identity.APIVersion = meta.APIVersion
+	identity.Kind = meta.Kind
+	identity.Name = meta.Metadata.Name
+	identity.GenerateName = meta.Metadata.GenerateName
+	identity.Namespace = meta.Metadata.Namespace
+	identity.Labels = meta.Metadata.Labels
+	return identity
+}
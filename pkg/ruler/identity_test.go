@@ -0,0 +1,116 @@
+package ruler
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestGetIdentity(t *testing.T) {
+	namespace := "prod"
+
+	tests := []struct {
+		name     string
+		data     string
+		expected Identity
+	}{
+		{
+			name: "named object",
+			data: `{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"name":"web","namespace":"prod","labels":{"app":"web"}}}`,
+			expected: Identity{
+				APIVersion: "apps/v1",
+				Kind:       "Deployment",
+				Name:       "web",
+				Namespace:  &namespace,
+				Labels:     map[string]string{"app": "web"},
+			},
+		},
+		{
+			name: "generated name without namespace",
+			data: `{"apiVersion":"batch/v1","kind":"Job","metadata":{"generateName":"migrate-"}}`,
+			expected: Identity{
+				APIVersion:   "batch/v1",
+				Kind:         "Job",
+				GenerateName: "migrate-",
+			},
+		},
+		{
+			name:     "labels of unexpected types",
+			data:     `{"kind":"Pod","metadata":{"name":"web","labels":["app"]}}`,
+			expected: Identity{Kind: "Pod", Name: "web"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := getIdentity([]byte(tt.data))
+			if got.Hash == "" {
+				t.Errorf("Got no hash")
+			}
+			got.Hash = ""
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Got %+v wanted %+v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestGetIdentity_Hash(t *testing.T) {
+	a := getIdentity([]byte(`{"kind": "Pod", "metadata": {"name": "web"}}`))
+	b := getIdentity([]byte(`{"metadata":{"name":"web"},"kind":"Pod"}`))
+	c := getIdentity([]byte(`{"metadata":{"name":"api"},"kind":"Pod"}`))
+
+	if a.Hash != b.Hash {
+		t.Errorf("Got hashes %s and %s for the same object", a.Hash, b.Hash)
+	}
+	if a.Hash == c.Hash {
+		t.Errorf("Got hash %s for different objects", a.Hash)
+	}
+}
+
+func TestRuleset_Run_Identity(t *testing.T) {
+	data := `apiVersion: v1
+kind: Pod
+metadata:
+  name: web
+spec:
+  containers:
+    - name: app
+      image: nginx
+`
+	config := NewDefaultSchemaConfig()
+	config.DisableValidation = true
+
+	ruleset, err := NewRuleset(zap.NewNop().Sugar())
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	reports, err := ruleset.Run("pod.yaml", []byte(data), config)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	out, err := json.Marshal(reports[0])
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	var report struct {
+		Object   string                 `json:"object"`
+		Identity map[string]interface{} `json:"identity"`
+	}
+	if err := json.Unmarshal(out, &report); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if report.Object != "Pod/web.default" {
+		t.Errorf("Got object %s wanted %s", report.Object, "Pod/web.default")
+	}
+	if namespace, ok := report.Identity["namespace"]; !ok || namespace != nil {
+		t.Errorf("Got namespace %v wanted an explicit null", namespace)
+	}
+	if report.Identity["name"] != "web" || report.Identity["apiVersion"] != "v1" {
+		t.Errorf("Got identity %v", report.Identity)
+	}
+}
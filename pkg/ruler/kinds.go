@@ -0,0 +1,53 @@
+package ruler
+
+import "github.com/controlplaneio/kubesec/v2/pkg/rules"
+
+// knownKinds lists the Kubernetes kinds a rule may target.
+var knownKinds = map[string]bool{
+	"Pod":                              true,
+	"PodTemplate":                      true,
+	"Deployment":                       true,
+	"StatefulSet":                      true,
+	"DaemonSet":                        true,
+	"ReplicaSet":                       true,
+	"ReplicationController":            true,
+	"Job":                              true,
+	"CronJob":                          true,
+	"Service":                          true,
+	"ServiceAccount":                   true,
+	"ConfigMap":                        true,
+	"Secret":                           true,
+	"Namespace":                        true,
+	"Node":                             true,
+	"Endpoints":                        true,
+	"EndpointSlice":                    true,
+	"Ingress":                          true,
+	"IngressClass":                     true,
+	"NetworkPolicy":                    true,
+	"PersistentVolume":                 true,
+	"PersistentVolumeClaim":            true,
+	"StorageClass":                     true,
+	"CSIDriver":                        true,
+	"Role":                             true,
+	"ClusterRole":                      true,
+	"RoleBinding":                      true,
+	"ClusterRoleBinding":               true,
+	"PodDisruptionBudget":              true,
+	"PodSecurityPolicy":                true,
+	"HorizontalPodAutoscaler":          true,
+	"LimitRange":                       true,
+	"ResourceQuota":                    true,
+	"PriorityClass":                    true,
+	"RuntimeClass":                     true,
+	"CustomResourceDefinition":         true,
+	"MutatingWebhookConfiguration":     true,
+	"ValidatingWebhookConfiguration":   true,
+	"ValidatingAdmissionPolicy":        true,
+	"ValidatingAdmissionPolicyBinding": true,
+}
+
+// IsKnownKind reports whether kind can be targeted by a rule, including the
+// kinds with a pod spec mapping.
+func IsKnownKind(kind string) bool {
+	return knownKinds[kind] || rules.IsPodSpecKind(kind)
+}
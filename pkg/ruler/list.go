@@ -0,0 +1,59 @@
+package ruler
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+)
+
+// listItems returns the items of a top-level JSON array or of a List object,
+// e.g. the output of kubectl get -o yaml, and the path of the items in the
+// document. It returns false for other objects.
+func listItems(data []byte) ([][]byte, []string, bool) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return nil, nil, false
+	}
+
+	if data[0] == '[' {
+		var items []json.RawMessage
+		if err := json.Unmarshal(data, &items); err != nil {
+			return nil, nil, false
+		}
+		return rawItems(items), nil, true
+	}
+
+	var list struct {
+		Kind  string            `json:"kind"`
+		Items []json.RawMessage `json:"items"`
+	}
+	if err := json.Unmarshal(data, &list); err != nil || !strings.HasSuffix(list.Kind, "List") || list.Items == nil {
+		return nil, nil, false
+	}
+	return rawItems(list.Items), []string{"items"}, true
+}
+
+func rawItems(items []json.RawMessage) [][]byte {
+	out := make([][]byte, 0, len(items))
+	for _, item := range items {
+		out = append(out, item)
+	}
+	return out
+}
+
+// splitJSONLines splits newline-delimited JSON into its lines. It returns
+// false unless there are several lines and each holds a JSON object or array.
+func splitJSONLines(data []byte) ([]yamlDocument, bool) {
+	var lines []yamlDocument
+	for i, l := range bytes.Split(data, []byte("\n")) {
+		l = bytes.TrimSpace(l)
+		if len(l) == 0 {
+			continue
+		}
+		if (l[0] != '{' && l[0] != '[') || !json.Valid(l) {
+			return nil, false
+		}
+		lines = append(lines, yamlDocument{data: l, line: i + 1})
+	}
+	return lines, len(lines) > 1
+}
@@ -0,0 +1,196 @@
+package ruler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ghodss/yaml"
+
+	"github.com/controlplaneio/kubesec/v2/pkg/rules"
+)
+
+// RulesFile is the format of a custom rules file
+type RulesFile struct {
+	Rules   []CustomRule   `json:"rules,omitempty"`
+	Plugins []PluginConfig `json:"plugins,omitempty"`
+}
+
+// CustomRule is a declarative rule loaded from a rules file. It shares its
+// fields with Rule, the Go predicate being replaced by either a match
+// expression or a CEL expression.
+type CustomRule struct {
+	ID       string       `json:"id"`
+	Selector string       `json:"selector,omitempty"`
+	Reason   string       `json:"reason"`
+	Link     string       `json:"link,omitempty"`
+	Kinds    []string     `json:"kinds"`
+	Points   int          `json:"points"`
+	Advise   int          `json:"advise,omitempty"`
+	Severity Severity     `json:"severity,omitempty"`
+	Match    *rules.Match `json:"match,omitempty"`
+	CEL      string       `json:"cel,omitempty"`
+	// Compliance references the controls of compliance frameworks the rule maps to
+	Compliance []Compliance `json:"compliance,omitempty"`
+}
+
+// LoadRulesFiles loads the custom rules defined in the given files and
+// validates them. Rule IDs must be unique across all files.
+func LoadRulesFiles(paths ...string) ([]Rule, error) {
+	var loaded []Rule
+	seen := make(map[string]string)
+
+	for _, path := range paths {
+		fileRules, err := loadRulesFile(path)
+		if err != nil {
+			return nil, err
+		}
+		for _, rule := range fileRules {
+			if other, ok := seen[rule.ID]; ok {
+				return nil, fmt.Errorf("%s: duplicate rule ID %s, already defined in %s", path, rule.ID, other)
+			}
+			seen[rule.ID] = path
+			loaded = append(loaded, rule)
+		}
+	}
+
+	return loaded, nil
+}
+
+// ListRulesFiles returns the .yaml, .yml and .json files of a directory,
+// sorted by name, to be loaded with LoadRulesFiles.
+func ListRulesFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var paths []string
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		switch strings.ToLower(filepath.Ext(e.Name())) {
+		case ".yaml", ".yml", ".json":
+			paths = append(paths, filepath.Join(dir, e.Name()))
+		}
+	}
+	sort.Strings(paths)
+
+	return paths, nil
+}
+
+func loadRulesFile(path string) ([]Rule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	jsonData, err := yaml.YAMLToJSON(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	var file RulesFile
+	dec := json.NewDecoder(bytes.NewReader(jsonData))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&file); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	loaded := make([]Rule, 0, len(file.Rules))
+	for i, cr := range file.Rules {
+		rule, err := cr.toRule(path)
+		if err != nil {
+			return nil, fmt.Errorf("%s: rule #%d: %w", path, i+1, err)
+		}
+		loaded = append(loaded, rule)
+	}
+
+	for _, pc := range file.Plugins {
+		pluginRules, err := pc.toRules(path, filepath.Dir(path))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		loaded = append(loaded, pluginRules...)
+	}
+
+	return loaded, nil
+}
+
+// celEvaluator evaluates an expression against each pod spec of an object,
+// like the predicate of a rule, failing with the first evaluation error
+func celEvaluator(predicate rules.CELPredicate) func([]byte) (Evaluation, error) {
+	return func(json []byte) (Evaluation, error) {
+		var evaluation Evaluation
+		for _, doc := range rules.SplitPodSpecs(json) {
+			res, err := predicate(doc)
+			if err != nil {
+				return Evaluation{}, &EvaluationError{Err: err}
+			}
+			evaluation.Count += res.Count
+			evaluation.Findings = append(evaluation.Findings, res.Findings...)
+		}
+		return evaluation, nil
+	}
+}
+
+func (cr CustomRule) toRule(source string) (Rule, error) {
+	var predicate rules.Predicate
+	var evaluator func([]byte) (Evaluation, error)
+	var err error
+	selector := cr.Selector
+
+	switch {
+	case cr.Match != nil && cr.CEL != "":
+		return Rule{}, fmt.Errorf("rule %s: only one of match or cel can be set", cr.ID)
+	case cr.Match != nil:
+		predicate, err = cr.Match.Compile()
+		if err != nil {
+			return Rule{}, fmt.Errorf("rule %s: %w", cr.ID, err)
+		}
+		if selector == "" {
+			if s, err := json.Marshal(cr.Match); err == nil {
+				selector = string(s)
+			}
+		}
+	case cr.CEL != "":
+		cel, err := rules.CompileCEL(cr.CEL)
+		if err != nil {
+			return Rule{}, fmt.Errorf("rule %s: invalid CEL expression: %w", cr.ID, err)
+		}
+		evaluator = celEvaluator(cel)
+		if selector == "" {
+			selector = cr.CEL
+		}
+	default:
+		return Rule{}, fmt.Errorf("rule %s: match or cel is required", cr.ID)
+	}
+
+	if err := validateCompliance(cr.Compliance); err != nil {
+		return Rule{}, fmt.Errorf("rule %s: %w", cr.ID, err)
+	}
+
+	rule := Rule{
+		Predicate:  predicate,
+		Evaluator:  evaluator,
+		ID:         cr.ID,
+		Selector:   selector,
+		Reason:     cr.Reason,
+		Link:       cr.Link,
+		Kinds:      cr.Kinds,
+		Points:     cr.Points,
+		Advise:     cr.Advise,
+		Severity:   cr.Severity,
+		Source:     source,
+		Compliance: cr.Compliance,
+	}
+	if err := rule.Validate(); err != nil {
+		return Rule{}, err
+	}
+	return rule, nil
+}
@@ -0,0 +1,282 @@
+package ruler
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+const houseRules = `
+rules:
+  - id: RequiredTeamLabel
+    reason: Workloads must be labelled with their owning team
+    kinds: [Deployment, StatefulSet, DaemonSet]
+    points: -3
+    match:
+      path: metadata.labels.team
+      op: notExists
+  - id: BannedRegistry
+    reason: Images must not be pulled from docker.io
+    kinds: [Pod, Deployment]
+    points: -9
+    match:
+      scope: containers
+      path: image
+      op: matches
+      value: "^docker\\.io/"
+  - id: MemoryLimitBelow2Gi
+    reason: Containers must set a memory limit below 2Gi
+    kinds: [Pod, Deployment]
+    points: 1
+    cel: allContainers(object).filter(c, quantity(c.?resources.?limits.?memory.orValue("Inf")) < quantity("2Gi")).size()
+`
+
+func writeRulesFile(t *testing.T, dir, name, content string) string {
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err.Error())
+	}
+	return path
+}
+
+func TestLoadRulesFiles(t *testing.T) {
+	path := writeRulesFile(t, t.TempDir(), "house.yaml", houseRules)
+
+	customRules, err := LoadRulesFiles(path)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if len(customRules) != 3 {
+		t.Fatalf("Got %v rules wanted %v", len(customRules), 3)
+	}
+	if customRules[0].Source != path {
+		t.Errorf("Got source %q wanted %q", customRules[0].Source, path)
+	}
+
+	var data = `
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+spec:
+  template:
+    spec:
+      containers:
+        - name: c1
+          image: docker.io/nginx
+        - name: c2
+          image: registry.example.com/app
+          resources:
+            limits:
+              memory: 1Gi
+`
+
+	config := NewDefaultSchemaConfig()
+	config.DisableValidation = true
+
+	ruleset, err := NewRulesetWithConfig(zap.NewNop().Sugar(), RulesetConfig{CustomRules: customRules}, "RequiredTeamLabel", "BannedRegistry", "MemoryLimitBelow2Gi")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	reports, err := ruleset.Run("kube.yaml", []byte(data), config)
+	if err != nil || len(reports) == 0 {
+		t.Fatal(err)
+	}
+
+	report := reports[0]
+	if len(report.Scoring.Critical) != 2 {
+		t.Errorf("Got %v critical rules wanted %v", len(report.Scoring.Critical), 2)
+	}
+	if len(report.Scoring.Passed) != 1 {
+		t.Errorf("Got %v passed rules wanted %v", len(report.Scoring.Passed), 1)
+	}
+	if report.Score != -11 {
+		t.Errorf("Got score %v wanted %v", report.Score, -11)
+	}
+}
+
+func TestLoadRulesFiles_Invalid(t *testing.T) {
+	tests := []struct {
+		name, content, expectedError string
+	}{
+		{
+			name: "unknown kind",
+			content: `
+rules:
+  - id: Foo
+    reason: foo
+    kinds: [Deploymnet]
+    points: 1
+    match: {path: metadata.name, op: exists}
+`,
+			expectedError: `unknown kind "Deploymnet"`,
+		},
+		{
+			name: "bad expression",
+			content: `
+rules:
+  - id: Foo
+    reason: foo
+    kinds: [Deployment]
+    points: 1
+    match: {path: metadata.name, op: matches, value: "(["}
+`,
+			expectedError: "invalid regular expression",
+		},
+		{
+			name: "missing match",
+			content: `
+rules:
+  - id: Foo
+    reason: foo
+    kinds: [Deployment]
+    points: 1
+`,
+			expectedError: "match or cel is required",
+		},
+		{
+			name: "CEL compile error",
+			content: `
+rules:
+  - id: Foo
+    reason: foo
+    kinds: [Deployment]
+    points: 1
+    cel: allContainers(object).size(
+`,
+			expectedError: "rule Foo: invalid CEL expression",
+		},
+		{
+			name: "match and CEL",
+			content: `
+rules:
+  - id: Foo
+    reason: foo
+    kinds: [Deployment]
+    points: 1
+    match: {path: metadata.name, op: exists}
+    cel: "true"
+`,
+			expectedError: "only one of match or cel",
+		},
+		{
+			name: "unknown field",
+			content: `
+rules:
+  - id: Foo
+    reason: foo
+    kind: Deployment
+    points: 1
+    match: {path: metadata.name, op: exists}
+`,
+			expectedError: "unknown field",
+		},
+		{
+			name: "invalid ID",
+			content: `
+rules:
+  - id: "1 Foo"
+    reason: foo
+    kinds: [Deployment]
+    points: 1
+    match: {path: metadata.name, op: exists}
+`,
+			expectedError: "invalid rule ID",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeRulesFile(t, t.TempDir(), "rules.yaml", tt.content)
+			_, err := LoadRulesFiles(path)
+			if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+				t.Errorf("Got error %v, expected: %v", err, tt.expectedError)
+			}
+		})
+	}
+}
+
+func TestLoadRulesFiles_DuplicateIDs(t *testing.T) {
+	dir := t.TempDir()
+	writeRulesFile(t, dir, "a.yaml", houseRules)
+	writeRulesFile(t, dir, "b.yml", houseRules)
+	writeRulesFile(t, dir, "README.md", "not a rules file")
+
+	paths, err := ListRulesFiles(dir)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if len(paths) != 2 {
+		t.Fatalf("Got %v rules files wanted %v", len(paths), 2)
+	}
+
+	_, err = LoadRulesFiles(paths...)
+	if err == nil || !strings.Contains(err.Error(), "duplicate rule ID RequiredTeamLabel") {
+		t.Errorf("Got error %v, expected duplicate rule ID", err)
+	}
+}
+
+func TestNewRulesetWithConfig_BuiltinClash(t *testing.T) {
+	content := `
+rules:
+  - id: Privileged
+    reason: shadows the built-in rule
+    kinds: [Pod]
+    points: 1
+    match: {path: metadata.name, op: exists}
+`
+	path := writeRulesFile(t, t.TempDir(), "rules.yaml", content)
+	customRules, err := LoadRulesFiles(path)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	_, err = NewRulesetWithConfig(zap.NewNop().Sugar(), RulesetConfig{CustomRules: customRules})
+	if err == nil || !strings.Contains(err.Error(), "duplicate rule ID Privileged") {
+		t.Errorf("Got error %v, expected duplicate rule ID", err)
+	}
+}
+
+func TestLoadRulesFiles_EvaluationError(t *testing.T) {
+	content := `
+rules:
+  - id: OwnerAnnotation
+    reason: Workloads must be annotated with their owner
+    kinds: [Pod]
+    points: 1
+    cel: object.metadata.annotations.owner == "payments"
+`
+	path := writeRulesFile(t, t.TempDir(), "rules.yaml", content)
+	customRules, err := LoadRulesFiles(path)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	core, logs := observer.New(zap.WarnLevel)
+	ruleset, err := NewRulesetWithConfig(zap.New(core).Sugar(), RulesetConfig{CustomRules: customRules}, "OwnerAnnotation")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	config := NewDefaultSchemaConfig()
+	config.DisableValidation = true
+
+	data := []byte(`{"apiVersion":"v1","kind":"Pod","metadata":{"name":"app"},"spec":{"containers":[{"name":"app"}]}}`)
+	reports, err := ruleset.Run("pod.json", data, config)
+	if err != nil || len(reports) == 0 {
+		t.Fatal(err)
+	}
+
+	// the pod sets no annotations, the rule can't evaluate it and is skipped
+	if len(reports[0].Rules) != 0 || reports[0].Score != 0 {
+		t.Errorf("Got %v rules with score %v, wanted none", len(reports[0].Rules), reports[0].Score)
+	}
+	if logs.FilterMessageSnippet("skipping rule OwnerAnnotation").Len() != 1 {
+		t.Errorf("Got logs %v wanted a warning for rule OwnerAnnotation", logs.All())
+	}
+}
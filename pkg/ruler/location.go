@@ -0,0 +1,115 @@
+package ruler
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/controlplaneio/kubesec/v2/pkg/rules"
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// source is a document of a scanned file with the positions of its nodes
+type source struct {
+	file string
+	// index is the index of the document in the file
+	index int
+	// line is the line of the file the document starts at, from 1
+	line int
+	root *yamlv3.Node
+	// prefix are the segments of the object in the document, e.g. items, 0
+	// for the first item of a List
+	prefix []string
+}
+
+// newSource parses the positions of a YAML or JSON document, the document
+// has no positions if it cannot be parsed
+func newSource(file string, index, line int, data []byte) *source {
+	s := &source{file: file, index: index, line: line}
+
+	var root yamlv3.Node
+	if err := yamlv3.Unmarshal(data, &root); err == nil && len(root.Content) > 0 {
+		s.root = root.Content[0]
+	}
+	return s
+}
+
+// locate returns the location of the node at the path segments, or of its
+// closest existing parent. It returns nil if the document has no positions.
+func (s *source) locate(segments []string) *rules.Location {
+	if s == nil || s.root == nil {
+		return nil
+	}
+
+	// mapping values are located at their key, so editors point at the field name
+	node, at := s.root, s.root
+	for _, segment := range append(append([]string{}, s.prefix...), segments...) {
+		key, value := child(node, segment)
+		if value == nil {
+			break
+		}
+		node, at = value, value
+		if key != nil {
+			at = key
+		}
+	}
+
+	return &rules.Location{
+		File:          s.file,
+		DocumentIndex: s.index,
+		Line:          s.line + at.Line - 1,
+		Column:        at.Column,
+	}
+}
+
+// item returns the source of the item at an index of the list at the prefix
+func (s *source) item(prefix []string, index int) *source {
+	if s == nil {
+		return nil
+	}
+	item := *s
+	item.prefix = append(append(append([]string{}, s.prefix...), prefix...), strconv.Itoa(index))
+	return &item
+}
+
+// locatePointer returns the location of the node at a JSON pointer, e.g.
+// /spec/replicas
+func (s *source) locatePointer(pointer string) *rules.Location {
+	var segments []string
+	if pointer = strings.TrimPrefix(pointer, "/"); pointer != "" {
+		for _, token := range strings.Split(pointer, "/") {
+			segments = append(segments, strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~"))
+		}
+	}
+	return s.locate(segments)
+}
+
+// locateFindings sets the location of the findings of the rules
+func (s *source) locateFindings(refs []RuleRef) {
+	for _, ref := range refs {
+		for i := range ref.Findings {
+			ref.Findings[i].Location = s.locate(ref.Findings[i].Segments())
+		}
+	}
+}
+
+// child returns the key and value nodes of a mapping key or the item of a
+// sequence index
+func child(node *yamlv3.Node, segment string) (*yamlv3.Node, *yamlv3.Node) {
+	for node.Kind == yamlv3.AliasNode && node.Alias != nil {
+		node = node.Alias
+	}
+
+	switch node.Kind {
+	case yamlv3.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == segment {
+				return node.Content[i], node.Content[i+1]
+			}
+		}
+	case yamlv3.SequenceNode:
+		if i, err := strconv.Atoi(segment); err == nil && i >= 0 && i < len(node.Content) {
+			return nil, node.Content[i]
+		}
+	}
+	return nil, nil
+}
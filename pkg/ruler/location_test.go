@@ -0,0 +1,115 @@
+package ruler
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/controlplaneio/kubesec/v2/pkg/rules"
+	"go.uber.org/zap"
+)
+
+func TestRuleset_Run_Locations(t *testing.T) {
+	data := `---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+---
+# the web server
+apiVersion: v1
+kind: Pod
+metadata:
+  name: web
+spec:
+  hostNetwork: true
+  containers:
+    - name: app
+      image: nginx
+    - name: cni
+      image: cni
+      securityContext:
+        privileged: true
+`
+
+	tests := []struct {
+		name string
+		data string
+	}{
+		{name: "LF", data: data},
+		{name: "CRLF", data: strings.ReplaceAll(data, "\n", "\r\n")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rs, err := NewRuleset(zap.NewNop().Sugar())
+			if err != nil {
+				t.Fatal(err.Error())
+			}
+			schemaConfig := NewDefaultSchemaConfig()
+			schemaConfig.DisableValidation = true
+
+			reports, err := rs.Run("pod.yaml", []byte(tt.data), schemaConfig)
+			if err != nil {
+				t.Fatal(err.Error())
+			}
+			if len(reports) != 2 {
+				t.Fatalf("Got %d reports wanted %d", len(reports), 2)
+			}
+
+			locations := make(map[string]rules.Location)
+			for _, ref := range reports[1].Scoring.Critical {
+				for _, f := range ref.Findings {
+					if f.Location == nil {
+						t.Fatalf("Got no location for %s finding %s", ref.ID, f.Path)
+					}
+					locations[ref.ID] = *f.Location
+				}
+			}
+
+			expected := map[string]rules.Location{
+				"HostNetwork": {File: "pod.yaml", DocumentIndex: 1, Line: 13, Column: 3},
+				"Privileged":  {File: "pod.yaml", DocumentIndex: 1, Line: 20, Column: 9},
+			}
+			for id, wanted := range expected {
+				if got := locations[id]; got != wanted {
+					t.Errorf("Got %s location %v wanted %v", id, got, wanted)
+				}
+			}
+		})
+	}
+}
+
+func TestSource_LocatePointer(t *testing.T) {
+	data := `
+apiVersion: apps/v1
+kind: Deployment
+spec:
+  replicas: "two"
+  template:
+    spec:
+      containers:
+      - name: app
+        ports:
+        - containerPort: http
+`
+
+	src := newSource("deploy.yaml", 2, 11, []byte(data))
+	tests := []struct {
+		pointer  string
+		expected rules.Location
+	}{
+		{"/spec/replicas", rules.Location{File: "deploy.yaml", DocumentIndex: 2, Line: 15, Column: 3}},
+		{"/spec/template/spec/containers/0/ports/0/containerPort", rules.Location{File: "deploy.yaml", DocumentIndex: 2, Line: 21, Column: 11}},
+		// missing fields are located at their closest parent
+		{"/spec/template/spec/containers/0/image", rules.Location{File: "deploy.yaml", DocumentIndex: 2, Line: 19, Column: 9}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pointer, func(t *testing.T) {
+			got := src.locatePointer(tt.pointer)
+			if got == nil || *got != tt.expected {
+				t.Errorf("Got %v wanted %v", got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,293 @@
+package ruler
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/controlplaneio/kubesec/v2/pkg/rules"
+)
+
+// PluginAPIVersion is the version of the protocol spoken with plugins.
+// See doc/plugins.md for the protocol description.
+const PluginAPIVersion = "kubesec.io/plugin/v1"
+
+const (
+	defaultPluginTimeout = 10 * time.Second
+	// pluginCacheSize bounds the number of documents whose plugin results
+	// are kept, so all the rules of a plugin share a single invocation. The
+	// least recently evaluated documents are evicted first.
+	pluginCacheSize = 64
+	// maxPluginOutput bounds the stdout and stderr read from a plugin.
+	maxPluginOutput = 4 << 20
+)
+
+// PluginConfig declares an external predicate plugin and the rules it evaluates
+type PluginConfig struct {
+	Name    string       `json:"name"`
+	Command string       `json:"command"`
+	Args    []string     `json:"args,omitempty"`
+	Timeout string       `json:"timeout,omitempty"`
+	Rules   []PluginRule `json:"rules"`
+}
+
+// PluginRule is a rule whose predicate is evaluated by a plugin
+type PluginRule struct {
+	ID       string   `json:"id"`
+	Selector string   `json:"selector,omitempty"`
+	Reason   string   `json:"reason"`
+	Link     string   `json:"link,omitempty"`
+	Kinds    []string `json:"kinds"`
+	Points   int      `json:"points"`
+	Advise   int      `json:"advise,omitempty"`
+	Severity Severity `json:"severity,omitempty"`
+}
+
+// PluginRequest is written to the standard input of a plugin
+type PluginRequest struct {
+	APIVersion string          `json:"apiVersion"`
+	Kind       string          `json:"kind"`
+	Rules      []string        `json:"rules"`
+	Object     json.RawMessage `json:"object"`
+}
+
+// PluginResponse is read from the standard output of a plugin
+type PluginResponse struct {
+	APIVersion string         `json:"apiVersion"`
+	Kind       string         `json:"kind"`
+	Results    []PluginResult `json:"results"`
+}
+
+// PluginResult is the outcome of a single rule evaluated by a plugin
+type PluginResult struct {
+	ID       string   `json:"id"`
+	Count    int      `json:"count"`
+	Reason   string   `json:"reason,omitempty"`
+	Evidence []string `json:"evidence,omitempty"`
+	// Findings are the fields the rule matched
+	Findings []rules.Finding `json:"findings,omitempty"`
+}
+
+// PluginError is returned when a plugin fails to evaluate a document
+type PluginError struct {
+	Plugin string
+	Err    error
+}
+
+func (e *PluginError) Error() string {
+	return fmt.Sprintf("plugin %s: %s", e.Plugin, e.Err)
+}
+
+func (e *PluginError) Unwrap() error {
+	return e.Err
+}
+
+// plugin runs an external predicate binary, once per document for all its rules
+type plugin struct {
+	name    string
+	command string
+	args    []string
+	timeout time.Duration
+	ruleIDs []string
+
+	mu sync.Mutex
+	// cache indexes the elements of recent, the calls of the most recently
+	// evaluated documents at its front
+	cache  map[[sha256.Size]byte]*list.Element
+	recent *list.List
+}
+
+type pluginCall struct {
+	key     [sha256.Size]byte
+	once    sync.Once
+	results map[string]PluginResult
+	err     error
+}
+
+// toRules validates the plugin configuration and returns its rules. A relative
+// command containing a path separator is resolved from baseDir.
+func (pc PluginConfig) toRules(source, baseDir string) ([]Rule, error) {
+	if !ruleIDPattern.MatchString(pc.Name) {
+		return nil, fmt.Errorf("invalid plugin name %q", pc.Name)
+	}
+	if pc.Command == "" {
+		return nil, fmt.Errorf("plugin %s: command is required", pc.Name)
+	}
+	if len(pc.Rules) == 0 {
+		return nil, fmt.Errorf("plugin %s: at least one rule is required", pc.Name)
+	}
+
+	timeout := defaultPluginTimeout
+	if pc.Timeout != "" {
+		var err error
+		timeout, err = time.ParseDuration(pc.Timeout)
+		if err != nil || timeout <= 0 {
+			return nil, fmt.Errorf("plugin %s: invalid timeout %q", pc.Name, pc.Timeout)
+		}
+	}
+
+	command := pc.Command
+	if !filepath.IsAbs(command) && strings.ContainsRune(command, filepath.Separator) {
+		command = filepath.Join(baseDir, command)
+	}
+
+	p := &plugin{
+		name:    pc.Name,
+		command: command,
+		args:    pc.Args,
+		timeout: timeout,
+		cache:   make(map[[sha256.Size]byte]*list.Element),
+		recent:  list.New(),
+	}
+
+	pluginRules := make([]Rule, 0, len(pc.Rules))
+	for _, pr := range pc.Rules {
+		id := pr.ID
+		rule := Rule{
+			Evaluator: func(json []byte) (Evaluation, error) {
+				return p.evaluate(id, json)
+			},
+			ID:       pr.ID,
+			Selector: pr.Selector,
+			Reason:   pr.Reason,
+			Link:     pr.Link,
+			Kinds:    pr.Kinds,
+			Points:   pr.Points,
+			Advise:   pr.Advise,
+			Severity: pr.Severity,
+			Source:   fmt.Sprintf("plugin:%s (%s)", pc.Name, source),
+		}
+		if rule.Selector == "" {
+			rule.Selector = "plugin:" + pc.Name
+		}
+		if err := rule.Validate(); err != nil {
+			return nil, fmt.Errorf("plugin %s: %w", pc.Name, err)
+		}
+		p.ruleIDs = append(p.ruleIDs, pr.ID)
+		pluginRules = append(pluginRules, rule)
+	}
+
+	return pluginRules, nil
+}
+
+// call returns the call of a document, evicting the least recently used
+// call when the cache is full
+func (p *plugin) call(key [sha256.Size]byte) *pluginCall {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if e, ok := p.cache[key]; ok {
+		p.recent.MoveToFront(e)
+		return e.Value.(*pluginCall)
+	}
+
+	if p.recent.Len() >= pluginCacheSize {
+		oldest := p.recent.Back()
+		p.recent.Remove(oldest)
+		delete(p.cache, oldest.Value.(*pluginCall).key)
+	}
+	call := &pluginCall{key: key}
+	p.cache[key] = p.recent.PushFront(call)
+	return call
+}
+
+// evaluate returns the result of a rule, running the plugin if the document
+// has not been evaluated yet.
+func (p *plugin) evaluate(ruleID string, json []byte) (Evaluation, error) {
+	call := p.call(sha256.Sum256(json))
+	call.once.Do(func() {
+		call.results, call.err = p.run(json)
+	})
+	if call.err != nil {
+		return Evaluation{}, &PluginError{Plugin: p.name, Err: call.err}
+	}
+
+	res := call.results[ruleID]
+	return Evaluation{
+		Count:    res.Count,
+		Reason:   res.Reason,
+		Evidence: res.Evidence,
+		Findings: res.Findings,
+	}, nil
+}
+
+// run executes the plugin binary with the document and decodes its response
+func (p *plugin) run(object []byte) (map[string]PluginResult, error) {
+	request, err := json.Marshal(PluginRequest{
+		APIVersion: PluginAPIVersion,
+		Kind:       "EvaluationRequest",
+		Rules:      p.ruleIDs,
+		Object:     object,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), p.timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, p.command, p.args...)
+	cmd.WaitDelay = time.Second
+	cmd.Stdin = bytes.NewReader(request)
+	stdout := &limitedBuffer{limit: maxPluginOutput}
+	stderr := &limitedBuffer{limit: maxPluginOutput}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	if err := cmd.Run(); err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, fmt.Errorf("timed out after %s", p.timeout)
+		}
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%w: %s", err, msg)
+		}
+		return nil, err
+	}
+	if stdout.truncated {
+		return nil, fmt.Errorf("response exceeds %d bytes", maxPluginOutput)
+	}
+
+	var response PluginResponse
+	if err := json.Unmarshal(stdout.Bytes(), &response); err != nil {
+		return nil, fmt.Errorf("invalid response: %w", err)
+	}
+	if response.APIVersion != PluginAPIVersion {
+		return nil, fmt.Errorf("unsupported response apiVersion %q, expected %q", response.APIVersion, PluginAPIVersion)
+	}
+
+	results := make(map[string]PluginResult, len(response.Results))
+	for _, res := range response.Results {
+		if res.Count < 0 {
+			return nil, fmt.Errorf("rule %s: negative count %d", res.ID, res.Count)
+		}
+		results[res.ID] = res
+	}
+	return results, nil
+}
+
+// limitedBuffer is a bytes.Buffer discarding writes past its limit
+type limitedBuffer struct {
+	bytes.Buffer
+	limit     int
+	truncated bool
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if remaining := b.limit - b.Len(); len(p) > remaining {
+		b.truncated = true
+		if remaining > 0 {
+			b.Buffer.Write(p[:remaining])
+		}
+		return len(p), nil
+	}
+	return b.Buffer.Write(p)
+}
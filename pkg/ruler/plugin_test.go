@@ -0,0 +1,231 @@
+package ruler
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const pluginModeEnv = "KUBESEC_TEST_PLUGIN_MODE"
+
+// TestPluginHelperProcess is not a real test, it is run as the plugin binary
+// by the plugin tests, following the behaviour set in pluginModeEnv.
+func TestPluginHelperProcess(t *testing.T) {
+	mode := os.Getenv(pluginModeEnv)
+	if mode == "" {
+		return
+	}
+
+	switch mode {
+	case "crash":
+		fmt.Fprintln(os.Stderr, "catalogue not found")
+		os.Exit(3)
+	case "hang":
+		time.Sleep(time.Minute)
+	case "bad-version":
+		fmt.Println(`{"apiVersion": "kubesec.io/plugin/v0", "results": []}`)
+		os.Exit(0)
+	}
+
+	var request PluginRequest
+	if err := json.NewDecoder(os.Stdin).Decode(&request); err != nil {
+		os.Exit(1)
+	}
+	var object struct {
+		Spec struct {
+			Containers []struct {
+				Image string `json:"image"`
+			} `json:"containers"`
+		} `json:"spec"`
+	}
+	if err := json.Unmarshal(request.Object, &object); err != nil {
+		os.Exit(1)
+	}
+
+	response := PluginResponse{APIVersion: PluginAPIVersion, Kind: "EvaluationResponse"}
+	for _, id := range request.Rules {
+		res := PluginResult{ID: id}
+		if id == "CatalogueImage" {
+			for _, c := range object.Spec.Containers {
+				if !strings.HasPrefix(c.Image, "registry.example.com/") {
+					res.Count++
+					res.Evidence = append(res.Evidence, c.Image+" is not in the catalogue")
+				}
+			}
+			res.Reason = fmt.Sprintf("%d images are not in the catalogue", res.Count)
+		}
+		response.Results = append(response.Results, res)
+	}
+	json.NewEncoder(os.Stdout).Encode(response)
+	os.Exit(0)
+}
+
+func pluginRulesFile(t *testing.T, timeout string) string {
+	content := fmt.Sprintf(`
+plugins:
+  - name: catalogue
+    command: %q
+    args: ["-test.run=^TestPluginHelperProcess$"]
+    timeout: %s
+    rules:
+      - id: CatalogueImage
+        reason: Images must be listed in the internal catalogue
+        kinds: [Pod]
+        points: -10
+      - id: CostCentreLabel
+        reason: Workloads should be labelled with a cost centre
+        kinds: [Pod]
+        points: 1
+`, os.Args[0], timeout)
+	return writeRulesFile(t, t.TempDir(), "plugins.yaml", content)
+}
+
+const pluginPod = `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: app
+spec:
+  containers:
+    - name: c1
+      image: docker.io/nginx
+    - name: c2
+      image: registry.example.com/app
+`
+
+func runPluginRules(t *testing.T, path string) Report {
+	customRules, err := LoadRulesFiles(path)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if len(customRules) != 2 || !strings.HasPrefix(customRules[0].Source, "plugin:catalogue") {
+		t.Fatalf("Got unexpected plugin rules %+v", customRules)
+	}
+
+	config := NewDefaultSchemaConfig()
+	config.DisableValidation = true
+	ruleset, err := NewRulesetWithConfig(zap.NewNop().Sugar(), RulesetConfig{CustomRules: customRules}, "CatalogueImage", "CostCentreLabel")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	reports, err := ruleset.Run("kube.yaml", []byte(pluginPod), config)
+	if err != nil || len(reports) == 0 {
+		t.Fatal(err)
+	}
+	return reports[0]
+}
+
+func TestPlugin(t *testing.T) {
+	t.Setenv(pluginModeEnv, "ok")
+	report := runPluginRules(t, pluginRulesFile(t, "10s"))
+
+	if len(report.Scoring.Critical) != 1 {
+		t.Fatalf("Got %v critical rules wanted %v", len(report.Scoring.Critical), 1)
+	}
+	critical := report.Scoring.Critical[0]
+	if critical.Reason != "1 images are not in the catalogue" {
+		t.Errorf("Got reason %q from plugin", critical.Reason)
+	}
+	if len(critical.Evidence) != 1 || critical.Evidence[0] != "docker.io/nginx is not in the catalogue" {
+		t.Errorf("Got evidence %v from plugin", critical.Evidence)
+	}
+	if len(report.Scoring.Advise) != 1 {
+		t.Errorf("Got %v advise rules wanted %v", len(report.Scoring.Advise), 1)
+	}
+}
+
+func TestPlugin_Failures(t *testing.T) {
+	tests := []struct {
+		mode, timeout string
+	}{
+		{mode: "crash", timeout: "10s"},
+		{mode: "hang", timeout: "200ms"},
+		{mode: "bad-version", timeout: "10s"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			t.Setenv(pluginModeEnv, tt.mode)
+			report := runPluginRules(t, pluginRulesFile(t, tt.timeout))
+
+			// failing plugin rules are skipped, the scan carries on
+			if len(report.Rules) != 0 || report.Score != 0 {
+				t.Errorf("Got %v rules with score %v, wanted none", len(report.Rules), report.Score)
+			}
+		})
+	}
+}
+
+func TestPlugin_Evaluate(t *testing.T) {
+	for _, tt := range []struct {
+		mode, expectedError string
+	}{
+		{mode: "crash", expectedError: "catalogue not found"},
+		{mode: "bad-version", expectedError: "unsupported response apiVersion"},
+	} {
+		t.Run(tt.mode, func(t *testing.T) {
+			t.Setenv(pluginModeEnv, tt.mode)
+			customRules, err := LoadRulesFiles(pluginRulesFile(t, "10s"))
+			if err != nil {
+				t.Fatal(err.Error())
+			}
+			_, err = customRules[0].Evaluator([]byte(`{"kind": "Pod"}`))
+			if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+				t.Errorf("Got error %v, expected: %v", err, tt.expectedError)
+			}
+		})
+	}
+}
+
+func TestPlugin_Cache(t *testing.T) {
+	p := &plugin{cache: make(map[[sha256.Size]byte]*list.Element), recent: list.New()}
+	key := func(i int) [sha256.Size]byte {
+		return sha256.Sum256([]byte(fmt.Sprint(i)))
+	}
+
+	calls := make([]*pluginCall, pluginCacheSize)
+	for i := range calls {
+		calls[i] = p.call(key(i))
+	}
+	// the first document is evaluated again, the second becomes the least
+	// recently used and is evicted by a new document
+	if p.call(key(0)) != calls[0] {
+		t.Fatalf("Got a new call for a cached document")
+	}
+	p.call(key(pluginCacheSize))
+
+	if len(p.cache) != pluginCacheSize || p.recent.Len() != pluginCacheSize {
+		t.Errorf("Got %v cached calls wanted %v", len(p.cache), pluginCacheSize)
+	}
+	if p.call(key(0)) != calls[0] {
+		t.Errorf("Got the recently used call evicted")
+	}
+	if p.call(key(1)) == calls[1] {
+		t.Errorf("Got the least recently used call kept")
+	}
+}
+
+func TestPluginConfig_Invalid(t *testing.T) {
+	content := `
+plugins:
+  - name: catalogue
+    command: ./catalogue
+    timeout: soon
+    rules:
+      - id: CatalogueImage
+        reason: Images must be listed in the internal catalogue
+        kinds: [Pod]
+        points: -10
+`
+	_, err := LoadRulesFiles(writeRulesFile(t, t.TempDir(), "plugins.yaml", content))
+	if err == nil || !strings.Contains(err.Error(), `invalid timeout "soon"`) {
+		t.Errorf("Got error %v, expected invalid timeout", err)
+	}
+}
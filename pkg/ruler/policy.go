@@ -0,0 +1,304 @@
+package ruler
+
+import (
+	"fmt"
+	"io"
+	"path"
+	"strings"
+)
+
+// ExitPolicy decides whether the objects of a scan pass, to gate CI
+// pipelines. Overrides refine the conditions for the objects matching their
+// kinds and namespaces, and are applied in order.
+type ExitPolicy struct {
+	PolicyConditions
+	Overrides []ExitPolicyOverride `json:"overrides,omitempty"`
+	ExitCodes ExitCodes            `json:"exitCodes,omitempty"`
+}
+
+// PolicyConditions are the conditions failing an object
+type PolicyConditions struct {
+	// MinScore is the minimum score to pass. Objects pass with the threshold
+	// of their profile if unset, or with a score above zero.
+	MinScore *int `json:"minScore,omitempty"`
+	// IgnoreScore passes objects whatever their score
+	IgnoreScore *bool `json:"ignoreScore,omitempty"`
+	// FailOnCritical fails objects with any critical finding
+	FailOnCritical *bool `json:"failOnCritical,omitempty"`
+	// FailOnRules fails objects with a critical or advise finding of these
+	// rules
+	FailOnRules []string `json:"failOnRules,omitempty"`
+	// FailOnSeverity fails objects with a critical or advise finding at or
+	// above the severity
+	FailOnSeverity Severity `json:"failOnSeverity,omitempty"`
+}
+
+// ExitPolicyOverride applies to objects whose kind and namespace match. An
+// empty list matches any value, namespaces are glob patterns.
+type ExitPolicyOverride struct {
+	Kinds      []string `json:"kinds,omitempty"`
+	Namespaces []string `json:"namespaces,omitempty"`
+	PolicyConditions
+}
+
+// ExitCodes are the exit codes of the outcomes of a scan, the default exit
+// codes are used for those left to zero
+type ExitCodes struct {
+	PolicyFailed  int `json:"policyFailed,omitempty"`
+	SchemaInvalid int `json:"schemaInvalid,omitempty"`
+	InvalidInput  int `json:"invalidInput,omitempty"`
+}
+
+// Outcome is the result of the policy for an object
+type Outcome string
+
+const (
+	OutcomePassed        Outcome = "passed"
+	OutcomePolicyFailed  Outcome = "policy failed"
+	OutcomeSchemaInvalid Outcome = "schema invalid"
+	OutcomeInvalidInput  Outcome = "invalid input"
+)
+
+// ObjectDecision is the outcome of the policy for a report and its reasons
+type ObjectDecision struct {
+	Object   string
+	FileName string
+	Outcome  Outcome
+	Reasons  []string
+}
+
+// Decision is the outcome of the policy for the reports of a scan
+type Decision struct {
+	Objects []ObjectDecision
+	// Outcome is the most severe outcome of the objects: invalid input, then
+	// schema invalid, then policy failed
+	Outcome  Outcome
+	ExitCode int
+}
+
+// Passed reports whether every object passed
+func (d Decision) Passed() bool {
+	return d.Outcome == OutcomePassed
+}
+
+// Validate checks the rule IDs, kinds, namespace patterns and severities
+// referenced by the policy. It does not change the policy, which may be
+// shared by concurrent scans.
+func (p *ExitPolicy) Validate(ruleExists func(string) bool) error {
+	check := func(c PolicyConditions) error {
+		for _, id := range c.FailOnRules {
+			if !ruleExists(id) {
+				return fmt.Errorf("exit policy: unknown rule ID %s", id)
+			}
+		}
+		if c.FailOnSeverity != "" {
+			if _, err := ParseSeverity(string(c.FailOnSeverity)); err != nil {
+				return fmt.Errorf("exit policy: %w", err)
+			}
+		}
+		return nil
+	}
+
+	if err := check(p.PolicyConditions); err != nil {
+		return err
+	}
+	for _, o := range p.Overrides {
+		for _, k := range o.Kinds {
+			if !IsKnownKind(k) {
+				return fmt.Errorf("exit policy: unknown kind %q", k)
+			}
+		}
+		for _, ns := range o.Namespaces {
+			if _, err := path.Match(ns, ""); err != nil {
+				return fmt.Errorf("exit policy: invalid namespace pattern %q", ns)
+			}
+		}
+		if err := check(o.PolicyConditions); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolve returns the conditions of the policy for an object, overrides
+// being applied in order, with a normalized severity
+func (p *ExitPolicy) resolve(kind, namespace string) PolicyConditions {
+	c := p.PolicyConditions
+	c.FailOnRules = append([]string{}, c.FailOnRules...)
+
+	for _, o := range p.Overrides {
+		if !o.matches(kind, namespace) {
+			continue
+		}
+		if o.MinScore != nil {
+			c.MinScore = o.MinScore
+		}
+		if o.IgnoreScore != nil {
+			c.IgnoreScore = o.IgnoreScore
+		}
+		if o.FailOnCritical != nil {
+			c.FailOnCritical = o.FailOnCritical
+		}
+		if o.FailOnSeverity != "" {
+			c.FailOnSeverity = o.FailOnSeverity
+		}
+		c.FailOnRules = append(c.FailOnRules, o.FailOnRules...)
+	}
+
+	if severity, err := ParseSeverity(string(c.FailOnSeverity)); err == nil {
+		c.FailOnSeverity = severity
+	}
+	return c
+}
+
+func (o *ExitPolicyOverride) matches(kind, namespace string) bool {
+	override := ProfileOverride{Kinds: o.Kinds, Namespaces: o.Namespaces}
+	return override.matches(kind, namespace)
+}
+
+// Decide applies the policy to the reports of a scan, the exit codes of the
+// policy left to zero are taken from defaults
+func (p *ExitPolicy) Decide(reports []Report, defaults ExitCodes) Decision {
+	codes := p.ExitCodes
+	if codes.PolicyFailed == 0 {
+		codes.PolicyFailed = defaults.PolicyFailed
+	}
+	if codes.SchemaInvalid == 0 {
+		codes.SchemaInvalid = defaults.SchemaInvalid
+	}
+	if codes.InvalidInput == 0 {
+		codes.InvalidInput = defaults.InvalidInput
+	}
+
+	decision := Decision{Outcome: OutcomePassed}
+	for _, r := range reports {
+		d := p.decide(r)
+		decision.Objects = append(decision.Objects, d)
+		if outcomeRank(d.Outcome) > outcomeRank(decision.Outcome) {
+			decision.Outcome = d.Outcome
+		}
+	}
+
+	switch decision.Outcome {
+	case OutcomeInvalidInput:
+		decision.ExitCode = codes.InvalidInput
+	case OutcomeSchemaInvalid:
+		decision.ExitCode = codes.SchemaInvalid
+	case OutcomePolicyFailed:
+		decision.ExitCode = codes.PolicyFailed
+	}
+	return decision
+}
+
+func (p *ExitPolicy) decide(r Report) ObjectDecision {
+	d := ObjectDecision{Object: r.Object, FileName: r.FileName, Outcome: OutcomePassed}
+
+	switch {
+	case r.Error != nil:
+		d.Outcome = OutcomeInvalidInput
+		d.Reasons = []string{fmt.Sprintf("%s: %s", r.Error.Reason, r.Error.Message)}
+		return d
+	case !r.Valid:
+		d.Outcome = OutcomeSchemaInvalid
+		d.Reasons = []string{strings.TrimSpace(r.Message)}
+		return d
+	}
+
+	namespace := ""
+	if r.Identity.Namespace != nil {
+		namespace = *r.Identity.Namespace
+	}
+	c := p.resolve(r.Identity.Kind, namespace)
+
+	if c.IgnoreScore == nil || !*c.IgnoreScore {
+		switch {
+		case c.MinScore != nil:
+			if r.Score < *c.MinScore {
+				d.Reasons = append(d.Reasons, fmt.Sprintf("score %d is below the minimum score %d", r.Score, *c.MinScore))
+			}
+		case r.Threshold != nil:
+			if r.Score < *r.Threshold {
+				d.Reasons = append(d.Reasons, fmt.Sprintf("score %d is below the threshold %d of profile %s", r.Score, *r.Threshold, r.Profile))
+			}
+		case r.Score <= 0:
+			d.Reasons = append(d.Reasons, fmt.Sprintf("score %d is not above zero", r.Score))
+		}
+	}
+
+	if c.FailOnCritical != nil && *c.FailOnCritical && len(r.Scoring.Critical) > 0 {
+		d.Reasons = append(d.Reasons, fmt.Sprintf("critical findings %s", strings.Join(refIDs(r.Scoring.Critical), ", ")))
+	}
+
+	failed := append(append([]RuleRef{}, r.Scoring.Critical...), r.Scoring.Advise...)
+	var matched []string
+	for _, ref := range failed {
+		if contains(c.FailOnRules, ref.ID) {
+			matched = append(matched, ref.ID)
+		}
+	}
+	if len(matched) > 0 {
+		d.Reasons = append(d.Reasons, fmt.Sprintf("findings of rules %s", strings.Join(matched, ", ")))
+	}
+
+	if c.FailOnSeverity != "" {
+		var atSeverity []string
+		for _, ref := range failed {
+			if ref.Severity.AtLeast(c.FailOnSeverity) {
+				atSeverity = append(atSeverity, ref.ID)
+			}
+		}
+		if len(atSeverity) > 0 {
+			d.Reasons = append(d.Reasons, fmt.Sprintf("findings at or above severity %s: %s", c.FailOnSeverity, strings.Join(atSeverity, ", ")))
+		}
+	}
+
+	if r.PodSecurity != nil && !r.PodSecurity.Allowed {
+		d.Reasons = append(d.Reasons, fmt.Sprintf("violates the %s Pod Security Standards level", r.PodSecurity.Level))
+	}
+
+	if len(d.Reasons) > 0 {
+		d.Outcome = OutcomePolicyFailed
+	}
+	return d
+}
+
+func refIDs(refs []RuleRef) []string {
+	ids := make([]string, 0, len(refs))
+	for _, ref := range refs {
+		ids = append(ids, ref.ID)
+	}
+	return ids
+}
+
+func outcomeRank(o Outcome) int {
+	switch o {
+	case OutcomeInvalidInput:
+		return 3
+	case OutcomeSchemaInvalid:
+		return 2
+	case OutcomePolicyFailed:
+		return 1
+	}
+	return 0
+}
+
+// Write prints the reasons of the failed objects and the outcome of the scan
+func (d Decision) Write(w io.Writer) error {
+	var failed int
+	for _, o := range d.Objects {
+		if o.Outcome == OutcomePassed {
+			continue
+		}
+		failed++
+		if _, err := fmt.Fprintf(w, "FAIL %s (%s), %s: %s\n", o.Object, o.FileName, o.Outcome, strings.Join(o.Reasons, "; ")); err != nil {
+			return err
+		}
+	}
+
+	if d.Passed() {
+		_, err := fmt.Fprintf(w, "PASS all %d objects passed the exit policy\n", len(d.Objects))
+		return err
+	}
+	_, err := fmt.Fprintf(w, "%d of %d objects failed the exit policy, exiting with %d (%s)\n", failed, len(d.Objects), d.ExitCode, d.Outcome)
+	return err
+}
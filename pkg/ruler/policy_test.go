@@ -0,0 +1,269 @@
+package ruler
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/controlplaneio/kubesec/v2/pkg/pss"
+	"go.uber.org/zap"
+)
+
+func TestExitPolicy_Decide(t *testing.T) {
+	intPtr := func(i int) *int { return &i }
+	boolPtr := func(b bool) *bool { return &b }
+	namespace := "kube-system"
+
+	deployment := Report{
+		Object: "Deployment/web.prod",
+		Valid:  true,
+		Score:  3,
+		Scoring: RuleScoring{
+			Critical: []RuleRef{{ID: "HostNetwork", Severity: SeverityHigh}},
+			Advise:   []RuleRef{{ID: "ReadOnlyRootFilesystem", Severity: SeverityLow}},
+		},
+		Identity: Identity{Kind: "Deployment"},
+	}
+	daemonSet := Report{
+		Object:   "DaemonSet/cni.kube-system",
+		Valid:    true,
+		Score:    -6,
+		Scoring:  RuleScoring{Critical: []RuleRef{{ID: "HostNetwork", Severity: SeverityHigh}}},
+		Identity: Identity{Kind: "DaemonSet", Namespace: &namespace},
+	}
+	codes := ExitCodes{PolicyFailed: 2, SchemaInvalid: 2, InvalidInput: 3}
+
+	var tests = []struct {
+		name     string
+		policy   ExitPolicy
+		reports  []Report
+		outcome  Outcome
+		exitCode int
+		reasons  [][]string
+	}{
+		{
+			name:     "default score gate",
+			reports:  []Report{deployment, daemonSet},
+			outcome:  OutcomePolicyFailed,
+			exitCode: 2,
+			reasons:  [][]string{nil, {"score -6 is not above zero"}},
+		},
+		{
+			name: "overrides per kind and namespace",
+			policy: ExitPolicy{
+				PolicyConditions: PolicyConditions{MinScore: intPtr(5)},
+				Overrides: []ExitPolicyOverride{{
+					Kinds:            []string{"DaemonSet"},
+					Namespaces:       []string{"kube-*"},
+					PolicyConditions: PolicyConditions{IgnoreScore: boolPtr(true)},
+				}},
+			},
+			reports:  []Report{deployment, daemonSet},
+			outcome:  OutcomePolicyFailed,
+			exitCode: 2,
+			reasons:  [][]string{{"score 3 is below the minimum score 5"}, nil},
+		},
+		{
+			name: "critical findings, rules and severities",
+			policy: ExitPolicy{
+				PolicyConditions: PolicyConditions{
+					IgnoreScore:    boolPtr(true),
+					FailOnCritical: boolPtr(true),
+					FailOnRules:    []string{"ReadOnlyRootFilesystem"},
+					FailOnSeverity: SeverityHigh,
+				},
+				ExitCodes: ExitCodes{PolicyFailed: 10},
+			},
+			reports:  []Report{deployment},
+			outcome:  OutcomePolicyFailed,
+			exitCode: 10,
+			reasons: [][]string{{
+				"critical findings HostNetwork",
+				"findings of rules ReadOnlyRootFilesystem",
+				"findings at or above severity high: HostNetwork",
+			}},
+		},
+		{
+			name:   "pod security violations",
+			policy: ExitPolicy{PolicyConditions: PolicyConditions{IgnoreScore: boolPtr(true)}},
+			reports: []Report{{
+				Object:      "Pod/web.default",
+				Valid:       true,
+				PodSecurity: &pss.Result{Level: pss.LevelRestricted},
+			}},
+			outcome:  OutcomePolicyFailed,
+			exitCode: 2,
+			reasons:  [][]string{{"violates the restricted Pod Security Standards level"}},
+		},
+		{
+			name:   "invalid input before schema invalid",
+			policy: ExitPolicy{ExitCodes: ExitCodes{SchemaInvalid: 4}},
+			reports: []Report{
+				{Object: "Deployment/web.default", Message: "missing properties: selector\n"},
+				{Object: "Unknown", Error: &ReportError{Reason: ErrorReasonParse, Message: "yaml: line 2"}},
+			},
+			outcome:  OutcomeInvalidInput,
+			exitCode: 3,
+			reasons:  [][]string{{"missing properties: selector"}, {"ParseError: yaml: line 2"}},
+		},
+		{
+			name:     "passed",
+			policy:   ExitPolicy{PolicyConditions: PolicyConditions{MinScore: intPtr(-10)}},
+			reports:  []Report{deployment, daemonSet},
+			outcome:  OutcomePassed,
+			exitCode: 0,
+			reasons:  [][]string{nil, nil},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decision := tt.policy.Decide(tt.reports, codes)
+			if decision.Outcome != tt.outcome || decision.ExitCode != tt.exitCode {
+				t.Errorf("Got outcome %s and exit code %d wanted %s and %d", decision.Outcome, decision.ExitCode, tt.outcome, tt.exitCode)
+			}
+			var reasons [][]string
+			for _, o := range decision.Objects {
+				reasons = append(reasons, o.Reasons)
+			}
+			if !reflect.DeepEqual(reasons, tt.reasons) {
+				t.Errorf("Got reasons %q wanted %q", reasons, tt.reasons)
+			}
+		})
+	}
+}
+
+func TestExitPolicy_Validate(t *testing.T) {
+	ruleExists := func(id string) bool { return id == "Privileged" }
+
+	var tests = []struct {
+		name    string
+		policy  ExitPolicy
+		wantErr bool
+	}{
+		{
+			name:   "valid",
+			policy: ExitPolicy{PolicyConditions: PolicyConditions{FailOnRules: []string{"Privileged"}, FailOnSeverity: "High"}},
+		},
+		{
+			name:    "unknown rule",
+			policy:  ExitPolicy{PolicyConditions: PolicyConditions{FailOnRules: []string{"NoSuchRule"}}},
+			wantErr: true,
+		},
+		{
+			name:    "unknown severity",
+			policy:  ExitPolicy{Overrides: []ExitPolicyOverride{{PolicyConditions: PolicyConditions{FailOnSeverity: "urgent"}}}},
+			wantErr: true,
+		},
+		{
+			name:    "unknown kind",
+			policy:  ExitPolicy{Overrides: []ExitPolicyOverride{{Kinds: []string{"Deploymnet"}}}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.policy.Validate(ruleExists)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Got error %v wanted error %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	// the policy is shared by the scans of the server and left as it is,
+	// severities are normalized when it is applied
+	policy := ExitPolicy{PolicyConditions: PolicyConditions{FailOnSeverity: "High"}}
+	if err := policy.Validate(ruleExists); err != nil || policy.FailOnSeverity != "High" {
+		t.Errorf("Got severity %q and error %v wanted %q", policy.FailOnSeverity, err, "High")
+	}
+	report := Report{Object: "Pod/web.default", Valid: true, Score: 5, Scoring: RuleScoring{
+		Advise: []RuleRef{{ID: "Privileged", Severity: SeverityCritical}},
+	}}
+	if d := policy.Decide([]Report{report}, ExitCodes{PolicyFailed: 2}); d.ExitCode != 2 {
+		t.Errorf("Got exit code %d wanted %d", d.ExitCode, 2)
+	}
+}
+
+func TestExitPolicy_Shared(t *testing.T) {
+	// the server builds a ruleset with the same policy for each request
+	policy := &ExitPolicy{
+		PolicyConditions: PolicyConditions{FailOnSeverity: "High"},
+		Overrides:        []ExitPolicyOverride{{Kinds: []string{"Pod"}, PolicyConditions: PolicyConditions{FailOnSeverity: "Medium"}}},
+	}
+	report := Report{Object: "Pod/web.default", Valid: true, Score: 5, Identity: Identity{Kind: "Pod"}, Scoring: RuleScoring{
+		Advise: []RuleRef{{ID: "Privileged", Severity: SeverityMedium}},
+	}}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ruleset, err := NewRulesetWithConfig(zap.NewNop().Sugar(), RulesetConfig{ExitPolicy: policy})
+			if err != nil {
+				t.Error(err.Error())
+				return
+			}
+			if d := ruleset.ExitPolicy.Decide([]Report{report}, ExitCodes{PolicyFailed: 2}); d.ExitCode != 2 {
+				t.Errorf("Got exit code %d wanted %d", d.ExitCode, 2)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestDecision_Write(t *testing.T) {
+	decision := Decision{
+		Objects: []ObjectDecision{
+			{Object: "Pod/web.default", FileName: "pod.yaml", Outcome: OutcomePassed},
+			{Object: "Pod/db.default", FileName: "pod.yaml", Outcome: OutcomePolicyFailed, Reasons: []string{"score 0 is not above zero"}},
+		},
+		Outcome:  OutcomePolicyFailed,
+		ExitCode: 2,
+	}
+
+	var buf bytes.Buffer
+	if err := decision.Write(&buf); err != nil {
+		t.Fatal(err.Error())
+	}
+	wanted := []string{
+		"FAIL Pod/db.default (pod.yaml), policy failed: score 0 is not above zero",
+		"1 of 2 objects failed the exit policy, exiting with 2 (policy failed)",
+	}
+	if got := strings.Split(strings.TrimSpace(buf.String()), "\n"); !reflect.DeepEqual(got, wanted) {
+		t.Errorf("Got %q wanted %q", got, wanted)
+	}
+}
+
+func TestLoadConfig_ExitPolicy(t *testing.T) {
+	content := `
+exitPolicy:
+  minScore: 2
+  failOnSeverity: critical
+  overrides:
+    - kinds: [DaemonSet]
+      namespaces: [kube-system]
+      ignoreScore: true
+  exitCodes:
+    policyFailed: 2
+    schemaInvalid: 4
+    invalidInput: 5
+`
+	config, err := LoadConfig(writeRulesFile(t, t.TempDir(), "kubesec.yaml", content))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	policy := config.ExitPolicy
+	if policy == nil || *policy.MinScore != 2 || policy.FailOnSeverity != SeverityCritical ||
+		len(policy.Overrides) != 1 || !*policy.Overrides[0].IgnoreScore || policy.ExitCodes.SchemaInvalid != 4 {
+		t.Errorf("Got exit policy %+v", policy)
+	}
+
+	_, err = LoadConfig(writeRulesFile(t, t.TempDir(), "kubesec.yaml", "exitPolicy:\n  minScroe: 2\n"))
+	if err == nil {
+		t.Errorf("Got no error for an unknown field wanted error")
+	}
+}
@@ -0,0 +1,126 @@
+package ruler
+
+import (
+	"fmt"
+	"path"
+)
+
+// Profile is a named policy which disables rules, overrides their points and
+// sets the minimum score to pass. Overrides refine the profile for the
+// objects matching their kinds and namespaces.
+type Profile struct {
+	Name      string            `json:"name"`
+	Threshold *int              `json:"threshold,omitempty"`
+	Disable   []string          `json:"disable,omitempty"`
+	Points    map[string]int    `json:"points,omitempty"`
+	Overrides []ProfileOverride `json:"overrides,omitempty"`
+}
+
+// ProfileOverride applies to objects whose kind and namespace match. An empty
+// list matches any value, namespaces are glob patterns.
+type ProfileOverride struct {
+	Kinds      []string       `json:"kinds,omitempty"`
+	Namespaces []string       `json:"namespaces,omitempty"`
+	Threshold  *int           `json:"threshold,omitempty"`
+	Disable    []string       `json:"disable,omitempty"`
+	Points     map[string]int `json:"points,omitempty"`
+}
+
+// profileSettings are the settings of a profile resolved for an object
+type profileSettings struct {
+	disabled  map[string]bool
+	points    map[string]int
+	threshold *int
+}
+
+// validate checks the rule IDs referenced by the profile exist
+func (p *Profile) validate(ruleExists func(string) bool) error {
+	check := func(disable []string, points map[string]int) error {
+		for _, id := range disable {
+			if !ruleExists(id) {
+				return fmt.Errorf("profile %s: unknown rule ID %s", p.Name, id)
+			}
+		}
+		for id := range points {
+			if !ruleExists(id) {
+				return fmt.Errorf("profile %s: unknown rule ID %s", p.Name, id)
+			}
+		}
+		return nil
+	}
+
+	if err := check(p.Disable, p.Points); err != nil {
+		return err
+	}
+	for _, o := range p.Overrides {
+		for _, k := range o.Kinds {
+			if !IsKnownKind(k) {
+				return fmt.Errorf("profile %s: unknown kind %q", p.Name, k)
+			}
+		}
+		for _, ns := range o.Namespaces {
+			if _, err := path.Match(ns, ""); err != nil {
+				return fmt.Errorf("profile %s: invalid namespace pattern %q", p.Name, ns)
+			}
+		}
+		if err := check(o.Disable, o.Points); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolve returns the settings of the profile for an object, overrides
+// being applied in order.
+func (p *Profile) resolve(kind, namespace string) profileSettings {
+	settings := profileSettings{
+		disabled:  make(map[string]bool),
+		points:    make(map[string]int),
+		threshold: p.Threshold,
+	}
+
+	apply := func(threshold *int, disable []string, points map[string]int) {
+		if threshold != nil {
+			settings.threshold = threshold
+		}
+		for _, id := range disable {
+			settings.disabled[id] = true
+		}
+		for id, v := range points {
+			settings.points[id] = v
+		}
+	}
+
+	apply(p.Threshold, p.Disable, p.Points)
+	for _, o := range p.Overrides {
+		if o.matches(kind, namespace) {
+			apply(o.Threshold, o.Disable, o.Points)
+		}
+	}
+
+	return settings
+}
+
+func (o *ProfileOverride) matches(kind, namespace string) bool {
+	if len(o.Kinds) > 0 && !contains(o.Kinds, kind) {
+		return false
+	}
+	if len(o.Namespaces) == 0 {
+		return true
+	}
+	for _, pattern := range o.Namespaces {
+		if ok, _ := path.Match(pattern, namespace); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
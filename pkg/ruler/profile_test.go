@@ -0,0 +1,228 @@
+package ruler
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+const profilesConfig = `
+profiles:
+  - name: strict
+    threshold: 3
+    disable: [HostAliases]
+    points:
+      HostNetwork: -20
+    overrides:
+      - kinds: [DaemonSet]
+        namespaces: [kube-*]
+        disable: [HostNetwork]
+        threshold: 0
+`
+
+func TestRuleset_Profile(t *testing.T) {
+	config, err := LoadConfig(writeRulesFile(t, t.TempDir(), "kubesec.yaml", profilesConfig))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	tests := []struct {
+		name, kind, namespace string
+		expectedScore         int
+		expectedThreshold     int
+		expectedMessage       string
+	}{
+		{
+			name:              "profile points and threshold",
+			kind:              "DaemonSet",
+			namespace:         "default",
+			expectedScore:     -17,
+			expectedThreshold: 3,
+			expectedMessage:   "Failed with a score of -17 points",
+		},
+		{
+			name:              "override for kube-system DaemonSets",
+			kind:              "DaemonSet",
+			namespace:         "kube-system",
+			expectedScore:     3,
+			expectedThreshold: 0,
+			expectedMessage:   "Passed with a score of 3 points",
+		},
+		{
+			name:              "override does not apply to other kinds",
+			kind:              "Deployment",
+			namespace:         "kube-system",
+			expectedScore:     -17,
+			expectedThreshold: 3,
+			expectedMessage:   "Failed with a score of -17 points",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := `
+apiVersion: apps/v1
+kind: ` + tt.kind + `
+metadata:
+  name: cni
+  namespace: ` + tt.namespace + `
+spec:
+  template:
+    spec:
+      hostNetwork: true
+      hostAliases:
+        - ip: 127.0.0.1
+          hostnames: [foo.local]
+      serviceAccountName: cni
+      containers:
+        - name: c1
+`
+			schemaConfig := NewDefaultSchemaConfig()
+			schemaConfig.DisableValidation = true
+
+			ruleset, err := NewRulesetWithConfig(zap.NewNop().Sugar(),
+				RulesetConfig{Profiles: config.Profiles, Profile: "strict"},
+				"HostNetwork", "HostAliases", "ServiceAccountName")
+			if err != nil {
+				t.Fatal(err.Error())
+			}
+			reports, err := ruleset.Run("kube.yaml", []byte(data), schemaConfig)
+			if err != nil || len(reports) == 0 {
+				t.Fatal(err)
+			}
+
+			report := reports[0]
+			if report.Profile != "strict" {
+				t.Errorf("Got profile %q wanted %q", report.Profile, "strict")
+			}
+			if report.Score != tt.expectedScore {
+				t.Errorf("Got score %v wanted %v", report.Score, tt.expectedScore)
+			}
+			if report.Threshold == nil || *report.Threshold != tt.expectedThreshold {
+				t.Errorf("Got threshold %v wanted %v", report.Threshold, tt.expectedThreshold)
+			}
+			if report.Message != tt.expectedMessage {
+				t.Errorf("Got message %q wanted %q", report.Message, tt.expectedMessage)
+			}
+		})
+	}
+}
+
+func TestRuleset_Profile_InvalidDocuments(t *testing.T) {
+	config, err := LoadConfig(writeRulesFile(t, t.TempDir(), "kubesec.yaml", profilesConfig))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	// the schema of DaemonSets requires a field no manifest sets
+	schemas := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(schemas, "master-standalone-strict"), 0755); err != nil {
+		t.Fatal(err.Error())
+	}
+	writeRulesFile(t, filepath.Join(schemas, "master-standalone-strict"), "daemonset-apps-v1.json",
+		`{"type": "object", "required": ["unset"]}`)
+
+	schemaConfig := NewDefaultSchemaConfig()
+	schemaConfig.Locations = []string{schemas}
+
+	data := `
+apiVersion: apps/v1
+kind: DaemonSet
+metadata:
+  name: cni
+  namespace: kube-system
+---
+kind: Pod
+metadata: [
+`
+	ruleset, err := NewRulesetWithConfig(zap.NewNop().Sugar(),
+		RulesetConfig{Profiles: config.Profiles, Profile: "strict"}, "HostNetwork")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	reports, err := ruleset.Run("kube.yaml", []byte(data), schemaConfig)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if len(reports) != 2 {
+		t.Fatalf("Got %v reports wanted %v", len(reports), 2)
+	}
+
+	// the schema invalid DaemonSet gets the threshold of its override, the
+	// unparsed document the threshold of the profile
+	for i, expectedThreshold := range []int{0, 3} {
+		report := reports[i]
+		if report.Valid || (i == 0 && len(report.SchemaErrors) == 0) || (i == 1 && report.Error == nil) {
+			t.Fatalf("Got report %d %+v wanted an invalid report", i, report)
+		}
+		if report.Profile != "strict" {
+			t.Errorf("Got profile %q for report %d wanted %q", report.Profile, i, "strict")
+		}
+		if report.Threshold == nil || *report.Threshold != expectedThreshold {
+			t.Errorf("Got threshold %v for report %d wanted %v", report.Threshold, i, expectedThreshold)
+		}
+	}
+}
+
+func TestRuleset_Profile_Invalid(t *testing.T) {
+	tests := []struct {
+		name, content, profile, expectedError string
+	}{
+		{
+			name:          "unknown profile",
+			content:       profilesConfig,
+			profile:       "relaxed",
+			expectedError: "unknown profile relaxed",
+		},
+		{
+			name: "unknown rule",
+			content: `
+profiles:
+  - name: strict
+    disable: [HostNetwrok]
+`,
+			profile:       "strict",
+			expectedError: "profile strict: unknown rule ID HostNetwrok",
+		},
+		{
+			name: "unknown override kind",
+			content: `
+profiles:
+  - name: strict
+    overrides:
+      - kinds: [Daemonset]
+        disable: [HostNetwork]
+`,
+			profile:       "strict",
+			expectedError: `profile strict: unknown kind "Daemonset"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config, err := LoadConfig(writeRulesFile(t, t.TempDir(), "kubesec.yaml", tt.content))
+			if err != nil {
+				t.Fatal(err.Error())
+			}
+			_, err = NewRulesetWithConfig(zap.NewNop().Sugar(), RulesetConfig{Profiles: config.Profiles, Profile: tt.profile})
+			if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+				t.Errorf("Got error %v, expected: %v", err, tt.expectedError)
+			}
+		})
+	}
+}
+
+func TestLoadConfig_DuplicateProfile(t *testing.T) {
+	content := `
+profiles:
+  - name: strict
+  - name: strict
+`
+	_, err := LoadConfig(writeRulesFile(t, t.TempDir(), "kubesec.yaml", content))
+	if err == nil || !strings.Contains(err.Error(), "duplicate profile strict") {
+		t.Errorf("Got error %v, expected duplicate profile", err)
+	}
+}
@@ -0,0 +1,94 @@
+package ruler
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+)
+
+// registry holds the rules NewRuleset draws from. The built-in rules are
+// registered at init, third-party modules can add their own, e.g. from the
+// init function of their package.
+var registry = struct {
+	sync.RWMutex
+	rules map[string]Rule
+	// order keeps rules listed in registration order
+	order []string
+}{
+	rules: make(map[string]Rule),
+}
+
+// RegisterRule validates the rule and adds it to the registry. It fails if a
+// rule with the same ID is already registered.
+func RegisterRule(rule Rule) error {
+	if err := rule.Validate(); err != nil {
+		return err
+	}
+
+	registry.Lock()
+	defer registry.Unlock()
+
+	if _, exists := registry.rules[rule.ID]; exists {
+		return fmt.Errorf("duplicate rule ID %s", rule.ID)
+	}
+	registry.rules[rule.ID] = cloneRule(rule)
+	registry.order = append(registry.order, rule.ID)
+
+	return nil
+}
+
+// MustRegisterRule registers the rules and panics if any of them is invalid
+func MustRegisterRule(rules ...Rule) {
+	for _, rule := range rules {
+		if err := RegisterRule(rule); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// UnregisterRule removes a rule from the registry and reports whether it was registered
+func UnregisterRule(id string) bool {
+	registry.Lock()
+	defer registry.Unlock()
+
+	if _, exists := registry.rules[id]; !exists {
+		return false
+	}
+	delete(registry.rules, id)
+	for i, registered := range registry.order {
+		if registered == id {
+			registry.order = append(registry.order[:i], registry.order[i+1:]...)
+			break
+		}
+	}
+
+	return true
+}
+
+// ListRules returns the registered rules in registration order
+func ListRules() []Rule {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	rules := make([]Rule, 0, len(registry.order))
+	for _, id := range registry.order {
+		rules = append(rules, cloneRule(registry.rules[id]))
+	}
+	return rules
+}
+
+// LookupRule returns the registered rule with the given ID
+func LookupRule(id string) (Rule, bool) {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	rule, exists := registry.rules[id]
+	return cloneRule(rule), exists
+}
+
+// cloneRule copies the kinds of a rule, so changing the kinds of a listed rule
+// doesn't change the registered rules
+func cloneRule(rule Rule) Rule {
+	rule.Kinds = slices.Clone(rule.Kinds)
+	return rule
+}
@@ -0,0 +1,111 @@
+package ruler
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/controlplaneio/kubesec/v2/pkg/rules"
+	"go.uber.org/zap"
+)
+
+func TestRegistry(t *testing.T) {
+	rule := Rule{
+		Predicate: func([]byte) rules.Result { return rules.Result{Count: 1} },
+		ID:        "OrgOwnerAnnotation",
+		Reason:    "Workloads must be annotated with their owner",
+		Kinds:     []string{"Deployment"},
+		Points:    1,
+		Source:    "github.com/example/kubesec-rules",
+	}
+
+	if err := RegisterRule(rule); err != nil {
+		t.Fatal(err.Error())
+	}
+	defer UnregisterRule(rule.ID)
+
+	if err := RegisterRule(rule); err == nil || !strings.Contains(err.Error(), "duplicate rule ID") {
+		t.Errorf("Got error %v, expected duplicate rule ID", err)
+	}
+
+	registered, ok := LookupRule(rule.ID)
+	if !ok || registered.Source != rule.Source {
+		t.Errorf("Lookup of %s failed", rule.ID)
+	}
+
+	rules := ListRules()
+	if rules[len(rules)-1].ID != rule.ID {
+		t.Errorf("Got last rule %s, wanted %s", rules[len(rules)-1].ID, rule.ID)
+	}
+
+	ruleset, err := NewRuleset(zap.NewNop().Sugar(), rule.ID)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if len(ruleset.Rules) != 1 {
+		t.Errorf("Got %v rules wanted %v", len(ruleset.Rules), 1)
+	}
+
+	if !UnregisterRule(rule.ID) {
+		t.Errorf("Unregister of %s failed", rule.ID)
+	}
+	if UnregisterRule(rule.ID) {
+		t.Errorf("Unregister of %s succeeded twice", rule.ID)
+	}
+	if _, ok := LookupRule(rule.ID); ok {
+		t.Errorf("Lookup of unregistered rule %s succeeded", rule.ID)
+	}
+	if _, err := NewRuleset(zap.NewNop().Sugar(), rule.ID); err == nil {
+		t.Errorf("Ruleset with unregistered rule %s succeeded", rule.ID)
+	}
+}
+
+func TestRegistry_Kinds(t *testing.T) {
+	hostNetwork, _ := LookupRule("HostNetwork")
+	hostNetwork.Kinds[0] = "Service"
+	hostNetwork.Kinds = append(hostNetwork.Kinds[:1], "Secret")
+
+	// the built-in rules don't share their kinds, neither with each other nor
+	// with the rules returned by the registry
+	for _, id := range []string{"HostNetwork", "HostPID"} {
+		rule, _ := LookupRule(id)
+		if len(rule.Kinds) != len(podSpecKinds) || rule.Kinds[0] != "Pod" || rule.Kinds[1] != "PodTemplate" {
+			t.Errorf("Got kinds %v for %s wanted %v", rule.Kinds, id, podSpecKinds)
+		}
+	}
+	if podSpecKinds[0] != "Pod" {
+		t.Errorf("Got pod spec kinds %v", podSpecKinds)
+	}
+}
+
+func TestRegisterRule_Invalid(t *testing.T) {
+	tests := []struct {
+		name          string
+		rule          Rule
+		expectedError string
+	}{
+		{
+			name:          "invalid ID",
+			rule:          Rule{ID: "Org Rule", Kinds: []string{"Pod"}, Predicate: func([]byte) rules.Result { return rules.Result{Count: 0} }},
+			expectedError: "invalid rule ID",
+		},
+		{
+			name:          "unknown kind",
+			rule:          Rule{ID: "OrgRule", Kinds: []string{"Pods"}, Predicate: func([]byte) rules.Result { return rules.Result{Count: 0} }},
+			expectedError: `unknown kind "Pods"`,
+		},
+		{
+			name:          "missing predicate",
+			rule:          Rule{ID: "OrgRule", Kinds: []string{"Pod"}},
+			expectedError: "predicate is required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := RegisterRule(tt.rule)
+			if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+				t.Errorf("Got error %v, expected: %v", err, tt.expectedError)
+			}
+		})
+	}
+}
@@ -1,5 +1,10 @@
 package ruler
 
+import (
+	"github.com/controlplaneio/kubesec/v2/pkg/pss"
+	"github.com/controlplaneio/kubesec/v2/pkg/rules"
+)
+
 type Reports []Report
 
 type Report struct {
@@ -10,22 +15,81 @@ type Report struct {
 	Message  string      `json:"message,omitempty"`
 	Score    int         `json:"score"`
 	Scoring  RuleScoring `json:"scoring,omitempty"`
+	// Profile is the name of the policy profile the object was scanned with
+	Profile string `json:"profile,omitempty"`
+	// Threshold is the minimum score to pass set by the profile
+	Threshold *int `json:"threshold,omitempty"`
+	// PodSecurity is the outcome of the Pod Security Standards controls
+	PodSecurity *pss.Result `json:"podSecurity,omitempty"`
+	// SchemaErrors are the schema validation errors of an invalid object
+	SchemaErrors []SchemaError `json:"schemaErrors,omitempty"`
+	// DocumentIndex is the index of the document of the object in its file,
+	// from 0
+	DocumentIndex int `json:"documentIndex"`
+	// ItemIndex is the index of the object in a List or a JSON array, or of
+	// its line in a newline-delimited JSON stream
+	ItemIndex *int `json:"itemIndex,omitempty"`
+	// Error is set on the invalid reports of the documents which could not
+	// be scanned
+	Error *ReportError `json:"error,omitempty"`
+	// Identity holds the fields identifying the object, Object is their
+	// summary kept for compatibility
+	Identity Identity `json:"identity"`
+	// Location is the start of the object in its file, if known
+	Location *rules.Location `json:"location,omitempty"`
+}
+
+const (
+	// ErrorReasonParse is the reason of the errors of documents which are
+	// not valid YAML or JSON
+	ErrorReasonParse = "ParseError"
+	// ErrorReasonRead is the reason of the errors of files which cannot be read
+	ErrorReasonRead = "ReadError"
+	// ErrorReasonInvalidInput is the reason of the errors of files holding no
+	// document
+	ErrorReasonInvalidInput = "InvalidInput"
+)
+
+// ReportError is the error of a document which could not be scanned
+type ReportError struct {
+	Reason  string `json:"reason"`
+	Message string `json:"message"`
+	// Location is the start of the document
+	Location *rules.Location `json:"location,omitempty"`
+}
+
+// SchemaError is an error of the schema validation of an object
+type SchemaError struct {
+	// Path is the JSON pointer of the invalid field, e.g. /spec/replicas
+	Path     string          `json:"path"`
+	Message  string          `json:"message"`
+	Location *rules.Location `json:"location,omitempty"`
 }
 
 type RuleScoring struct {
 	Critical []RuleRef `json:"critical,omitempty"`
 	Passed   []RuleRef `json:"passed,omitempty"`
 	Advise   []RuleRef `json:"advise,omitempty"`
+	// Suppressed holds the failed rules which were waived, they do not
+	// count towards the score
+	Suppressed []RuleRef `json:"suppressed,omitempty"`
 }
 
 type RuleRef struct {
-	ID         string `json:"id"`
-	Selector   string `json:"selector"`
-	Reason     string `json:"reason"`
-	Weight     int    `json:"weight,omitempty"`
-	Link       string `json:"href,omitempty"`
-	Containers int    `json:"-"`
-	Points     int    `json:"points"`
+	ID         string   `json:"id"`
+	Selector   string   `json:"selector"`
+	Reason     string   `json:"reason"`
+	Weight     int      `json:"weight,omitempty"`
+	Link       string   `json:"href,omitempty"`
+	Containers int      `json:"-"`
+	Points     int      `json:"points"`
+	Severity   Severity `json:"severity,omitempty"`
+	Evidence   []string `json:"evidence,omitempty"`
+	// Findings are the fields of the object the rule matched
+	Findings   []rules.Finding `json:"findings,omitempty"`
+	Compliance []Compliance    `json:"compliance,omitempty"`
+	// Suppression is set on the rules of the suppressed section
+	Suppression *Suppression `json:"suppression,omitempty"`
 }
 
 // This implements a custom sort interface (Len, Swap, Less) for the report listing.
@@ -3,7 +3,9 @@ package ruler
 import (
 	"bytes"
 	"fmt"
+	"regexp"
 
+	"github.com/controlplaneio/kubesec/v2/pkg/rules"
 	"github.com/thedevsaddam/gojsonq/v2"
 )
 
@@ -15,38 +17,116 @@ func (e *NotSupportedError) Error() string {
 	return fmt.Sprintf("rule does not apply to kind %s", e.Kind)
 }
 
+// EvaluationError is returned by the rules which failed to evaluate an
+// object, the rule is skipped for that object
+type EvaluationError struct {
+	Err error
+}
+
+func (e *EvaluationError) Error() string {
+	return fmt.Sprintf("evaluation failed: %s", e.Err)
+}
+
+func (e *EvaluationError) Unwrap() error {
+	return e.Err
+}
+
 type Rule struct {
-	ID        string           `json:"id" yaml:"id"`
-	Selector  string           `json:"selector" yaml:"selector"`
-	Reason    string           `json:"reason" yaml:"reason"`
-	Link      string           `json:"link,omitempty" yaml:"link,omitempty"`
-	Kinds     []string         `json:"kinds" yaml:"kinds"`
-	Points    int              `json:"points" yaml:"points"`
-	Advise    int              `json:"advise" yaml:"advise"`
-	Predicate func([]byte) int `json:"-" yaml:"-"`
+	ID       string   `json:"id" yaml:"id"`
+	Selector string   `json:"selector" yaml:"selector"`
+	Reason   string   `json:"reason" yaml:"reason"`
+	Link     string   `json:"link,omitempty" yaml:"link,omitempty"`
+	Kinds    []string `json:"kinds" yaml:"kinds"`
+	Points   int      `json:"points" yaml:"points"`
+	Advise   int      `json:"advise" yaml:"advise"`
+	// Severity defaults to a severity derived from the points
+	Severity Severity `json:"severity,omitempty" yaml:"severity,omitempty"`
+	Source   string   `json:"source,omitempty" yaml:"source,omitempty"`
+	// Compliance references the controls of compliance frameworks the rule maps to
+	Compliance []Compliance    `json:"compliance,omitempty" yaml:"compliance,omitempty"`
+	Predicate  rules.Predicate `json:"-" yaml:"-"`
+	// Evaluator is used instead of Predicate by rules whose evaluation can
+	// fail or return more than a match count, e.g. plugin rules.
+	Evaluator func([]byte) (Evaluation, error) `json:"-" yaml:"-"`
+}
+
+// Evaluation is the outcome of a rule evaluated by an Evaluator
+type Evaluation struct {
+	// Count is the number of matches, like the return value of a Predicate.
+	Count int
+	// Reason optionally replaces the reason of the rule in the report.
+	Reason string
+	// Evidence optionally describes what was matched.
+	Evidence []string
+	// Findings are the fields matched, like the findings of a Predicate.
+	Findings []rules.Finding
+}
+
+var ruleIDPattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_-]*$`)
+
+// Validate checks the rule has a well-formed ID, known kinds and a predicate
+func (r *Rule) Validate() error {
+	if !ruleIDPattern.MatchString(r.ID) {
+		return fmt.Errorf("invalid rule ID %q: must start with a letter and contain only letters, digits, '-' or '_'", r.ID)
+	}
+	if len(r.Kinds) == 0 {
+		return fmt.Errorf("rule %s: at least one kind is required", r.ID)
+	}
+	for _, k := range r.Kinds {
+		if !IsKnownKind(k) {
+			return fmt.Errorf("rule %s: unknown kind %q", r.ID, k)
+		}
+	}
+	if r.Severity != "" {
+		if _, err := ParseSeverity(string(r.Severity)); err != nil {
+			return fmt.Errorf("rule %s: %w", r.ID, err)
+		}
+	}
+	if r.Predicate == nil && r.Evaluator == nil {
+		return fmt.Errorf("rule %s: predicate is required", r.ID)
+	}
+	return nil
 }
 
 // Eval executes the predicate if the kind matches the rule
 func (r *Rule) Eval(json []byte) (int, error) {
+	evaluation, err := r.evaluate(json)
+	return evaluation.Count, err
+}
+
+// evaluate executes the evaluator, or else the predicate, if the kind matches the rule
+func (r *Rule) evaluate(json []byte) (Evaluation, error) {
 	jq := gojsonq.New().Reader(bytes.NewReader(json)).From("kind")
 	if jq.Error() != nil {
-		return 0, jq.Error()
+		return Evaluation{}, jq.Error()
 	}
 
 	kind := fmt.Sprintf("%s", jq.Get())
 
+	// objects with a pod spec mapping are evaluated by the rules of pods
+	podSpec := rules.HasPodSpecMapping(json)
+
 	var match bool
 	for _, k := range r.Kinds {
-		if k == kind {
+		if k == kind || (podSpec && k == "Pod") {
 			match = true
 			break
 		}
 	}
 
-	if match {
-		count := r.Predicate(json)
-		return count, nil
-	} else {
-		return 0, &NotSupportedError{Kind: kind}
+	if !match {
+		return Evaluation{}, &NotSupportedError{Kind: kind}
+	}
+
+	if r.Evaluator != nil {
+		return r.Evaluator(json)
+	}
+
+	var evaluation Evaluation
+	for _, doc := range rules.SplitPodSpecs(json) {
+		res := r.Predicate(doc)
+		evaluation.Count += res.Count
+		evaluation.Findings = append(evaluation.Findings, res.Findings...)
 	}
+	return evaluation, nil
 }
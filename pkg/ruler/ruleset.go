@@ -5,9 +5,11 @@ import (
 	"crypto/sha256"
 	"encoding/json"
 	"fmt"
+	"io"
 	"reflect"
-	"runtime"
+	"regexp"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
 
@@ -16,12 +18,17 @@ import (
 	"github.com/thedevsaddam/gojsonq/v2"
 	"go.uber.org/zap"
 
+	"github.com/controlplaneio/kubesec/v2/pkg/pss"
 	"github.com/controlplaneio/kubesec/v2/pkg/rules"
 )
 
 type Ruleset struct {
-	Rules  []Rule
-	logger *zap.SugaredLogger
+	Rules            []Rule
+	Profile          *Profile
+	Exceptions       *Exceptions
+	PodSecurityLevel pss.Level
+	ExitPolicy       *ExitPolicy
+	logger           *zap.SugaredLogger
 }
 
 type InvalidInputError struct {
@@ -31,257 +38,99 @@ func (e *InvalidInputError) Error() string {
 	return "Invalid input"
 }
 
+// RulesetConfig holds the optional configuration of a Ruleset.
+type RulesetConfig struct {
+	// CustomRules are merged with the registered rules. Their IDs must not
+	// clash with a registered rule.
+	CustomRules []Rule
+
+	// Profiles are the policy profiles available for selection.
+	Profiles []Profile
+
+	// Profile is the name of the active profile, none if empty.
+	Profile string
+
+	// Exceptions accept findings of the objects they match, none if nil.
+	Exceptions *Exceptions
+
+	// PodSecurityLevel is the Pod Security Standards level objects are
+	// checked against, none if empty.
+	PodSecurityLevel pss.Level
+
+	// ExitPolicy decides whether the objects of a scan pass, none if nil.
+	ExitPolicy *ExitPolicy
+}
+
+// NewRuleset returns the registered rules, restricted to ruleIDs if any are given.
 func NewRuleset(logger *zap.SugaredLogger, ruleIDs ...string) (*Ruleset, error) {
-	allRules := []Rule{
-		{
-			Predicate: rules.HostNetwork,
-			ID:        "HostNetwork",
-			Selector:  ".spec .hostNetwork == true",
-			Reason:    "Sharing the host's network namespace permits processes in the pod to communicate with processes bound to the host's loopback adapter",
-			Kinds:     []string{"Pod", "Deployment", "StatefulSet", "DaemonSet"},
-			Points:    -9,
-		},
-		{
-			Predicate: rules.HostPID,
-			ID:        "HostPID",
-			Selector:  ".spec .hostPID == true",
-			Reason:    "Sharing the host's PID namespace allows visibility of processes on the host, potentially leaking information such as environment variables and configuration",
-			Kinds:     []string{"Pod", "Deployment", "StatefulSet", "DaemonSet"},
-			Points:    -9,
-		},
-		{
-			Predicate: rules.HostIPC,
-			ID:        "HostIPC",
-			Selector:  ".spec .hostIPC == true",
-			Reason:    "Sharing the host's IPC namespace allows container processes to communicate with processes on the host",
-			Kinds:     []string{"Pod", "Deployment", "StatefulSet", "DaemonSet"},
-			Points:    -9,
-		},
-		{
-			Predicate: rules.ReadOnlyRootFilesystem,
-			ID:        "ReadOnlyRootFilesystem",
-			Selector:  "containers[] .securityContext .readOnlyRootFilesystem == true",
-			Reason:    "An immutable root filesystem can prevent malicious binaries being added to PATH and increase attack cost",
-			Kinds:     []string{"Pod", "Deployment", "StatefulSet", "DaemonSet"},
-			Points:    1,
-			Advise:    3,
-		},
-		{
-			Predicate: rules.RunAsNonRoot,
-			ID:        "RunAsNonRoot",
-			Selector:  ".spec, .spec.containers[] | .securityContext .runAsNonRoot == true",
-			Reason:    "Force the running image to run as a non-root user to ensure least privilege",
-			Kinds:     []string{"Pod", "Deployment", "StatefulSet", "DaemonSet"},
-			Points:    1,
-			Advise:    10,
-		},
-		{
-			Predicate: rules.RunAsUser,
-			ID:        "RunAsUser",
-			Selector:  ".spec, .spec.containers[] | .securityContext .runAsUser -gt 10000",
-			Reason:    "Run as a high-UID user to avoid conflicts with the host's users",
-			Kinds:     []string{"Pod", "Deployment", "StatefulSet", "DaemonSet"},
-			Points:    1,
-			Advise:    4,
-		},
-		{
-			Predicate: rules.RunAsGroup,
-			ID:        "RunAsGroup",
-			Selector:  ".spec, .spec.containers[] | .securityContext .runAsGroup -gt 10000",
-			Reason:    "Run as a high-UID group to avoid conflicts with the host's groups",
-			Kinds:     []string{"Pod", "Deployment", "StatefulSet", "DaemonSet"},
-			Points:    1,
-			Advise:    4,
-		},
-		{
-			Predicate: rules.Privileged,
-			ID:        "Privileged",
-			Selector:  "containers[] .securityContext .privileged == true",
-			Reason:    "Privileged containers can allow almost completely unrestricted host access",
-			Kinds:     []string{"Pod", "Deployment", "StatefulSet", "DaemonSet"},
-			Points:    -30,
-		},
-		{
-			Predicate: rules.CapSysAdmin,
-			ID:        "CapSysAdmin",
-			Selector:  "containers[] .securityContext .capabilities .add == SYS_ADMIN",
-			Reason:    "CAP_SYS_ADMIN is the most privileged capability and should always be avoided",
-			Kinds:     []string{"Pod", "Deployment", "StatefulSet", "DaemonSet"},
-			Points:    -30,
-		},
-		{
-			Predicate: rules.CapDropAny,
-			ID:        "CapDropAny",
-			Selector:  "containers[] .securityContext .capabilities .drop",
-			Reason:    "Reducing kernel capabilities available to a container limits its attack surface",
-			Kinds:     []string{"Pod", "Deployment", "StatefulSet", "DaemonSet"},
-			Points:    1,
-		},
-		{
-			Predicate: rules.CapDropAll,
-			ID:        "CapDropAll",
-			Selector:  "containers[] .securityContext .capabilities .drop | index(\"ALL\")",
-			Reason:    "Drop all capabilities and add only those required to reduce syscall attack surface",
-			Kinds:     []string{"Pod", "Deployment", "StatefulSet", "DaemonSet"},
-			Points:    1,
-		},
-		{
-			Predicate: rules.DockerSock,
-			ID:        "DockerSock",
-			Selector:  "volumes[] .hostPath .path == /var/run/docker.sock",
-			Reason:    "Mounting the docker.socket leaks information about other containers and can allow container breakout",
-			Kinds:     []string{"Pod", "Deployment", "StatefulSet", "DaemonSet"},
-			Points:    -9,
-		},
-		{
-			Predicate: rules.ProcMount,
-			ID:        "ProcMount",
-			Selector:  "volumes[] .hostPath .path == /proc",
-			Reason:    "Mounting the proc directory from the host system into a container gives access to information about other containers running on the same host and can allow container breakout",
-			Kinds:     []string{"Pod", "Deployment", "StatefulSet", "DaemonSet"},
-			Points:    -9,
-		},
-		{
-			Predicate: rules.RequestsCPU,
-			ID:        "RequestsCPU",
-			Selector:  "containers[] .resources .requests .cpu",
-			Reason:    "Enforcing CPU requests aids a fair balancing of resources across the cluster",
-			Kinds:     []string{"Pod", "Deployment", "StatefulSet", "DaemonSet"},
-			Points:    1,
-		},
-		{
-			Predicate: rules.LimitsCPU,
-			ID:        "LimitsCPU",
-			Selector:  "containers[] .resources .limits .cpu",
-			Reason:    "Enforcing CPU limits prevents DOS via resource exhaustion",
-			Kinds:     []string{"Pod", "Deployment", "StatefulSet", "DaemonSet"},
-			Points:    1,
-		},
-		{
-			Predicate: rules.RequestsMemory,
-			ID:        "RequestsMemory",
-			Selector:  "containers[] .resources .requests .memory",
-			Reason:    "Enforcing memory requests aids a fair balancing of resources across the cluster",
-			Kinds:     []string{"Pod", "Deployment", "StatefulSet", "DaemonSet"},
-			Points:    1,
-		},
-		{
-			Predicate: rules.LimitsMemory,
-			ID:        "LimitsMemory",
-			Selector:  "containers[] .resources .limits .memory",
-			Reason:    "Enforcing memory limits prevents DOS via resource exhaustion",
-			Kinds:     []string{"Pod", "Deployment", "StatefulSet", "DaemonSet"},
-			Points:    1,
-		},
-		{
-			Predicate: rules.ServiceAccountName,
-			ID:        "ServiceAccountName",
-			Selector:  ".spec .serviceAccountName",
-			Reason:    "Service accounts restrict Kubernetes API access and should be configured with least privilege",
-			Kinds:     []string{"Pod", "Deployment", "StatefulSet", "DaemonSet"},
-			Points:    3,
-		},
-		{
-			Predicate: rules.HostAliases,
-			ID:        "HostAliases",
-			Selector:  ".spec .hostAliases",
-			Reason:    "Managing /etc/hosts aliases can prevent the container from modifying the file after a pod's containers have already been started. DNS should be managed by the orchestrator",
-			Kinds:     []string{"Pod", "Deployment", "StatefulSet", "DaemonSet"},
-			Points:    -3,
-		},
-		{
-			Predicate: rules.SeccompAny,
-			ID:        "SeccompAny",
-			Selector:  ".spec .securityContext .seccompProfile .type | .spec .containers[] .securityContext .seccompProfile .type | .spec .initContainers[] .securityContext .seccompProfile .type | .spec .ephemeralContainers[] .securityContext .seccompProfile .type",
-			Reason:    "Seccomp profiles set minimum privilege and secure against unknown threats",
-			Kinds:     []string{"Pod", "Deployment", "StatefulSet", "DaemonSet"},
-			Points:    1,
-		},
-		{
-			Predicate: rules.SeccompUnconfined,
-			ID:        "SeccompUnconfined",
-			Selector:  ".spec .securityContext .seccompProfile .type | .spec .containers[] .securityContext .seccompProfile .type | .spec .initContainers[] .securityContext .seccompProfile .type | .spec .ephemeralContainers[] .securityContext .seccompProfile .type",
-			Reason:    "Unconfined Seccomp profiles have full system call access",
-			Kinds:     []string{"Pod", "Deployment", "StatefulSet", "DaemonSet"},
-			Points:    -1,
-		},
-		{
-			Predicate: rules.ApparmorAny,
-			ID:        "ApparmorAny",
-			Selector:  ".spec .securityContext .appArmorProfile .type | .spec .containers[] .securityContext .appArmorProfile .type | .spec .initContainers[] .securityContext .appArmorProfile .type | .spec .ephemeralContainers[] .securityContext .appArmorProfile .type",
-			Reason:    "Well defined AppArmor policies may provide greater protection from unknown threats.",
-			Kinds:     []string{"Pod", "Deployment", "StatefulSet", "DaemonSet"},
-			Points:    3,
-		},
-		{
-			Predicate: rules.ApparmorUnconfined,
-			ID:        "ApparmorUnconfined",
-			Selector:  ".spec .securityContext .appArmorProfile .type | .spec .containers[] .securityContext .appArmorProfile .type | .spec .initContainers[] .securityContext .appArmorProfile .type | .spec .ephemeralContainers[] .securityContext .appArmorProfile .type",
-			Reason:    "Unconfined AppArmor profiles disable AppArmor enforcement on the workloads",
-			Kinds:     []string{"Pod", "Deployment", "StatefulSet", "DaemonSet"},
-			Points:    -1,
-		},
-		{
-			Predicate: rules.VolumeClaimAccessModeReadWriteOnce,
-			ID:        "VolumeClaimAccessModeReadWriteOnce",
-			Selector:  ".spec .volumeClaimTemplates[] .spec .accessModes | index(\"ReadWriteOnce\")",
-			Reason:    "Setting the access mode of ReadWriteOnce on volumeClaimTemplates (if any exist) allows only one node to mount the persistentVolume",
-			Kinds:     []string{"StatefulSet"},
-			Points:    1,
-		},
-		{
-			Predicate: rules.VolumeClaimRequestsStorage,
-			ID:        "VolumeClaimRequestsStorage",
-			Selector:  ".spec .volumeClaimTemplates[] .spec .resources .requests .storage",
-			Reason:    "Setting a storage request on volumeClaimTemplates (if any exist) allows for the StatefulSet's PVCs to be bound to appropriately sized PVs.",
-			Kinds:     []string{"StatefulSet"},
-			Points:    1,
-		},
-		{
-			Predicate: rules.AllowPrivilegeEscalation,
-			ID:        "AllowPrivilegeEscalation",
-			Selector:  "containers[] .securityContext .allowPrivilegeEscalation == true",
-			Reason:    "Ensure a non-root process can not gain more privileges",
-			Kinds:     []string{"Pod", "Deployment", "StatefulSet", "DaemonSet"},
-			Points:    -7,
-		},
-		{
-			Predicate: rules.AutomountServiceAccountToken,
-			ID:        "AutomountServiceAccountToken",
-			Selector:  ".spec .automountServiceAccountToken == false",
-			Reason:    "Disabling the automounting of Service Account Token reduces the attack surface of the API server",
-			Kinds:     []string{"Pod", "Deployment", "StatefulSet", "DaemonSet"},
-			Points:    1,
-		},
-		{
-			Predicate: rules.HostUsers,
-			ID:        "HostUsers",
-			Selector:  ".spec .hostUsers == false",
-			Reason:    "A user namespace for a Pod is enabled by setting the hostUsers field of Pod .spec, which can prevent various attacks",
-			Kinds:     []string{"Pod", "Deployment", "StatefulSet", "DaemonSet"},
-			Points:    1,
-		},
-		{
-			Predicate: rules.SecretsAsEnvironmentVariables,
-			ID:        "SecretsAsEnvironmentVariables",
-			Selector:  ".spec .containers[] .env[] .valueFrom .secretKeyRef | .spec .initContainers[] .env[] .valueFrom .secretKeyRef | .spec .ephemeralContainers[] .env[] .valueFrom .secretKeyRef | .spec .containers[] .envFrom[] .secretRef | .spec .initContainers[] .envFrom[] .secretRef | .spec .ephemeralContainers[] .envFrom[] .secretRef",
-			Reason:    "Secrets passed as environment variables can be easily exposed through application logs, crash dumps, and system process inspection",
-			Kinds:     []string{"Pod", "Deployment", "StatefulSet", "DaemonSet", "ReplicaSet", "Job", "CronJob"},
-			Points:    -5,
-		},
-		{
-			Predicate: rules.BindingsToSystemAnonymous,
-			ID:        "BindingsToSystemAnonymous",
-			Selector:  ".subjects[] .name == \"system:anonymous\"",
-			Reason:    "Binding a Role or a ClusterRole to user system:anonymous gives any unauthenticated user the permissions granted by that role",
-			Kinds:     []string{"RoleBinding", "ClusterRoleBinding"},
-			Points:    -30,
-		},
+	return NewRulesetWithConfig(logger, RulesetConfig{}, ruleIDs...)
+}
+
+// NewRulesetWithConfig returns the registered rules merged with the custom rules
+// of the config, restricted to ruleIDs if any are given.
+func NewRulesetWithConfig(logger *zap.SugaredLogger, config RulesetConfig, ruleIDs ...string) (*Ruleset, error) {
+	allRules := ListRules()
+
+	ruleIDsSeen := make(map[string]bool, len(allRules)+len(config.CustomRules))
+	for _, rule := range allRules {
+		ruleIDsSeen[rule.ID] = true
+	}
+	for _, rule := range config.CustomRules {
+		if err := rule.Validate(); err != nil {
+			return nil, err
+		}
+		if ruleIDsSeen[rule.ID] {
+			return nil, fmt.Errorf("duplicate rule ID %s", rule.ID)
+		}
+		ruleIDsSeen[rule.ID] = true
+		allRules = append(allRules, rule)
+	}
+
+	// rules without a severity get the severity of their points
+	for i := range allRules {
+		if allRules[i].Severity == "" {
+			allRules[i].Severity = SeverityForPoints(allRules[i].Points)
+		}
+	}
+
+	var profile *Profile
+	if config.Profile != "" {
+		for i := range config.Profiles {
+			if config.Profiles[i].Name == config.Profile {
+				profile = &config.Profiles[i]
+				break
+			}
+		}
+		if profile == nil {
+			return nil, fmt.Errorf("unknown profile %s", config.Profile)
+		}
+		if err := profile.validate(func(id string) bool { return ruleIDsSeen[id] }); err != nil {
+			return nil, err
+		}
+	}
+
+	if config.Exceptions != nil {
+		if err := config.Exceptions.validate(func(id string) bool { return ruleIDsSeen[id] }); err != nil {
+			return nil, err
+		}
+	}
+
+	if config.ExitPolicy != nil {
+		if err := config.ExitPolicy.Validate(func(id string) bool { return ruleIDsSeen[id] }); err != nil {
+			return nil, err
+		}
 	}
 
 	// If no specific IDs were passed, return all rules.
 	if len(ruleIDs) == 0 {
-		return &Ruleset{Rules: allRules, logger: logger}, nil
+		return &Ruleset{
+			Rules:            allRules,
+			Profile:          profile,
+			Exceptions:       config.Exceptions,
+			PodSecurityLevel: config.PodSecurityLevel,
+			ExitPolicy:       config.ExitPolicy,
+			logger:           logger,
+		}, nil
 	}
 
 	// Map all available rules for validation and fast lookup
@@ -292,11 +141,29 @@ func NewRuleset(logger *zap.SugaredLogger, ruleIDs ...string) (*Ruleset, error)
 
 	var filteredRules []Rule
 	var invalidIDs []string
+	selected := make(map[string]bool)
 
-	// Validate requested rules
+	// Validate requested rules, severity:<level> selecting the rules at or above a severity
 	for _, id := range ruleIDs {
+		if strings.HasPrefix(id, SeveritySelectorPrefix) {
+			severity, err := ParseSeverity(strings.TrimPrefix(id, SeveritySelectorPrefix))
+			if err != nil {
+				return nil, err
+			}
+			for _, rule := range allRules {
+				if rule.Severity.AtLeast(severity) && !selected[rule.ID] {
+					selected[rule.ID] = true
+					filteredRules = append(filteredRules, rule)
+				}
+			}
+			continue
+		}
+
 		if rule, exists := availableRules[id]; exists {
-			filteredRules = append(filteredRules, rule)
+			if !selected[id] {
+				selected[id] = true
+				filteredRules = append(filteredRules, rule)
+			}
 		} else {
 			invalidIDs = append(invalidIDs, id)
 		}
@@ -308,8 +175,12 @@ func NewRuleset(logger *zap.SugaredLogger, ruleIDs ...string) (*Ruleset, error)
 	}
 
 	return &Ruleset{
-		Rules:  filteredRules,
-		logger: logger,
+		Rules:            filteredRules,
+		Profile:          profile,
+		Exceptions:       config.Exceptions,
+		PodSecurityLevel: config.PodSecurityLevel,
+		ExitPolicy:       config.ExitPolicy,
+		logger:           logger,
 	}, nil
 }
 
@@ -318,31 +189,59 @@ func (rs *Ruleset) Run(fileName string, fileBytes []byte, schemaConfig SchemaCon
 
 	isJSON := json.Valid(fileBytes)
 	if isJSON {
-		report := rs.generateReport(fileName, fileBytes, schemaConfig)
-		reports = append(reports, report)
+		src := newSource(fileName, 0, 1, fileBytes)
+		reports = append(reports, rs.generateReports(fileName, fileBytes, schemaConfig, src)...)
+	} else if lines, ok := splitJSONLines(fileBytes); ok {
+		// newline-delimited JSON is a stream of items
+		for i, l := range lines {
+			src := newSource(fileName, i, l.line, l.data)
+			for _, report := range rs.generateReports(fileName, l.data, schemaConfig, src) {
+				// the items of a List on a line are at the index of the line
+				index := i
+				report.ItemIndex = &index
+				report.DocumentIndex = i
+				reports = append(reports, report)
+			}
+		}
 	} else {
-		lineBreak := detectLineBreak(fileBytes)
-		bits := bytes.Split(fileBytes, []byte(lineBreak+"---"+lineBreak))
-		for i, d := range bits {
-			doc := bytes.TrimSpace(d)
-
-			// If empty or just a header
-			if len(doc) == 0 || (len(doc) == 3 && string(doc) == "---") {
-				// if we're at the end and there are no reports
-				if len(bits) == i+1 && len(reports) == 0 {
-					rs.logger.Debugf("empty and no records, erroring")
-					return nil, &InvalidInputError{}
-				}
-				rs.logger.Debugf("empty but still more docs, continuing")
-				continue
+		var documents int
+		dec := newDocumentDecoder(bytes.NewReader(fileBytes))
+		for {
+			d, err := dec.Decode()
+			if err == io.EOF {
+				break
 			}
-
-			data, err := yaml.YAMLToJSON(doc)
 			if err != nil {
 				return reports, err
 			}
-			report := rs.generateReport(fileName, data, schemaConfig)
-			reports = append(reports, report)
+
+			data, err := yaml.YAMLToJSON(d.data)
+			if err != nil {
+				// keep scanning the documents after an invalid one
+				rs.logger.Debugf("document at line %d is invalid: %v", d.line, err)
+				line, err := documentError(err, d.line)
+				report := NewErrorReport(fileName, documents, line, ErrorReasonParse, err)
+				rs.applyProfile(&report, "", "")
+				reports = append(reports, report)
+				documents++
+				continue
+			}
+			if isEmptyDocument(data) {
+				rs.logger.Debugf("empty document at line %d, continuing", d.line)
+				continue
+			}
+
+			src := newSource(fileName, documents, d.line, d.data)
+			for _, report := range rs.generateReports(fileName, data, schemaConfig, src) {
+				report.DocumentIndex = documents
+				reports = append(reports, report)
+			}
+			documents++
+		}
+
+		if documents == 0 {
+			rs.logger.Debugf("empty and no records, erroring")
+			return nil, &InvalidInputError{}
 		}
 	}
 
@@ -410,42 +309,156 @@ func containsRule(rules []RuleRef, newRule RuleRef) bool {
 	return false
 }
 
-func (rs *Ruleset) generateReport(fileName string, json []byte, schemaConfig SchemaConfig) Report {
+// yamlErrorLine matches the line of a YAML error, counted from the start of
+// the document
+var yamlErrorLine = regexp.MustCompile(`yaml: line (\d+):`)
+
+// documentError returns the line of the file a YAML error of the document
+// starting at the given line points at, the error being rewritten with that
+// line. Errors without a line point at the start of the document.
+func documentError(err error, start int) (int, error) {
+	message := err.Error()
+	m := yamlErrorLine.FindStringSubmatchIndex(message)
+	if m == nil {
+		return start, err
+	}
+	n, _ := strconv.Atoi(message[m[2]:m[3]])
+	line := start + n - 1
+	return line, fmt.Errorf("%syaml: line %d:%s", message[:m[0]], line, message[m[1]:])
+}
+
+// NewErrorReport returns the invalid report of a document which could not be
+// scanned
+func NewErrorReport(fileName string, index, line int, reason string, err error) Report {
+	return Report{
+		Object:        "Unknown",
+		FileName:      fileName,
+		DocumentIndex: index,
+		Message:       err.Error(),
+		Rules:         make([]RuleRef, 0),
+		Scoring: RuleScoring{
+			Advise:     make([]RuleRef, 0),
+			Passed:     make([]RuleRef, 0),
+			Critical:   make([]RuleRef, 0),
+			Suppressed: make([]RuleRef, 0),
+		},
+		Error: &ReportError{
+			Reason:   reason,
+			Message:  err.Error(),
+			Location: &rules.Location{File: fileName, DocumentIndex: index, Line: line, Column: 1},
+		},
+	}
+}
+
+// applyProfile records the profile and threshold of an object on its report
+// and returns its profile settings
+func (rs *Ruleset) applyProfile(report *Report, kind, namespace string) profileSettings {
+	if rs.Profile == nil {
+		return profileSettings{}
+	}
+	settings := rs.Profile.resolve(kind, namespace)
+	report.Profile = rs.Profile.Name
+	report.Threshold = settings.threshold
+	return settings
+}
+
+// generateReports reports on an object, or on each item of a list with the
+// index of the item
+func (rs *Ruleset) generateReports(fileName string, json []byte, schemaConfig SchemaConfig, src *source) []Report {
+	items, prefix, ok := listItems(json)
+	if !ok {
+		return []Report{rs.generateReport(fileName, json, schemaConfig, src)}
+	}
+
+	reports := make([]Report, 0, len(items))
+	for i, item := range items {
+		report := rs.generateReport(fileName, item, schemaConfig, src.item(prefix, i))
+		index := i
+		report.ItemIndex = &index
+		reports = append(reports, report)
+	}
+	return reports
+}
+
+func (rs *Ruleset) generateReport(fileName string, json []byte, schemaConfig SchemaConfig, src *source) Report {
 	report := Report{
 		Object:   getObjectName(json),
+		Identity: getIdentity(json),
 		FileName: fileName,
 		Score:    0,
 		Rules:    make([]RuleRef, 0),
 		Scoring: RuleScoring{
-			Advise:   make([]RuleRef, 0),
-			Passed:   make([]RuleRef, 0),
-			Critical: make([]RuleRef, 0),
+			Advise:     make([]RuleRef, 0),
+			Passed:     make([]RuleRef, 0),
+			Critical:   make([]RuleRef, 0),
+			Suppressed: make([]RuleRef, 0),
 		},
-		Valid: true,
+		Valid:    true,
+		Location: src.locate(nil),
 	}
 
+	// invalid objects are reported with the profile they were scanned with
+	kind, namespace, _ := getObjectMeta(json)
+	settings := rs.applyProfile(&report, kind, namespace)
+
 	// validate resource with kubeconform
 	if !schemaConfig.DisableValidation {
+		// custom resources with a pod spec mapping are scanned without a schema
+		if rules.HasPodSpecMapping(json) {
+			schemaConfig.ValidatorOpts.IgnoreMissingSchemas = true
+		}
 		report = validateSchema(report, json, schemaConfig)
 		if report.Message != "" {
+			for i := range report.SchemaErrors {
+				report.SchemaErrors[i].Location = src.locatePointer(report.SchemaErrors[i].Path)
+			}
 			report.Valid = false
 			return report
 		}
 	}
 
 	// check kubesec rules
-	return rs.checkRules(report, json)
+	report = rs.checkRules(report, json, settings)
+	src.locateFindings(report.Scoring.Critical)
+	src.locateFindings(report.Scoring.Advise)
+	src.locateFindings(report.Scoring.Passed)
+	src.locateFindings(report.Scoring.Suppressed)
+
+	// check the Pod Security Standards
+	if rs.PodSecurityLevel != "" {
+		if result, ok := pss.Evaluate(json, rs.PodSecurityLevel); ok {
+			report.PodSecurity = result
+		}
+	}
+
+	return report
 }
 
 // checkRules checks the resource against the kubesec rules
 // and updates the provided Report.
-func (rs *Ruleset) checkRules(report Report, json []byte) Report {
+func (rs *Ruleset) checkRules(report Report, json []byte, settings profileSettings) Report {
+	kind, namespace, name := getObjectMeta(json)
+
+	suppressions, ok := getAnnotationSuppressions(json)
+	if !ok {
+		rs.logger.Warnf("ignore annotations of %s are not honoured without a %s annotation",
+			report.Object, AnnotationIgnoreJustification)
+	}
+
 	// run rules in parallel
 	ch := make(chan RuleRef, len(rs.Rules))
+	evaluated := make(map[string]Rule, len(rs.Rules))
 	var wg sync.WaitGroup
 	for _, rule := range rs.Rules {
+		if settings.disabled[rule.ID] {
+			continue
+		}
+		if points, ok := settings.points[rule.ID]; ok {
+			rule.Points = points
+		}
+		evaluated[rule.ID] = rule
 		wg.Add(1)
-		go eval(json, rule, ch, &wg)
+		go rs.eval(json, rule, ch, &wg)
 	}
 	wg.Wait()
 	close(ch)
@@ -455,6 +468,34 @@ func (rs *Ruleset) checkRules(report Report, json []byte) Report {
 	for ruleRef := range ch {
 		appliedRules++
 
+		// failed rules can be suppressed, they are then left out of the score
+		if ruleRef.failed() {
+			suppression := suppressions.suppress(evaluated[ruleRef.ID], ruleRef, json)
+			// exceptions are matched even when an annotation suppresses the
+			// finding, so they are not reported as unused
+			if rs.Exceptions != nil {
+				exception := rs.Exceptions.suppress(exceptionTarget{
+					ruleID:    ruleRef.ID,
+					kind:      kind,
+					namespace: namespace,
+					name:      name,
+					fileName:  report.FileName,
+				})
+				if suppression == nil {
+					suppression = exception
+				}
+			}
+			if suppression != nil && !suppression.Expired {
+				rs.logger.Debugf("rule %v suppressed by %v", ruleRef.ID, suppression.Source)
+				ruleRef.Suppression = suppression
+				report.Rules = appendUniqueRule(report.Rules, ruleRef)
+				report.Scoring.Suppressed = append(report.Scoring.Suppressed, ruleRef)
+				continue
+			}
+			// an expired exception is reported along with the failure
+			ruleRef.Suppression = suppression
+		}
+
 		report.Rules = appendUniqueRule(report.Rules, ruleRef)
 
 		if ruleRef.Containers > 0 {
@@ -475,9 +516,14 @@ func (rs *Ruleset) checkRules(report Report, json []byte) Report {
 		}
 	}
 
+	threshold := 0
+	if report.Threshold != nil {
+		threshold = *report.Threshold
+	}
+
 	if appliedRules < 1 {
 		report.Message = "This resource kind is not supported by kubesec"
-	} else if report.Score >= 0 {
+	} else if report.Score >= threshold {
 		report.Message = fmt.Sprintf("Passed with a score of %v points", report.Score)
 	} else {
 		report.Message = fmt.Sprintf("Failed with a score of %v points", report.Score)
@@ -487,28 +533,47 @@ func (rs *Ruleset) checkRules(report Report, json []byte) Report {
 	sort.Sort(RuleRefCustomOrder(report.Scoring.Critical))
 	sort.Sort(RuleRefCustomOrder(report.Scoring.Passed))
 	sort.Sort(RuleRefCustomOrder(report.Scoring.Advise))
+	sort.Sort(RuleRefCustomOrder(report.Scoring.Suppressed))
 
 	return report
 }
 
-func eval(json []byte, rule Rule, ch chan RuleRef, wg *sync.WaitGroup) {
+func (rs *Ruleset) eval(json []byte, rule Rule, ch chan RuleRef, wg *sync.WaitGroup) {
 	defer wg.Done()
 
-	containers, err := rule.Eval(json)
+	evaluation, err := rule.evaluate(json)
 
-	// skip rule if it doesn't apply to object kind
 	switch err.(type) {
+	// skip rule if it doesn't apply to object kind
 	case *NotSupportedError:
 		return
+	// skip rule if its plugin failed, the scan carries on without it
+	case *PluginError:
+		rs.logger.Warnf("skipping rule %s: %v", rule.ID, err)
+		return
+	// skip rule if it can't evaluate the object, e.g. a CEL expression
+	// exceeding its cost limit
+	case *EvaluationError:
+		rs.logger.Warnf("skipping rule %s: %v", rule.ID, err)
+		return
+	}
+
+	reason := rule.Reason
+	if evaluation.Reason != "" {
+		reason = evaluation.Reason
 	}
 
 	result := RuleRef{
-		Containers: containers,
+		Containers: evaluation.Count,
 		ID:         rule.ID,
 		Points:     rule.Points,
-		Reason:     rule.Reason,
+		Severity:   rule.Severity,
+		Reason:     reason,
 		Selector:   rule.Selector,
 		Link:       rule.Link,
+		Evidence:   evaluation.Evidence,
+		Findings:   evaluation.Findings,
+		Compliance: rule.Compliance,
 	}
 
 	ch <- result
@@ -544,10 +609,28 @@ func getObjectName(json []byte) string {
 	return object
 }
 
-func detectLineBreak(haystack []byte) string {
-	windowsLineEnding := bytes.Contains(haystack, []byte("\r\n"))
-	if windowsLineEnding && runtime.GOOS == "windows" {
-		return "\r\n"
+// getObjectMeta returns the kind, namespace and name of an object,
+// empty if unset
+func getObjectMeta(json []byte) (kind, namespace, name string) {
+	jq := gojsonq.New().Reader(bytes.NewReader(json))
+	if len(jq.Errors()) > 0 {
+		return "", "", ""
+	}
+
+	if v, ok := jq.Copy().From("kind").Get().(string); ok {
+		kind = v
 	}
-	return "\n"
+	if v, ok := jq.Copy().From("metadata.namespace").Get().(string); ok {
+		namespace = v
+	}
+	if v, ok := jq.Copy().From("metadata.name").Get().(string); ok {
+		name = v
+	}
+	return kind, namespace, name
+}
+
+// isEmptyDocument reports whether the JSON of a YAML document holds no
+// content, e.g. a document with only comments
+func isEmptyDocument(json []byte) bool {
+	return len(bytes.TrimSpace(json)) == 0 || string(bytes.TrimSpace(json)) == "null"
 }
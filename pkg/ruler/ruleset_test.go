@@ -1,11 +1,16 @@
 package ruler
 
 import (
+	"reflect"
+	"sort"
 	"strings"
 	"testing"
 
 	"github.com/in-toto/in-toto-golang/in_toto"
 	"go.uber.org/zap"
+
+	"github.com/controlplaneio/kubesec/v2/pkg/pss"
+	"github.com/controlplaneio/kubesec/v2/pkg/rules"
 )
 
 func TestRuleset_Run(t *testing.T) {
@@ -499,3 +504,307 @@ spec:
 		})
 	}
 }
+
+func TestRuleset_Run_PodSecurity(t *testing.T) {
+	data := `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+spec:
+  template:
+    spec:
+      hostPID: true
+      containers:
+        - name: c1
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: app
+spec:
+  ports:
+    - port: 80
+`
+	config := NewDefaultSchemaConfig()
+	config.DisableValidation = true
+
+	ruleset, err := NewRulesetWithConfig(zap.NewNop().Sugar(), RulesetConfig{PodSecurityLevel: pss.LevelBaseline})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	reports, err := ruleset.Run("kube.yaml", []byte(data), config)
+	if err != nil || len(reports) != 2 {
+		t.Fatalf("Got %d reports and error %v, expected 2 reports", len(reports), err)
+	}
+
+	if reports[0].PodSecurity == nil || reports[0].PodSecurity.Allowed {
+		t.Errorf("Got pod security %v for the Deployment, expected a violation", reports[0].PodSecurity)
+	}
+	if reports[1].PodSecurity != nil {
+		t.Errorf("Got pod security %v for the Service, expected none", reports[1].PodSecurity)
+	}
+}
+
+func TestRuleset_Run_PodSpecKinds(t *testing.T) {
+	podSpec := `
+      hostPID: true
+      containers:
+        - name: c1
+          securityContext:
+            privileged: true
+`
+	// indent re-indents the pod spec, written at the depth of a Deployment
+	indent := func(depth int) string {
+		return strings.ReplaceAll(podSpec, "\n      ", "\n"+strings.Repeat(" ", depth))
+	}
+
+	tests := []struct {
+		kind string
+		data string
+	}{
+		{"Pod", "apiVersion: v1\nkind: Pod\nspec:" + indent(2)},
+		{"PodTemplate", "apiVersion: v1\nkind: PodTemplate\ntemplate:\n  spec:" + indent(4)},
+		{"Deployment", "apiVersion: apps/v1\nkind: Deployment\nspec:\n  template:\n    spec:" + indent(6)},
+		{"StatefulSet", "apiVersion: apps/v1\nkind: StatefulSet\nspec:\n  template:\n    spec:" + indent(6)},
+		{"DaemonSet", "apiVersion: apps/v1\nkind: DaemonSet\nspec:\n  template:\n    spec:" + indent(6)},
+		{"ReplicaSet", "apiVersion: apps/v1\nkind: ReplicaSet\nspec:\n  template:\n    spec:" + indent(6)},
+		{"ReplicationController", "apiVersion: v1\nkind: ReplicationController\nspec:\n  template:\n    spec:" + indent(6)},
+		{"Job", "apiVersion: batch/v1\nkind: Job\nspec:\n  template:\n    spec:" + indent(6)},
+		{"CronJob", "apiVersion: batch/v1\nkind: CronJob\nspec:\n  jobTemplate:\n    spec:\n      template:\n        spec:" + indent(10)},
+	}
+
+	config := NewDefaultSchemaConfig()
+	config.DisableValidation = true
+
+	ruleset, err := NewRuleset(zap.NewNop().Sugar())
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.kind, func(t *testing.T) {
+			reports, err := ruleset.Run("workload.yaml", []byte(tt.data), config)
+			if err != nil || len(reports) != 1 {
+				t.Fatalf("Got %d reports and error %v, expected 1 report", len(reports), err)
+			}
+
+			var critical []string
+			for _, ref := range reports[0].Scoring.Critical {
+				critical = append(critical, ref.ID)
+			}
+			sort.Strings(critical)
+			if wanted := []string{"HostPID", "Privileged"}; !reflect.DeepEqual(critical, wanted) {
+				t.Errorf("Got critical rules %v wanted %v", critical, wanted)
+			}
+			if len(reports[0].Scoring.Advise) == 0 {
+				t.Errorf("Got no advise, expected the positive rules to apply to %s", tt.kind)
+			}
+		})
+	}
+}
+
+func TestRuleset_Run_PodSpecMappings(t *testing.T) {
+	err := rules.RegisterPodSpecMappings(rules.PodSpecMapping{
+		APIVersion: "example.com/v1",
+		Kind:       "Worker",
+		Paths:      []string{"spec.template.spec", "spec.sidecarTemplate.spec"},
+	})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	tests := []struct {
+		name     string
+		data     string
+		critical []string
+	}{
+		{
+			name:     "Argo Rollout",
+			data:     "apiVersion: argoproj.io/v1alpha1\nkind: Rollout\nspec:\n  template:\n    spec:\n      hostPID: true\n      containers:\n        - name: c1\n          securityContext:\n            privileged: true\n",
+			critical: []string{"HostPID", "Privileged"},
+		},
+		{
+			name:     "Knative Service",
+			data:     "apiVersion: serving.knative.dev/v1\nkind: Service\nspec:\n  template:\n    spec:\n      containers:\n        - name: c1\n          securityContext:\n            privileged: true\n",
+			critical: []string{"Privileged"},
+		},
+		{
+			name:     "OpenShift DeploymentConfig",
+			data:     "apiVersion: apps.openshift.io/v1\nkind: DeploymentConfig\nspec:\n  template:\n    spec:\n      hostNetwork: true\n      containers:\n        - name: c1\n",
+			critical: []string{"HostNetwork"},
+		},
+		{
+			name:     "configured pod specs",
+			data:     "apiVersion: example.com/v1\nkind: Worker\nspec:\n  template:\n    spec:\n      containers:\n        - name: c1\n          securityContext:\n            privileged: true\n  sidecarTemplate:\n    spec:\n      hostIPC: true\n      containers:\n        - name: c2\n          securityContext:\n            privileged: true\n",
+			critical: []string{"HostIPC", "Privileged"},
+		},
+	}
+
+	config := NewDefaultSchemaConfig()
+	config.DisableValidation = true
+
+	ruleset, err := NewRuleset(zap.NewNop().Sugar())
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reports, err := ruleset.Run("workload.yaml", []byte(tt.data), config)
+			if err != nil || len(reports) != 1 {
+				t.Fatalf("Got %d reports and error %v, expected 1 report", len(reports), err)
+			}
+
+			var critical []string
+			for _, ref := range reports[0].Scoring.Critical {
+				critical = append(critical, ref.ID)
+			}
+			sort.Strings(critical)
+			if !reflect.DeepEqual(critical, tt.critical) {
+				t.Errorf("Got critical rules %v wanted %v", critical, tt.critical)
+			}
+		})
+	}
+
+	t.Run("findings of each pod spec", func(t *testing.T) {
+		reports, err := ruleset.Run("workload.yaml", []byte(tests[3].data), config)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		for _, ref := range reports[0].Scoring.Critical {
+			if ref.ID != "Privileged" {
+				continue
+			}
+			var paths []string
+			for _, f := range ref.Findings {
+				paths = append(paths, f.Path)
+			}
+			wanted := []string{
+				"spec.template.spec.containers[0].securityContext.privileged",
+				"spec.sidecarTemplate.spec.containers[0].securityContext.privileged",
+			}
+			if !reflect.DeepEqual(paths, wanted) {
+				t.Errorf("Got finding paths %v wanted %v", paths, wanted)
+			}
+		}
+	})
+
+	t.Run("core Service is not a workload", func(t *testing.T) {
+		reports, err := ruleset.Run("service.yaml", []byte("apiVersion: v1\nkind: Service\nspec:\n  template:\n    spec:\n      hostPID: true\n"), config)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		if len(reports[0].Scoring.Critical) != 0 {
+			t.Errorf("Got critical rules %v for a core Service", reports[0].Scoring.Critical)
+		}
+	})
+}
+
+func TestRuleset_Run_Lists(t *testing.T) {
+	pod := func(name string) string {
+		return `{"apiVersion":"v1","kind":"Pod","metadata":{"name":"` + name + `"},"spec":{"containers":[{"name":"c1","securityContext":{"privileged":true}}]}}`
+	}
+
+	tests := []struct {
+		name    string
+		data    string
+		objects []string
+		items   []int
+	}{
+		{
+			name: "YAML List",
+			data: `apiVersion: v1
+kind: List
+items:
+  - apiVersion: v1
+    kind: Pod
+    metadata:
+      name: a
+    spec:
+      containers:
+        - name: c1
+  - apiVersion: v1
+    kind: Pod
+    metadata:
+      name: b
+    spec:
+      containers:
+        - name: c1
+          securityContext:
+            privileged: true
+`,
+			objects: []string{"Pod/a.default", "Pod/b.default"},
+			items:   []int{0, 1},
+		},
+		{
+			name:    "JSON PodList",
+			data:    `{"apiVersion":"v1","kind":"PodList","items":[` + pod("a") + `,` + pod("b") + `]}`,
+			objects: []string{"Pod/a.default", "Pod/b.default"},
+			items:   []int{0, 1},
+		},
+		{
+			name:    "JSON array",
+			data:    `[` + pod("a") + `,` + pod("b") + `,` + pod("c") + `]`,
+			objects: []string{"Pod/a.default", "Pod/b.default", "Pod/c.default"},
+			items:   []int{0, 1, 2},
+		},
+		{
+			name:    "newline-delimited JSON",
+			data:    pod("a") + "\n\n" + `{"kind":"List","items":[` + pod("b") + `,` + pod("c") + `]}` + "\n" + pod("d") + "\n",
+			objects: []string{"Pod/a.default", "Pod/b.default", "Pod/c.default", "Pod/d.default"},
+			items:   []int{0, 1, 1, 2},
+		},
+		{
+			name:    "empty List",
+			data:    `{"apiVersion":"v1","kind":"List","items":[]}`,
+			objects: nil,
+		},
+	}
+
+	config := NewDefaultSchemaConfig()
+	config.DisableValidation = true
+
+	ruleset, err := NewRuleset(zap.NewNop().Sugar())
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reports, err := ruleset.Run("list.json", []byte(tt.data), config)
+			if err != nil {
+				t.Fatal(err.Error())
+			}
+
+			var objects []string
+			for i, report := range reports {
+				objects = append(objects, report.Object)
+				if report.ItemIndex == nil || *report.ItemIndex != tt.items[i] {
+					t.Errorf("Got item index %v for report %d wanted %d", report.ItemIndex, i, tt.items[i])
+				}
+			}
+			if !reflect.DeepEqual(objects, tt.objects) {
+				t.Errorf("Got objects %v wanted %v", objects, tt.objects)
+			}
+		})
+	}
+
+	t.Run("locations of list items", func(t *testing.T) {
+		reports, err := ruleset.Run("list.yaml", []byte(tests[0].data), config)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		for _, ref := range reports[1].Scoring.Critical {
+			if ref.ID != "Privileged" {
+				continue
+			}
+			wanted := rules.Location{File: "list.yaml", Line: 19, Column: 13}
+			if got := ref.Findings[0].Location; got == nil || *got != wanted {
+				t.Errorf("Got location %v wanted %v", got, wanted)
+			}
+			return
+		}
+		t.Errorf("Got no Privileged finding for the second item")
+	})
+}
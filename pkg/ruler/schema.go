@@ -46,6 +46,9 @@ func validateSchema(report Report, json []byte, schemaConfig SchemaConfig) Repor
 		// File starts with ---, the parser assumes a first empty resource
 		if res.Status == validator.Invalid {
 			report.Message += res.Err.Error() + "\n"
+			for _, e := range res.ValidationErrors {
+				report.SchemaErrors = append(report.SchemaErrors, SchemaError{Path: e.Path, Message: e.Msg})
+			}
 		}
 		if res.Status == validator.Error {
 			report.Message += res.Err.Error()
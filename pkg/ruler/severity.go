@@ -0,0 +1,65 @@
+package ruler
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Severity rates the impact of a finding independently of its points
+type Severity string
+
+const (
+	SeverityCritical Severity = "critical"
+	SeverityHigh     Severity = "high"
+	SeverityMedium   Severity = "medium"
+	SeverityLow      Severity = "low"
+	SeverityInfo     Severity = "info"
+)
+
+// severities are ordered from the most to the least severe
+var severities = []Severity{SeverityCritical, SeverityHigh, SeverityMedium, SeverityLow, SeverityInfo}
+
+// SeveritySelectorPrefix selects the rules at or above a severity in the
+// rule IDs passed to NewRuleset, e.g. severity:high
+const SeveritySelectorPrefix = "severity:"
+
+// ParseSeverity returns the severity of its name
+func ParseSeverity(s string) (Severity, error) {
+	for _, severity := range severities {
+		if Severity(strings.ToLower(s)) == severity {
+			return severity, nil
+		}
+	}
+	return "", fmt.Errorf("unknown severity %q, expected one of critical, high, medium, low, info", s)
+}
+
+// rank is 0 for critical and grows as the severity decreases
+func (s Severity) rank() int {
+	for i, severity := range severities {
+		if s == severity {
+			return i
+		}
+	}
+	return len(severities)
+}
+
+// AtLeast reports whether the severity is as severe as other, or more
+func (s Severity) AtLeast(other Severity) bool {
+	return s.rank() <= other.rank()
+}
+
+// SeverityForPoints is the default severity of the rules which do not set one
+func SeverityForPoints(points int) Severity {
+	switch {
+	case points <= -30:
+		return SeverityCritical
+	case points <= -7:
+		return SeverityHigh
+	case points < 0:
+		return SeverityMedium
+	case points > 0:
+		return SeverityLow
+	default:
+		return SeverityInfo
+	}
+}
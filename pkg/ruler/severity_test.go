@@ -0,0 +1,117 @@
+package ruler
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/controlplaneio/kubesec/v2/pkg/rules"
+	"go.uber.org/zap"
+)
+
+func TestSeverityForPoints(t *testing.T) {
+	tests := []struct {
+		points   int
+		expected Severity
+	}{
+		{-30, SeverityCritical},
+		{-9, SeverityHigh},
+		{-7, SeverityHigh},
+		{-1, SeverityMedium},
+		{0, SeverityInfo},
+		{3, SeverityLow},
+	}
+
+	for _, tt := range tests {
+		if got := SeverityForPoints(tt.points); got != tt.expected {
+			t.Errorf("Got severity %v for %d points, wanted %v", got, tt.points, tt.expected)
+		}
+	}
+}
+
+func TestNewRuleset_SeveritySelection(t *testing.T) {
+	tests := []struct {
+		name          string
+		ruleIDs       []string
+		expectedRules []string
+		expectedError string
+	}{
+		{
+			name:          "critical rules",
+			ruleIDs:       []string{"severity:critical"},
+			expectedRules: []string{"Privileged", "CapSysAdmin", "DockerSock", "BindingsToSystemAnonymous"},
+		},
+		{
+			name:          "critical rules and a rule ID",
+			ruleIDs:       []string{"severity:critical", "SeccompUnconfined", "Privileged"},
+			expectedRules: []string{"Privileged", "CapSysAdmin", "DockerSock", "BindingsToSystemAnonymous", "SeccompUnconfined"},
+		},
+		{
+			name:          "unknown severity",
+			ruleIDs:       []string{"severity:urgent"},
+			expectedError: `unknown severity "urgent"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ruleset, err := NewRuleset(zap.NewNop().Sugar(), tt.ruleIDs...)
+			if tt.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+					t.Errorf("Got error %v, expected: %v", err, tt.expectedError)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err.Error())
+			}
+
+			var ids []string
+			for _, rule := range ruleset.Rules {
+				ids = append(ids, rule.ID)
+			}
+			if !equalIDs(ids, tt.expectedRules) {
+				t.Errorf("Got rules %v wanted %v", ids, tt.expectedRules)
+			}
+		})
+	}
+}
+
+func TestRuleset_Run_Severity(t *testing.T) {
+	rule := Rule{
+		Predicate: func([]byte) rules.Result { return rules.Result{Count: 1} },
+		ID:        "AlwaysMatches",
+		Reason:    "test",
+		Kinds:     []string{"Pod"},
+		Points:    -1,
+	}
+	data := `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: app
+spec:
+  containers:
+    - name: c1
+      securityContext:
+        privileged: true
+`
+	schemaConfig := NewDefaultSchemaConfig()
+	schemaConfig.DisableValidation = true
+
+	ruleset, err := NewRulesetWithConfig(zap.NewNop().Sugar(),
+		RulesetConfig{CustomRules: []Rule{rule}}, "Privileged", "AlwaysMatches")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	reports, err := ruleset.Run("kube.yaml", []byte(data), schemaConfig)
+	if err != nil || len(reports) == 0 {
+		t.Fatal(err)
+	}
+
+	expected := map[string]Severity{"Privileged": SeverityCritical, "AlwaysMatches": SeverityMedium}
+	for _, ruleRef := range reports[0].Scoring.Critical {
+		if ruleRef.Severity != expected[ruleRef.ID] {
+			t.Errorf("Got severity %v for %s wanted %v", ruleRef.Severity, ruleRef.ID, expected[ruleRef.ID])
+		}
+	}
+}
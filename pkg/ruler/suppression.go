@@ -0,0 +1,128 @@
+package ruler
+
+import (
+	"bytes"
+	"sort"
+	"strings"
+
+	"github.com/thedevsaddam/gojsonq/v2"
+
+	"github.com/controlplaneio/kubesec/v2/pkg/rules"
+)
+
+const (
+	// AnnotationIgnore lists the comma-separated IDs of the rules whose
+	// findings are accepted for an object.
+	AnnotationIgnore = "kubesec.io/ignore"
+	// AnnotationIgnoreContainerPrefix followed by a container name lists the
+	// IDs of the rules whose findings are accepted for that container only.
+	AnnotationIgnoreContainerPrefix = "kubesec.io/ignore."
+	// AnnotationIgnoreJustification explains why findings are accepted. It is
+	// required for the ignore annotations to be honoured.
+	AnnotationIgnoreJustification = "kubesec.io/ignore-justification"
+)
+
+// SuppressionSourceAnnotation is the source of suppressions set by annotations
+const SuppressionSourceAnnotation = "annotation"
+
+// Suppression records why a finding was waived
+type Suppression struct {
+	Source        string `json:"source"`
+	Justification string `json:"justification"`
+	// Containers limits the suppression to the named containers
+	Containers []string `json:"containers,omitempty"`
+	// Owner and Expires are set by exceptions
+	Owner   string `json:"owner,omitempty"`
+	Expires string `json:"expires,omitempty"`
+	// Expired is set when the exception expired, the finding is then
+	// reported as a failure
+	Expired bool `json:"expired,omitempty"`
+}
+
+// annotationSuppressions are the suppressions requested by the annotations of an object
+type annotationSuppressions struct {
+	justification string
+	rules         map[string]bool
+	// containers maps rule IDs to the containers they are ignored for
+	containers map[string][]string
+}
+
+// getAnnotationSuppressions reads the ignore annotations of an object. The
+// second return value is false when ignore annotations are set without a
+// justification.
+func getAnnotationSuppressions(json []byte) (annotationSuppressions, bool) {
+	s := annotationSuppressions{
+		rules:      make(map[string]bool),
+		containers: make(map[string][]string),
+	}
+
+	jq := gojsonq.New().Reader(bytes.NewReader(json))
+	annotations, ok := jq.From("metadata.annotations").Get().(map[string]interface{})
+	if !ok {
+		return s, true
+	}
+
+	for key, value := range annotations {
+		v, ok := value.(string)
+		if !ok {
+			continue
+		}
+		switch {
+		case key == AnnotationIgnoreJustification:
+			s.justification = strings.TrimSpace(v)
+		case key == AnnotationIgnore:
+			for _, id := range splitRuleIDs(v) {
+				s.rules[id] = true
+			}
+		case strings.HasPrefix(key, AnnotationIgnoreContainerPrefix):
+			container := strings.TrimPrefix(key, AnnotationIgnoreContainerPrefix)
+			for _, id := range splitRuleIDs(v) {
+				s.containers[id] = append(s.containers[id], container)
+			}
+		}
+	}
+	for id := range s.containers {
+		sort.Strings(s.containers[id])
+	}
+
+	if s.justification == "" && (len(s.rules) > 0 || len(s.containers) > 0) {
+		return annotationSuppressions{}, false
+	}
+	return s, true
+}
+
+// suppress returns the suppression of a failed finding of the rule, nil if
+// the finding is not suppressed. Container scoped suppressions only apply when
+// the rule no longer matches once the ignored containers are removed.
+func (s annotationSuppressions) suppress(rule Rule, ruleRef RuleRef, json []byte) *Suppression {
+	if s.rules[ruleRef.ID] {
+		return &Suppression{
+			Source:        SuppressionSourceAnnotation,
+			Justification: s.justification,
+		}
+	}
+
+	containers, ok := s.containers[ruleRef.ID]
+	if !ok || ruleRef.Points >= 0 || ruleRef.Containers == 0 {
+		return nil
+	}
+	evaluation, err := rule.evaluate(rules.RemoveContainers(json, containers...))
+	if err != nil || evaluation.Count > 0 {
+		return nil
+	}
+	return &Suppression{
+		Source:        SuppressionSourceAnnotation,
+		Justification: s.justification,
+		Containers:    containers,
+	}
+}
+
+func splitRuleIDs(value string) []string {
+	var ids []string
+	for _, id := range strings.Split(value, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
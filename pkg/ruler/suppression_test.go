@@ -0,0 +1,125 @@
+package ruler
+
+import (
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestRuleset_Suppression(t *testing.T) {
+	tests := []struct {
+		name               string
+		annotations        string
+		expectedScore      int
+		expectedSuppressed []string
+		expectedCritical   []string
+	}{
+		{
+			name:             "no annotations",
+			annotations:      `{}`,
+			expectedScore:    -39,
+			expectedCritical: []string{"HostNetwork", "Privileged"},
+		},
+		{
+			name: "object suppression",
+			annotations: `
+    kubesec.io/ignore: HostNetwork, HostPID
+    kubesec.io/ignore-justification: CNI plugin needs the host network`,
+			expectedScore:      -30,
+			expectedSuppressed: []string{"HostNetwork"},
+			expectedCritical:   []string{"Privileged"},
+		},
+		{
+			name: "missing justification",
+			annotations: `
+    kubesec.io/ignore: HostNetwork`,
+			expectedScore:    -39,
+			expectedCritical: []string{"HostNetwork", "Privileged"},
+		},
+		{
+			name: "container suppression",
+			annotations: `
+    kubesec.io/ignore.cni: Privileged
+    kubesec.io/ignore-justification: CNI plugin configures the host`,
+			expectedScore:      -9,
+			expectedSuppressed: []string{"Privileged"},
+			expectedCritical:   []string{"HostNetwork"},
+		},
+		{
+			name: "container suppression does not cover other containers",
+			annotations: `
+    kubesec.io/ignore.sidecar: Privileged
+    kubesec.io/ignore-justification: sidecar is trusted`,
+			expectedScore:    -39,
+			expectedCritical: []string{"HostNetwork", "Privileged"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := `
+apiVersion: apps/v1
+kind: DaemonSet
+metadata:
+  name: cni
+  annotations: ` + tt.annotations + `
+spec:
+  template:
+    spec:
+      hostNetwork: true
+      containers:
+        - name: cni
+          securityContext:
+            privileged: true
+        - name: sidecar
+`
+			schemaConfig := NewDefaultSchemaConfig()
+			schemaConfig.DisableValidation = true
+
+			ruleset, err := NewRuleset(zap.NewNop().Sugar(), "HostNetwork", "Privileged")
+			if err != nil {
+				t.Fatal(err.Error())
+			}
+			reports, err := ruleset.Run("kube.yaml", []byte(data), schemaConfig)
+			if err != nil || len(reports) == 0 {
+				t.Fatal(err)
+			}
+
+			report := reports[0]
+			if report.Score != tt.expectedScore {
+				t.Errorf("Got score %v wanted %v", report.Score, tt.expectedScore)
+			}
+			if got := ruleIDs(report.Scoring.Critical); !equalIDs(got, tt.expectedCritical) {
+				t.Errorf("Got critical %v wanted %v", got, tt.expectedCritical)
+			}
+			if got := ruleIDs(report.Scoring.Suppressed); !equalIDs(got, tt.expectedSuppressed) {
+				t.Errorf("Got suppressed %v wanted %v", got, tt.expectedSuppressed)
+			}
+			for _, ruleRef := range report.Scoring.Suppressed {
+				if ruleRef.Suppression == nil || ruleRef.Suppression.Justification == "" {
+					t.Errorf("Got suppression %v for %s, wanted a justification", ruleRef.Suppression, ruleRef.ID)
+				}
+			}
+		})
+	}
+}
+
+func ruleIDs(refs []RuleRef) []string {
+	var ids []string
+	for _, ref := range refs {
+		ids = append(ids, ref.ID)
+	}
+	return ids
+}
+
+func equalIDs(got, wanted []string) bool {
+	if len(got) != len(wanted) {
+		return false
+	}
+	for _, id := range wanted {
+		if !contains(got, id) {
+			return false
+		}
+	}
+	return true
+}
@@ -4,10 +4,11 @@ import (
 	"github.com/thedevsaddam/gojsonq/v2"
 )
 
-func AllowPrivilegeEscalation(json []byte) int {
+func AllowPrivilegeEscalation(json []byte) Result {
 	return checkSecurityContext(
 		json,
 		false, // not present in PodSecurityContext
+		"securityContext.allowPrivilegeEscalation",
 		func(jq *gojsonq.JSONQ) checkSecurityContextResult {
 			value := jq.From("securityContext.allowPrivilegeEscalation").Get()
 
@@ -31,7 +31,7 @@ spec:
 		t.Fatal(err.Error())
 	}
 
-	containers := AllowPrivilegeEscalation(json)
+	containers := AllowPrivilegeEscalation(json).Count
 	if containers != 3 {
 		t.Errorf("Got %v containers wanted %v", containers, 3)
 	}
@@ -68,7 +68,7 @@ spec:
 		t.Fatal(err.Error())
 	}
 
-	containers := AllowPrivilegeEscalation(json)
+	containers := AllowPrivilegeEscalation(json).Count
 	if containers != 2 {
 		t.Errorf("Got %v containers wanted %v", containers, 2)
 	}
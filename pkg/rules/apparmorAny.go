@@ -22,10 +22,11 @@ func isApparmorUnconfined(jq *gojsonq.JSONQ, expectedUnconfined bool) checkSecur
 	}
 }
 
-func ApparmorAny(json []byte) int {
+func ApparmorAny(json []byte) Result {
 	return checkSecurityContext(
 		json,
 		true, // present in Pod Security Context
+		"securityContext.appArmorProfile.type",
 		func(jq *gojsonq.JSONQ) checkSecurityContextResult {
 			return isApparmorUnconfined(jq, false)
 		},
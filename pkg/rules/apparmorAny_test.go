@@ -15,7 +15,7 @@ func Test_ApparmorAny(t *testing.T) {
 				t.Fatal(err.Error())
 			}
 
-			count := ApparmorAny(json)
+			count := ApparmorAny(json).Count
 			expectedCount := 0
 			if tc.expectedProfileType == tcprofAppArmorRuntimeDefault || tc.expectedProfileType == tcprofAppArmorLocalhost {
 				expectedCount = 1
@@ -2,10 +2,11 @@ package rules
 
 import "github.com/thedevsaddam/gojsonq/v2"
 
-func ApparmorUnconfined(json []byte) int {
+func ApparmorUnconfined(json []byte) Result {
 	return checkSecurityContext(
 		json,
 		true, // can be found in Pod Security Context
+		"securityContext.appArmorProfile.type",
 		func(jq *gojsonq.JSONQ) checkSecurityContextResult {
 			return isApparmorUnconfined(jq, true)
 		},
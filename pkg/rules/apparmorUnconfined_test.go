@@ -14,7 +14,7 @@ func Test_apparmorUnconfined(t *testing.T) {
 				t.Fatal(err.Error())
 			}
 
-			count := ApparmorUnconfined(json)
+			count := ApparmorUnconfined(json).Count
 			expectedCount := 0
 			if tc.expectedProfileType == tcprofAppArmorUnconfined {
 				expectedCount = 1
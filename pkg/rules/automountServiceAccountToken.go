@@ -6,7 +6,7 @@ import (
 	"github.com/thedevsaddam/gojsonq/v2"
 )
 
-func AutomountServiceAccountToken(json []byte) int {
+func AutomountServiceAccountToken(json []byte) Result {
 	spec := getSpecSelector(json)
 	
 	res := gojsonq.New().Reader(bytes.NewReader(json)).
@@ -14,9 +14,9 @@ func AutomountServiceAccountToken(json []byte) int {
 	
 	if res != nil {
 		if v, ok := res.(bool); ok && !v {
-			return 1
+			return match(spec+".automountServiceAccountToken", v)
 		}
 	}
 	
-	return 0
+	return Result{}
 }
@@ -8,17 +8,23 @@ import (
 	"github.com/thedevsaddam/gojsonq/v2"
 )
 
-func BindingsToSystemAnonymous(json []byte) int {
-	data := gojsonq.New().Reader(bytes.NewReader(json)).
-		From("subjects").
-		Only("name")
+func BindingsToSystemAnonymous(json []byte) Result {
+	jq := gojsonq.New().Reader(bytes.NewReader(json))
 
-	subjectNames, ok := data.([]interface{})
-	if ok && subjectNames != nil {
-		if strings.Contains(fmt.Sprintf("%v", subjectNames), "system:anonymous") {
-			return 1
+	var res Result
+	count := jq.Copy().From("subjects").Count()
+	for i := 0; i < count; i++ {
+		name := jq.Copy().From(fmt.Sprintf("subjects.[%d].name", i)).Get()
+		if strings.Contains(fmt.Sprintf("%v", name), "system:anonymous") {
+			res.Findings = append(res.Findings, Finding{
+				Path:  fmt.Sprintf("subjects[%d].name", i),
+				Value: name,
+			})
 		}
 	}
+	if len(res.Findings) > 0 {
+		res.Count = 1
+	}
 
-	return 0
+	return res
 }
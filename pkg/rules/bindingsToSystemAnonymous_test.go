@@ -28,7 +28,7 @@ subjects:
 		t.Fatal(err.Error())
 	}
 
-	res := BindingsToSystemAnonymous(json)
+	res := BindingsToSystemAnonymous(json).Count
 	if res != 1 {
 		t.Errorf("Got %v bindings wanted %v", res, 1)
 	}
@@ -56,7 +56,7 @@ subjects:
 		t.Fatal(err.Error())
 	}
 
-	res := BindingsToSystemAnonymous(json)
+	res := BindingsToSystemAnonymous(json).Count
 	if res != 1 {
 		t.Errorf("Got %v bindings wanted %v", res, 1)
 	}
@@ -85,7 +85,7 @@ subjects:
 		t.Fatal(err.Error())
 	}
 
-	res := BindingsToSystemAnonymous(json)
+	res := BindingsToSystemAnonymous(json).Count
 	if res != 0 {
 		t.Errorf("Got %v bindings wanted %v", res, 0)
 	}
@@ -114,7 +114,7 @@ subjects:
 		t.Fatal(err.Error())
 	}
 
-	res := BindingsToSystemAnonymous(json)
+	res := BindingsToSystemAnonymous(json).Count
 	if res != 0 {
 		t.Errorf("Got %v bindings wanted %v", res, 0)
 	}
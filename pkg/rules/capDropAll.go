@@ -7,10 +7,11 @@ import (
 	"github.com/thedevsaddam/gojsonq/v2"
 )
 
-func CapDropAll(json []byte) int {
+func CapDropAll(json []byte) Result {
 	return checkSecurityContext(
 		json,
 		false, // not present in PodSecurityContext
+		"securityContext.capabilities.drop",
 		func(jq *gojsonq.JSONQ) checkSecurityContextResult {
 			value := jq.From("securityContext.capabilities.drop").Get()
 
@@ -28,7 +28,7 @@ spec:
 		t.Fatal(err.Error())
 	}
 
-	containers := CapDropAll(json)
+	containers := CapDropAll(json).Count
 	if containers != 1 {
 		t.Errorf("Got %v containers wanted %v", containers, 1)
 	}
@@ -56,7 +56,7 @@ spec:
 		t.Fatal(err.Error())
 	}
 
-	containers := CapDropAll(json)
+	containers := CapDropAll(json).Count
 	if containers != 0 {
 		t.Errorf("Got %v containers wanted %v", containers, 0)
 	}
@@ -87,7 +87,7 @@ spec:
 		t.Fatal(err.Error())
 	}
 
-	containers := CapDropAll(json)
+	containers := CapDropAll(json).Count
 	if containers != 2 {
 		t.Errorf("Got %v containers wanted %v", containers, 2)
 	}
@@ -110,7 +110,7 @@ spec:
 		t.Fatal(err.Error())
 	}
 
-	containers := CapDropAll(json)
+	containers := CapDropAll(json).Count
 	if containers != 0 {
 		t.Errorf("Got %v containers wanted %v", containers, 0)
 	}
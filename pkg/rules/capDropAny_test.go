@@ -29,7 +29,7 @@ spec:
 		t.Fatal(err.Error())
 	}
 
-	containers := CapDropAny(json)
+	containers := CapDropAny(json).Count
 	if containers != 1 {
 		t.Errorf("Got %v containers wanted %v", containers, 1)
 	}
@@ -57,7 +57,7 @@ spec:
 		t.Fatal(err.Error())
 	}
 
-	containers := CapDropAny(json)
+	containers := CapDropAny(json).Count
 	if containers != 0 {
 		t.Errorf("Got %v containers wanted %v", containers, 0)
 	}
@@ -88,7 +88,7 @@ spec:
 		t.Fatal(err.Error())
 	}
 
-	containers := CapDropAny(json)
+	containers := CapDropAny(json).Count
 	if containers != 2 {
 		t.Errorf("Got %v containers wanted %v", containers, 2)
 	}
@@ -111,7 +111,7 @@ spec:
 		t.Fatal(err.Error())
 	}
 
-	containers := CapDropAny(json)
+	containers := CapDropAny(json).Count
 	if containers != 0 {
 		t.Errorf("Got %v containers wanted %v", containers, 0)
 	}
@@ -138,7 +138,7 @@ spec:
 		t.Fatal(err.Error())
 	}
 
-	containers := CapDropAny(json)
+	containers := CapDropAny(json).Count
 	if containers != 0 {
 		t.Errorf("Got %v containers wanted %v", containers, 0)
 	}
@@ -165,7 +165,7 @@ spec:
 		t.Fatal(err.Error())
 	}
 
-	containers := CapDropAny(json)
+	containers := CapDropAny(json).Count
 	if containers != 0 {
 		t.Errorf("Got %v containers wanted %v", containers, 0)
 	}
@@ -193,7 +193,7 @@ spec:
 		t.Fatal(err.Error())
 	}
 
-	containers := CapDropAny(json)
+	containers := CapDropAny(json).Count
 	if containers != 0 {
 		t.Errorf("Got %v containers wanted %v", containers, 0)
 	}
@@ -221,7 +221,7 @@ spec:
 		t.Fatal(err.Error())
 	}
 
-	containers := CapDropAny(json)
+	containers := CapDropAny(json).Count
 	if containers != 0 {
 		t.Errorf("Got %v containers wanted %v", containers, 0)
 	}
@@ -248,7 +248,7 @@ spec:
 		t.Fatal(err.Error())
 	}
 
-	containers := CapDropAny(json)
+	containers := CapDropAny(json).Count
 	if containers != 0 {
 		t.Errorf("Got %v containers wanted %v", containers, 0)
 	}
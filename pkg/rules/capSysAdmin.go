@@ -7,10 +7,11 @@ import (
 	"github.com/thedevsaddam/gojsonq/v2"
 )
 
-func CapSysAdmin(json []byte) int {
+func CapSysAdmin(json []byte) Result {
 	return checkSecurityContext(
 		json,
 		false, // not present in PodSecurityContext
+		"securityContext.capabilities.add",
 		func(jq *gojsonq.JSONQ) checkSecurityContextResult {
 			value := jq.From("securityContext.capabilities.add").Get()
 
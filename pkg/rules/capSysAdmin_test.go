@@ -29,7 +29,7 @@ spec:
 		t.Fatal(err.Error())
 	}
 
-	containers := CapSysAdmin(json)
+	containers := CapSysAdmin(json).Count
 	if containers != 1 {
 		t.Errorf("Got %v containers wanted %v", containers, 1)
 	}
@@ -60,7 +60,7 @@ spec:
 		t.Fatal(err.Error())
 	}
 
-	containers := CapSysAdmin(json)
+	containers := CapSysAdmin(json).Count
 	if containers != 2 {
 		t.Errorf("Got %v containers wanted %v", containers, 2)
 	}
@@ -83,7 +83,7 @@ spec:
 		t.Fatal(err.Error())
 	}
 
-	containers := CapSysAdmin(json)
+	containers := CapSysAdmin(json).Count
 	if containers != 0 {
 		t.Errorf("Got %v containers wanted %v", containers, 0)
 	}
@@ -0,0 +1,131 @@
+package rules
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/ext"
+)
+
+// celCostLimit bounds the work a single expression evaluation can do.
+const celCostLimit = 1000000
+
+var celEnv *cel.Env
+
+func init() {
+	var err error
+	celEnv, err = cel.NewEnv(
+		cel.Variable("object", cel.MapType(cel.StringType, cel.DynType)),
+		cel.OptionalTypes(),
+		ext.Strings(),
+		cel.Function("podSpec",
+			cel.Overload("podSpec_map", []*cel.Type{cel.DynType}, cel.MapType(cel.StringType, cel.DynType),
+				cel.UnaryBinding(func(object ref.Val) ref.Val {
+					obj, ok := object.Value().(map[string]interface{})
+					if !ok {
+						return types.NewErr("podSpec: expected an object")
+					}
+					spec, ok := lookupPath(obj, strings.Split(specSelectorsOf(obj)[0], "."))
+					if !ok {
+						spec = map[string]interface{}{}
+					}
+					return types.DefaultTypeAdapter.NativeToValue(spec)
+				}),
+			),
+		),
+		cel.Function("allContainers",
+			cel.Overload("allContainers_map", []*cel.Type{cel.DynType}, cel.ListType(cel.DynType),
+				cel.UnaryBinding(func(object ref.Val) ref.Val {
+					obj, ok := object.Value().(map[string]interface{})
+					if !ok {
+						return types.NewErr("allContainers: expected an object")
+					}
+					var containers []interface{}
+					for _, spec := range specSelectorsOf(obj) {
+						containers = append(containers, getContainers(obj, spec)...)
+					}
+					if containers == nil {
+						containers = []interface{}{}
+					}
+					return types.DefaultTypeAdapter.NativeToValue(containers)
+				}),
+			),
+		),
+		cel.Function("quantity",
+			cel.Overload("quantity_string", []*cel.Type{cel.StringType}, cel.DoubleType,
+				cel.UnaryBinding(func(value ref.Val) ref.Val {
+					q, err := parseQuantity(fmt.Sprint(value.Value()))
+					if err != nil {
+						return types.NewErr("quantity: %s", err)
+					}
+					return types.Double(q)
+				}),
+			),
+		),
+	)
+	if err != nil {
+		panic(fmt.Sprintf("failed to create CEL environment: %v", err))
+	}
+}
+
+// CELPredicate is a compiled expression. Unlike a Predicate it fails when
+// the expression can not be evaluated against an object, e.g. as it exceeds
+// its cost limit or reads a field the object doesn't set.
+type CELPredicate func(json []byte) (Result, error)
+
+// CompileCEL compiles a Common Expression Language expression into a
+// predicate. The decoded manifest is bound to the `object` variable and the
+// expression must return either an int, the match count, or a bool, counted
+// as a single match when true.
+//
+// The following helpers are available to expressions:
+//   - podSpec(object) returns the pod spec of a workload, the first one of
+//     objects with several
+//   - allContainers(object) returns the initContainers, containers and
+//     ephemeralContainers of every pod spec of a workload
+//   - quantity(string) converts a resource quantity such as "2Gi" or "500m"
+//     to a double in base units
+func CompileCEL(expression string) (CELPredicate, error) {
+	ast, issues := celEnv.Compile(expression)
+	if issues != nil && issues.Err() != nil {
+		return nil, issues.Err()
+	}
+
+	switch ast.OutputType() {
+	case cel.IntType, cel.BoolType, cel.DynType:
+	default:
+		return nil, fmt.Errorf("expression must return an int or a bool, got %s", ast.OutputType())
+	}
+
+	program, err := celEnv.Program(ast, cel.CostLimit(celCostLimit))
+	if err != nil {
+		return nil, err
+	}
+
+	return func(data []byte) (Result, error) {
+		var object map[string]interface{}
+		if err := json.Unmarshal(data, &object); err != nil {
+			return Result{}, err
+		}
+
+		out, _, err := program.Eval(map[string]interface{}{"object": object})
+		if err != nil {
+			return Result{}, err
+		}
+
+		// expressions only return a count, there is no field to report
+		switch v := out.(type) {
+		case types.Int:
+			return Result{Count: int(v)}, nil
+		case types.Bool:
+			if v {
+				return Result{Count: 1}, nil
+			}
+		}
+		return Result{}, nil
+	}, nil
+}
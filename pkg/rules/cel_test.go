@@ -0,0 +1,264 @@
+package rules
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/ghodss/yaml"
+)
+
+func Test_CompileCEL(t *testing.T) {
+	var data = `
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+  labels:
+    team: payments
+spec:
+  template:
+    spec:
+      serviceAccountName: app
+      initContainers:
+        - name: init1
+          resources:
+            limits:
+              memory: 64Mi
+      containers:
+        - name: c1
+          image: registry.example.com/app:1.0
+          resources:
+            limits:
+              memory: 4Gi
+        - name: c2
+          image: docker.io/nginx
+          resources:
+            limits:
+              memory: 512Mi
+      ephemeralContainers:
+        - name: debug
+          image: busybox
+`
+
+	json, err := yaml.YAMLToJSON([]byte(data))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	tests := []struct {
+		name, expression string
+		expected         int
+	}{
+		{
+			name:       "containers with memory limit below 2Gi",
+			expression: `allContainers(object).filter(c, quantity(c.?resources.?limits.?memory.orValue("Inf")) < quantity("2Gi")).size()`,
+			expected:   2,
+		},
+		{
+			name:       "all containers from the internal registry",
+			expression: `allContainers(object).all(c, c.?image.orValue("").startsWith("registry.example.com/"))`,
+			expected:   0,
+		},
+		{
+			name:       "team label set",
+			expression: `has(object.metadata.labels.team)`,
+			expected:   1,
+		},
+		{
+			name:       "pod spec service account",
+			expression: `podSpec(object).serviceAccountName == "app"`,
+			expected:   1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			predicate, err := CompileCEL(tt.expression)
+			if err != nil {
+				t.Fatal(err.Error())
+			}
+			res, err := predicate(json)
+			if err != nil {
+				t.Fatal(err.Error())
+			}
+			if count := res.Count; count != tt.expected {
+				t.Errorf("Got %v matches wanted %v", count, tt.expected)
+			}
+		})
+	}
+}
+
+func Test_CompileCEL_PodSpecMappings(t *testing.T) {
+	defer func(mappings []PodSpecMapping) { podSpecMappings = mappings }(PodSpecMappings())
+
+	err := RegisterPodSpecMappings(PodSpecMapping{
+		APIVersion: "example.com",
+		Kind:       "Worker",
+		Paths:      []string{"spec.server.spec", "spec.sidecar.spec"},
+	})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	tests := []struct {
+		name, data, expression string
+		expected               int
+	}{
+		{
+			name: "pod spec of a knative revision",
+			data: `
+apiVersion: serving.knative.dev/v1
+kind: Revision
+metadata:
+  name: app
+spec:
+  serviceAccountName: app
+  containers:
+    - name: c1
+`,
+			expression: `podSpec(object).serviceAccountName == "app"`,
+			expected:   1,
+		},
+		{
+			name: "containers of a knative revision",
+			data: `
+apiVersion: serving.knative.dev/v1
+kind: Revision
+metadata:
+  name: app
+spec:
+  containers:
+    - name: c1
+    - name: c2
+`,
+			expression: `allContainers(object).size()`,
+			expected:   2,
+		},
+		{
+			name: "containers of every pod spec",
+			data: `
+apiVersion: example.com/v1
+kind: Worker
+metadata:
+  name: app
+spec:
+  server:
+    spec:
+      serviceAccountName: server
+      containers:
+        - name: c1
+  sidecar:
+    spec:
+      initContainers:
+        - name: init
+      containers:
+        - name: c2
+`,
+			expression: `allContainers(object).size() * 10 + (podSpec(object).serviceAccountName == "server" ? 1 : 0)`,
+			expected:   31,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			json, err := yaml.YAMLToJSON([]byte(tt.data))
+			if err != nil {
+				t.Fatal(err.Error())
+			}
+			predicate, err := CompileCEL(tt.expression)
+			if err != nil {
+				t.Fatal(err.Error())
+			}
+			res, err := predicate(json)
+			if err != nil {
+				t.Fatal(err.Error())
+			}
+			if res.Count != tt.expected {
+				t.Errorf("Got %v matches wanted %v", res.Count, tt.expected)
+			}
+		})
+	}
+}
+
+func Test_CompileCEL_EvaluationErrors(t *testing.T) {
+	items := make([]string, 2000)
+	for i := range items {
+		items[i] = fmt.Sprint(i)
+	}
+	json := []byte(fmt.Sprintf(`{"kind":"Pod","metadata":{"name":"app"},"spec":{"items":[%s]}}`, strings.Join(items, ",")))
+
+	tests := []struct {
+		name, expression, expectedError string
+	}{
+		{
+			name:          "missing field",
+			expression:    `object.metadata.annotations.owner == "me"`,
+			expectedError: "no such key",
+		},
+		{
+			name:          "cost limit",
+			expression:    `object.spec.items.all(a, object.spec.items.exists(b, a == b))`,
+			expectedError: "cost limit exceeded",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			predicate, err := CompileCEL(tt.expression)
+			if err != nil {
+				t.Fatal(err.Error())
+			}
+			if _, err := predicate(json); err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+				t.Errorf("Got error %v wanted %q", err, tt.expectedError)
+			}
+		})
+	}
+}
+
+func Test_CompileCEL_Invalid(t *testing.T) {
+	tests := []struct {
+		name, expression string
+	}{
+		{name: "syntax error", expression: `allContainers(object).size(`},
+		{name: "undeclared reference", expression: `containers.size()`},
+		{name: "wrong output type", expression: `object.metadata.name + "x"`},
+		{name: "string output", expression: `"match"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := CompileCEL(tt.expression); err == nil {
+				t.Errorf("Compile succeeded when it shouldn't")
+			}
+		})
+	}
+}
+
+func Test_parseQuantity(t *testing.T) {
+	tests := []struct {
+		quantity string
+		expected float64
+	}{
+		{"2Gi", 2 * 1024 * 1024 * 1024},
+		{"500m", 0.5},
+		{"1.5", 1.5},
+		{"128M", 128e6},
+		{"1e3", 1000},
+	}
+
+	for _, tt := range tests {
+		v, err := parseQuantity(tt.quantity)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		if v != tt.expected {
+			t.Errorf("Got %v for %s wanted %v", v, tt.quantity, tt.expected)
+		}
+	}
+
+	if _, err := parseQuantity("lots"); err == nil {
+		t.Errorf("Parsing succeeded when it shouldn't")
+	}
+}
@@ -25,7 +25,7 @@ spec:
 		t.Fatal(err.Error())
 	}
 
-	containers := DockerSock(json)
+	containers := DockerSock(json).Count
 	if containers != 1 {
 		t.Errorf("Got %v volumes wanted %v", containers, 1)
 	}
@@ -56,7 +56,7 @@ spec:
 		t.Fatal(err.Error())
 	}
 
-	containers := DockerSock(json)
+	containers := DockerSock(json).Count
 	if containers != 1 {
 		t.Errorf("Got %v volumes wanted %v", containers, 1)
 	}
@@ -79,7 +79,7 @@ spec:
 		t.Fatal(err.Error())
 	}
 
-	containers := DockerSock(json)
+	containers := DockerSock(json).Count
 	if containers != 0 {
 		t.Errorf("Got %v volumes wanted %v", containers, 0)
 	}
@@ -103,7 +103,7 @@ spec:
 		t.Fatal(err.Error())
 	}
 
-	containers := DockerSock(json)
+	containers := DockerSock(json).Count
 	if containers != 0 {
 		t.Errorf("Got %v volumes wanted %v", containers, 0)
 	}
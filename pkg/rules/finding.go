@@ -0,0 +1,182 @@
+package rules
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/thedevsaddam/gojsonq/v2"
+)
+
+// ContainerType is the kind of container a finding was found in
+type ContainerType string
+
+const (
+	ContainerTypeInit      ContainerType = "init"
+	ContainerTypeRegular   ContainerType = "regular"
+	ContainerTypeEphemeral ContainerType = "ephemeral"
+)
+
+// containerFields are the fields of a pod spec holding containers, in the
+// order they are checked
+var containerFields = []struct {
+	field         string
+	containerType ContainerType
+}{
+	{"initContainers", ContainerTypeInit},
+	{"containers", ContainerTypeRegular},
+	{"ephemeralContainers", ContainerTypeEphemeral},
+}
+
+// Finding is a field of an object matched by a predicate
+type Finding struct {
+	// Container and ContainerType are unset for fields outside of containers
+	Container     string        `json:"container,omitempty"`
+	ContainerType ContainerType `json:"containerType,omitempty"`
+	// Path is the JSON path of the field, e.g.
+	// spec.template.spec.containers[0].securityContext.privileged
+	Path string `json:"path"`
+	// Value is the value observed at the path
+	Value interface{} `json:"value,omitempty"`
+	// Location is the position of the field in the source file, if known
+	Location *Location `json:"location,omitempty"`
+}
+
+// Location is a position in a source file
+type Location struct {
+	File string `json:"file"`
+	// DocumentIndex is the index of the YAML document in the file, from 0
+	DocumentIndex int `json:"documentIndex"`
+	// Line and Column start from 1
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// String returns the position as file:line:column
+func (l Location) String() string {
+	return fmt.Sprintf("%s:%d:%d", l.File, l.Line, l.Column)
+}
+
+// Segments returns the keys and indexes of the path of the finding
+func (f Finding) Segments() []string {
+	segments, err := parsePath(f.Path)
+	if err != nil {
+		return nil
+	}
+	return segments
+}
+
+// String describes the finding, e.g.
+// regular container app: spec.containers[0].securityContext.privileged = true
+func (f Finding) String() string {
+	s := f.Path
+	if f.Value != nil {
+		s += fmt.Sprintf(" = %v", f.Value)
+	}
+	if f.Container != "" {
+		s = fmt.Sprintf("%s container %s: %s", f.ContainerType, f.Container, s)
+	}
+	return s
+}
+
+// Result is the outcome of a predicate: the number of matches, one per
+// container for container fields, and the fields matched
+type Result struct {
+	Count    int
+	Findings []Finding
+}
+
+// Predicate evaluates a rule against the JSON of an object
+type Predicate func(json []byte) Result
+
+// match returns the result of a single match at a path
+func match(path string, value interface{}) Result {
+	return Result{Count: 1, Findings: []Finding{{Path: path, Value: value}}}
+}
+
+// add counts a match and records its finding
+func (r *Result) add(f Finding) {
+	r.Count++
+	r.Findings = append(r.Findings, f)
+}
+
+// container is a container of the pod spec of an object
+type container struct {
+	name          string
+	containerType ContainerType
+	// node is the gojsonq node of the container, e.g. spec.containers.[0]
+	node string
+	// path is the JSON path of the container, e.g. spec.containers[0]
+	path string
+}
+
+func (c container) finding(field string, value interface{}) Finding {
+	return Finding{
+		Container:     c.name,
+		ContainerType: c.containerType,
+		Path:          c.path + "." + field,
+		Value:         value,
+	}
+}
+
+// getContainerNodes returns the containers of the given fields of the pod
+// spec, all of them if no field is given
+func getContainerNodes(jq *gojsonq.JSONQ, spec string, fields ...string) []container {
+	var containers []container
+	for _, cf := range containerFields {
+		if len(fields) > 0 && !contains(fields, cf.field) {
+			continue
+		}
+		count := jq.Copy().From(spec + "." + cf.field).Count()
+		for i := 0; i < count; i++ {
+			node := fmt.Sprintf("%s.%s.[%s]", spec, cf.field, strconv.Itoa(i))
+			name, _ := jq.Copy().From(node + ".name").Get().(string)
+			containers = append(containers, container{
+				name:          name,
+				containerType: cf.containerType,
+				node:          node,
+				path:          fmt.Sprintf("%s.%s[%d]", spec, cf.field, i),
+			})
+		}
+	}
+	return containers
+}
+
+// checkContainers counts the containers of the given fields where the field
+// is set
+func checkContainers(json []byte, field string, fields ...string) Result {
+	jq := gojsonq.New().Reader(bytes.NewReader(json))
+	spec := getSpecSelector(json)
+
+	var res Result
+	for _, c := range getContainerNodes(jq, spec, fields...) {
+		if value := jq.Copy().From(c.node + "." + field).Get(); value != nil {
+			res.add(c.finding(field, value))
+		}
+	}
+	return res
+}
+
+// checkHostPathVolumes returns a single match if the host path of any volume
+// contains the fragment, with a finding per volume
+func checkHostPathVolumes(json []byte, fragment string) Result {
+	jq := gojsonq.New().Reader(bytes.NewReader(json))
+	spec := getSpecSelector(json)
+
+	var res Result
+	count := jq.Copy().From(spec + ".volumes").Count()
+	for i := 0; i < count; i++ {
+		value := jq.Copy().From(fmt.Sprintf("%s.volumes.[%d].hostPath.path", spec, i)).Get()
+		if path, ok := value.(string); ok && strings.Contains(path, fragment) {
+			res.Findings = append(res.Findings, Finding{
+				Path:  fmt.Sprintf("%s.volumes[%d].hostPath.path", spec, i),
+				Value: path,
+			})
+		}
+	}
+	if len(res.Findings) > 0 {
+		res.Count = 1
+	}
+	return res
+}
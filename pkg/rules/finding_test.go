@@ -0,0 +1,156 @@
+package rules
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ghodss/yaml"
+)
+
+func Test_Findings(t *testing.T) {
+	var data = `
+---
+apiVersion: apps/v1
+kind: Deployment
+spec:
+  template:
+    spec:
+      securityContext:
+        runAsNonRoot: true
+      volumes:
+      - name: docker
+        hostPath:
+          path: /var/run/docker.sock
+      initContainers:
+      - name: init
+        securityContext:
+          privileged: true
+      containers:
+      - name: app
+        securityContext:
+          runAsNonRoot: false
+        resources:
+          limits:
+            cpu: 300m
+      - name: sidecar
+        env:
+        - name: TOKEN
+          valueFrom:
+            secretKeyRef:
+              name: token
+              key: token
+      ephemeralContainers:
+      - name: debug
+        securityContext:
+          privileged: true
+`
+
+	json, err := yaml.YAMLToJSON([]byte(data))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	var tests = []struct {
+		name      string
+		predicate Predicate
+		expected  Result
+	}{
+		{
+			name:      "Privileged",
+			predicate: Privileged,
+			expected: Result{Count: 2, Findings: []Finding{
+				{Container: "init", ContainerType: ContainerTypeInit, Path: "spec.template.spec.initContainers[0].securityContext.privileged", Value: true},
+				{Container: "debug", ContainerType: ContainerTypeEphemeral, Path: "spec.template.spec.ephemeralContainers[0].securityContext.privileged", Value: true},
+			}},
+		},
+		{
+			name:      "RunAsNonRoot inherited from the pod security context",
+			predicate: RunAsNonRoot,
+			expected: Result{Count: 3, Findings: []Finding{
+				{Container: "init", ContainerType: ContainerTypeInit, Path: "spec.template.spec.securityContext.runAsNonRoot", Value: true},
+				{Container: "sidecar", ContainerType: ContainerTypeRegular, Path: "spec.template.spec.securityContext.runAsNonRoot", Value: true},
+				{Container: "debug", ContainerType: ContainerTypeEphemeral, Path: "spec.template.spec.securityContext.runAsNonRoot", Value: true},
+			}},
+		},
+		{
+			name:      "LimitsCPU",
+			predicate: LimitsCPU,
+			expected: Result{Count: 1, Findings: []Finding{
+				{Container: "app", ContainerType: ContainerTypeRegular, Path: "spec.template.spec.containers[0].resources.limits.cpu", Value: "300m"},
+			}},
+		},
+		{
+			name:      "SecretsAsEnvironmentVariables",
+			predicate: SecretsAsEnvironmentVariables,
+			expected: Result{Count: 1, Findings: []Finding{
+				{Container: "sidecar", ContainerType: ContainerTypeRegular, Path: "spec.template.spec.containers[1].env[0].valueFrom.secretKeyRef", Value: map[string]interface{}{"name": "token", "key": "token"}},
+			}},
+		},
+		{
+			name:      "DockerSock",
+			predicate: DockerSock,
+			expected: Result{Count: 1, Findings: []Finding{
+				{Path: "spec.template.spec.volumes[0].hostPath.path", Value: "/var/run/docker.sock"},
+			}},
+		},
+		{
+			name:      "HostNetwork unset",
+			predicate: HostNetwork,
+			expected:  Result{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.predicate(json); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Got %+v wanted %+v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func Test_Match_Findings(t *testing.T) {
+	var data = `
+---
+apiVersion: v1
+kind: Pod
+spec:
+  containers:
+  - name: app
+    image: nginx:latest
+  - name: sidecar
+    image: envoy:v1.30
+`
+
+	json, err := yaml.YAMLToJSON([]byte(data))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	match := Match{Scope: MatchScopeContainers, Path: "image", Op: OpMatches, Value: ":latest$"}
+	predicate, err := match.Compile()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	expected := Result{Count: 1, Findings: []Finding{
+		{Container: "app", ContainerType: ContainerTypeRegular, Path: "spec.containers[0].image", Value: "nginx:latest"},
+	}}
+	if got := predicate(json); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Got %+v wanted %+v", got, expected)
+	}
+}
+
+func Test_Finding_String(t *testing.T) {
+	f := Finding{
+		Container:     "app",
+		ContainerType: ContainerTypeRegular,
+		Path:          "spec.containers[0].securityContext.privileged",
+		Value:         true,
+	}
+
+	wanted := "regular container app: spec.containers[0].securityContext.privileged = true"
+	if got := f.String(); got != wanted {
+		t.Errorf("Got %q wanted %q", got, wanted)
+	}
+}
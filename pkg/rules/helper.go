@@ -2,8 +2,8 @@ package rules
 
 import (
 	"bytes"
-	"fmt"
-	"strconv"
+	"encoding/json"
+	"strings"
 
 	"github.com/thedevsaddam/gojsonq/v2"
 )
@@ -22,7 +22,9 @@ type checkSecurityContextResult struct {
 	valid bool
 }
 
-func checkSecurityContext(json []byte, checkPodSecurityContext bool, checkFn checkSecurityContextFn) int {
+// checkSecurityContext counts the containers where checkFn is valid for the
+// field at path, e.g. securityContext.privileged, and records their findings.
+func checkSecurityContext(json []byte, checkPodSecurityContext bool, path string, checkFn checkSecurityContextFn) Result {
 	jq := gojsonq.New().Reader(bytes.NewReader(json))
 	spec := getSpecSelector(json)
 
@@ -38,30 +40,78 @@ func checkSecurityContext(json []byte, checkPodSecurityContext bool, checkFn che
 			attrValidAtPodLevel = true
 		}
 	}
+	podFinding := Finding{
+		Path:  spec + "." + path,
+		Value: jq.Copy().From(spec + "." + path).Get(),
+	}
 
-	containersLen := jq.Copy().From(spec + ".containers").Count()
-	initContainersLen := jq.Copy().From(spec + ".initContainers").Count()
-	ephemeralContainersLen := jq.Copy().From(spec + ".ephemeralContainers").Count()
+	var result Result
+	for _, c := range getContainerNodes(jq, spec) {
+		res := checkFn(jq.Copy().From(c.node))
+		switch {
+		case res.valid:
+			result.add(c.finding(path, jq.Copy().From(c.node+"."+path).Get()))
+		// inherits common setting from pod level.
+		case res.unset && attrValidAtPodLevel:
+			f := podFinding
+			f.Container = c.name
+			f.ContainerType = c.containerType
+			result.add(f)
+		}
+	}
 
-	var valid int
+	return result
+}
 
-	ctnFn := func(containerCount int, containerNode string) {
-		for i := 0; i < containerCount; i++ {
-			// e.g: .initContainer.[0]
-			node := fmt.Sprintf(".%s.[%s]", containerNode, strconv.Itoa(i))
-			c := jq.Copy().From(spec + node)
+// RemoveContainers returns the json of an object without the initContainers,
+// containers and ephemeralContainers with the given names. The json is
+// returned unchanged if it cannot be decoded.
+func RemoveContainers(data []byte, names ...string) []byte {
+	var object interface{}
+	if err := json.Unmarshal(data, &object); err != nil {
+		return data
+	}
 
-			res := checkFn(c)
-			// takes precedence or inherits common setting from pod level.
-			if res.valid || (res.unset && attrValidAtPodLevel) {
-				valid += 1
+	for _, selector := range getSpecSelectors(data) {
+		spec, ok := lookupPath(object, strings.Split(selector, "."))
+		if !ok {
+			continue
+		}
+		podSpec, ok := spec.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		for _, field := range []string{"initContainers", "containers", "ephemeralContainers"} {
+			containers, ok := podSpec[field].([]interface{})
+			if !ok {
+				continue
+			}
+			kept := make([]interface{}, 0, len(containers))
+			for _, c := range containers {
+				if container, ok := c.(map[string]interface{}); ok {
+					if name, ok := container["name"].(string); ok && contains(names, name) {
+						continue
+					}
+				}
+				kept = append(kept, c)
 			}
+			podSpec[field] = kept
 		}
 	}
 
-	ctnFn(initContainersLen, "initContainers")
-	ctnFn(containersLen, "containers")
-	ctnFn(ephemeralContainersLen, "ephemeralContainers")
+	out, err := json.Marshal(object)
+	if err != nil {
+		return data
+	}
+	return out
+}
 
-	return valid
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
 }
@@ -11,6 +11,7 @@ func testCheckSecurityContextRule(json []byte) int {
 	return checkSecurityContext(
 		json,
 		true,
+		"securityContext.myAttribute",
 		func(jq *gojsonq.JSONQ) checkSecurityContextResult {
 			v := jq.From("securityContext.myAttribute").Get()
 
@@ -25,7 +26,7 @@ func testCheckSecurityContextRule(json []byte) int {
 			}
 
 			return res
-		})
+		}).Count
 }
 
 func TestCheckSecurityContext(t *testing.T) {
@@ -6,16 +6,16 @@ import (
 	"github.com/thedevsaddam/gojsonq/v2"
 )
 
-func HostAliases(json []byte) int {
+func HostAliases(json []byte) Result {
 	spec := getSpecSelector(json)
 
 	jqContainers := gojsonq.New().Reader(bytes.NewReader(json)).
 		From(spec + ".hostAliases")
 
 	// TODO(ajm) the above `Where` selectors don't do what I'd expect and filter the results
-	if fmt.Sprintf("%v", jqContainers.Get()) != "<nil>" {
-		return 1
+	if value := jqContainers.Get(); fmt.Sprintf("%v", value) != "<nil>" {
+		return match(spec+".hostAliases", value)
 	}
 
-	return 0
+	return Result{}
 }
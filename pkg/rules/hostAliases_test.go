@@ -37,7 +37,7 @@ spec:
 		t.Fatal(err.Error())
 	}
 
-	containers := HostAliases(json)
+	containers := HostAliases(json).Count
 	if containers != 1 {
 		t.Errorf("Got %v containers wanted %v", containers, 1)
 	}
@@ -60,7 +60,7 @@ spec:
 		t.Fatal(err.Error())
 	}
 
-	containers := HostAliases(json)
+	containers := HostAliases(json).Count
 	if containers != 0 {
 		t.Errorf("Got %v containers wanted %v", containers, 0)
 	}
@@ -82,7 +82,7 @@ spec:
 		t.Fatal(err.Error())
 	}
 
-	containers := HostAliases(json)
+	containers := HostAliases(json).Count
 	if containers != 0 {
 		t.Errorf("Got %v containers wanted %v", containers, 0)
 	}
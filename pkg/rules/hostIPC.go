@@ -5,15 +5,15 @@ import (
 	"github.com/thedevsaddam/gojsonq/v2"
 )
 
-func HostIPC(json []byte) int {
+func HostIPC(json []byte) Result {
 	spec := getSpecSelector(json)
 
 	res := gojsonq.New().Reader(bytes.NewReader(json)).
 		From(spec + ".hostIPC").Get()
 
 	if res != nil && res.(bool) {
-		return 1
+		return match(spec+".hostIPC", res)
 	}
 
-	return 0
+	return Result{}
 }
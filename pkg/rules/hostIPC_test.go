@@ -24,7 +24,7 @@ spec:
 		t.Fatal(err.Error())
 	}
 
-	containers := HostIPC(json)
+	containers := HostIPC(json).Count
 	if containers != 1 {
 		t.Errorf("Got %v containers wanted %v", containers, 1)
 	}
@@ -49,7 +49,7 @@ spec:
 		t.Fatal(err.Error())
 	}
 
-	containers := HostIPC(json)
+	containers := HostIPC(json).Count
 	if containers != 0 {
 		t.Errorf("Got %v containers wanted %v", containers, 0)
 	}
@@ -73,7 +73,7 @@ spec:
 		t.Fatal(err.Error())
 	}
 
-	containers := HostIPC(json)
+	containers := HostIPC(json).Count
 	if containers != 0 {
 		t.Errorf("Got %v containers wanted %v", containers, 0)
 	}
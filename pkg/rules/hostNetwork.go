@@ -5,15 +5,15 @@ import (
 	"github.com/thedevsaddam/gojsonq/v2"
 )
 
-func HostNetwork(json []byte) int {
+func HostNetwork(json []byte) Result {
 	spec := getSpecSelector(json)
 
 	res := gojsonq.New().Reader(bytes.NewReader(json)).
 		From(spec + ".hostNetwork").Get()
 
 	if res != nil && res.(bool) {
-		return 1
+		return match(spec+".hostNetwork", res)
 	}
 
-	return 0
+	return Result{}
 }
@@ -5,15 +5,15 @@ import (
 	"github.com/thedevsaddam/gojsonq/v2"
 )
 
-func HostPID(json []byte) int {
+func HostPID(json []byte) Result {
 	spec := getSpecSelector(json)
 
 	res := gojsonq.New().Reader(bytes.NewReader(json)).
 		From(spec + ".hostPID").Get()
 
 	if res != nil && res.(bool) {
-		return 1
+		return match(spec+".hostPID", res)
 	}
 
-	return 0
+	return Result{}
 }
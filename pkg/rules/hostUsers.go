@@ -7,9 +7,9 @@ import (
 )
 
 // HostUsers checks if the hostUsers field is set to false in the container spec.
-// If it is set to false, it returns 1, indicating that the user namespace is being used.
-// Otherwise, it returns 0, indicating that the user namespace is not being used.
-func HostUsers(json []byte) int {
+// If it is set to false, it returns a count of 1, indicating that the user namespace is being used.
+// Otherwise, it returns a count of 0, indicating that the user namespace is not being used.
+func HostUsers(json []byte) Result {
 	spec := getSpecSelector(json)
 
 	res := gojsonq.New().
@@ -23,16 +23,16 @@ func HostUsers(json []byte) int {
 	// the host’s user namespace.
 
 	if res == nil { // if the value is not set, the default is true
-		return 0
+		return Result{}
 	}
 
 	// If the value is a boolean, we check its value.
 	if v, ok := res.(bool); ok {
 		if !v {
-			return 1
+			return match(spec+".hostUsers", v)
 		}
-		return 0
+		return Result{}
 	}
 	// default to 0 if the value is not a boolean
-	return 0
+	return Result{}
 }
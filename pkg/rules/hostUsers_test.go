@@ -77,7 +77,7 @@ spec:
 			if err != nil {
 				t.Fatal(err)
 			}
-			got := HostUsers(json)
+			got := HostUsers(json).Count
 			if got != tc.want {
 				t.Errorf("HostUsers() - got %v, wanted %v", got, tc.want)
 			}
@@ -236,7 +236,7 @@ spec:
 			if err != nil {
 				t.Fatal(err)
 			}
-			got := HostUsers(json)
+			got := HostUsers(json).Count
 			if got != tc.want {
 				t.Errorf("HostUsers() - got %v, wanted %v", got, tc.want)
 			}
@@ -364,7 +364,7 @@ spec:
 			if err != nil {
 				t.Fatal(err)
 			}
-			got := HostUsers(json)
+			got := HostUsers(json).Count
 			if got != tc.want {
 				t.Errorf("HostUsers() - got %v, wanted %v", got, tc.want)
 			}
@@ -496,7 +496,7 @@ spec:
 			if err != nil {
 				t.Fatal(err)
 			}
-			got := HostUsers(json)
+			got := HostUsers(json).Count
 			if got != tc.want {
 				t.Errorf("HostUsers() - got %v, wanted %v", got, tc.want)
 			}
@@ -29,7 +29,7 @@ spec:
 		t.Fatal(err.Error())
 	}
 
-	containers := LimitsCPU(json)
+	containers := LimitsCPU(json).Count
 	if containers != 1 {
 		t.Errorf("Got %v containers wanted %v", containers, 1)
 	}
@@ -62,7 +62,7 @@ spec:
 		t.Fatal(err.Error())
 	}
 
-	containers := LimitsCPU(json)
+	containers := LimitsCPU(json).Count
 	if containers != 2 {
 		t.Errorf("Got %v containers wanted %v", containers, 2)
 	}
@@ -90,7 +90,7 @@ spec:
 		t.Fatal(err.Error())
 	}
 
-	containers := LimitsCPU(json)
+	containers := LimitsCPU(json).Count
 	if containers != 0 {
 		t.Errorf("Got %v containers wanted %v", containers, 0)
 	}
@@ -131,7 +131,7 @@ spec:
 		t.Fatal(err.Error())
 	}
 
-	containers := LimitsCPU(json)
+	containers := LimitsCPU(json).Count
 	if containers != 0 {
 		t.Errorf("Got %v containers wanted %v", containers, 0)
 	}
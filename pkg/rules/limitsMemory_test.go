@@ -30,7 +30,7 @@ spec:
 		t.Fatal(err.Error())
 	}
 
-	containers := LimitsMemory(json)
+	containers := LimitsMemory(json).Count
 	if containers != 1 {
 		t.Errorf("Got %v containers wanted %v", containers, 1)
 	}
@@ -61,7 +61,7 @@ spec:
 		t.Fatal(err.Error())
 	}
 
-	containers := LimitsMemory(json)
+	containers := LimitsMemory(json).Count
 	if containers != 2 {
 		t.Errorf("Got %v containers wanted %v", containers, 2)
 	}
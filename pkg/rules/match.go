@@ -0,0 +1,430 @@
+package rules
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Match scopes define which node of a manifest the path of a Match is
+// resolved against.
+const (
+	// MatchScopeObject resolves paths from the root of the manifest.
+	MatchScopeObject = "object"
+	// MatchScopePodSpec resolves paths from the pod spec of a workload,
+	// e.g. spec.template.spec for a Deployment.
+	MatchScopePodSpec = "podSpec"
+	// MatchScopeContainers resolves paths from every initContainer,
+	// container and ephemeralContainer of the pod spec.
+	MatchScopeContainers = "containers"
+)
+
+// Match operators.
+const (
+	OpExists      = "exists"
+	OpNotExists   = "notExists"
+	OpEquals      = "equals"
+	OpNotEquals   = "notEquals"
+	OpIn          = "in"
+	OpNotIn       = "notIn"
+	OpContains    = "contains"
+	OpMatches     = "matches"
+	OpNotMatches  = "notMatches"
+	OpGreaterThan = "greaterThan"
+	OpLessThan    = "lessThan"
+)
+
+// Match is a declarative predicate over the JSON of a manifest. A Match is
+// either a leaf condition (Path, Op and Value) or a combination of nested
+// matches through All, Any or Not.
+//
+// The predicate returned by Compile counts one match per container when
+// Scope is "containers", and a single match otherwise.
+type Match struct {
+	Scope string      `json:"scope,omitempty" yaml:"scope,omitempty"`
+	Path  string      `json:"path,omitempty" yaml:"path,omitempty"`
+	Op    string      `json:"op,omitempty" yaml:"op,omitempty"`
+	Value interface{} `json:"value,omitempty" yaml:"value,omitempty"`
+	All   []Match     `json:"all,omitempty" yaml:"all,omitempty"`
+	Any   []Match     `json:"any,omitempty" yaml:"any,omitempty"`
+	Not   *Match      `json:"not,omitempty" yaml:"not,omitempty"`
+}
+
+// condition evaluates a compiled Match against a decoded JSON node.
+type condition func(node interface{}) bool
+
+// Compile validates the Match and returns a predicate evaluating it.
+func (m *Match) Compile() (Predicate, error) {
+	scope := m.Scope
+	if scope == "" {
+		scope = MatchScopeObject
+	}
+	switch scope {
+	case MatchScopeObject, MatchScopePodSpec, MatchScopeContainers:
+	default:
+		return nil, fmt.Errorf("unknown match scope %q", m.Scope)
+	}
+
+	cond, err := m.compile()
+	if err != nil {
+		return nil, err
+	}
+
+	return func(data []byte) Result {
+		var object interface{}
+		if err := json.Unmarshal(data, &object); err != nil {
+			return Result{}
+		}
+
+		spec := getSpecSelector(data)
+		var nodes []matchNode
+		switch scope {
+		case MatchScopeObject:
+			nodes = []matchNode{{node: object}}
+		case MatchScopePodSpec:
+			if node, ok := lookupPath(object, strings.Split(spec, ".")); ok {
+				nodes = []matchNode{{node: node, finding: Finding{Path: spec}}}
+			}
+		case MatchScopeContainers:
+			nodes = getContainerMatchNodes(object, spec)
+		}
+
+		var res Result
+		for _, n := range nodes {
+			if cond(n.node) {
+				res.add(m.finding(n))
+			}
+		}
+		return res
+	}, nil
+}
+
+// matchNode is a node a Match is evaluated against, with the finding of the node
+type matchNode struct {
+	node    interface{}
+	finding Finding
+}
+
+// finding returns the finding of a matched node, pointing to the path of a
+// leaf match
+func (m *Match) finding(n matchNode) Finding {
+	f := n.finding
+	if m.Path == "" {
+		return f
+	}
+
+	if f.Path != "" {
+		f.Path += "."
+	}
+	f.Path += m.Path
+	if path, err := parsePath(m.Path); err == nil {
+		f.Value, _ = lookupPath(n.node, path)
+	}
+	return f
+}
+
+func (m *Match) compile() (condition, error) {
+	combinators := 0
+	for _, set := range []bool{len(m.All) > 0, len(m.Any) > 0, m.Not != nil, m.Op != "" || m.Path != ""} {
+		if set {
+			combinators++
+		}
+	}
+	if combinators != 1 {
+		return nil, fmt.Errorf("match must define exactly one of path/op, all, any or not")
+	}
+
+	switch {
+	case len(m.All) > 0:
+		conds, err := compileNested(m.All)
+		if err != nil {
+			return nil, err
+		}
+		return func(node interface{}) bool {
+			for _, c := range conds {
+				if !c(node) {
+					return false
+				}
+			}
+			return true
+		}, nil
+	case len(m.Any) > 0:
+		conds, err := compileNested(m.Any)
+		if err != nil {
+			return nil, err
+		}
+		return func(node interface{}) bool {
+			for _, c := range conds {
+				if c(node) {
+					return true
+				}
+			}
+			return false
+		}, nil
+	case m.Not != nil:
+		conds, err := compileNested([]Match{*m.Not})
+		if err != nil {
+			return nil, err
+		}
+		return func(node interface{}) bool {
+			return !conds[0](node)
+		}, nil
+	}
+
+	return m.compileLeaf()
+}
+
+func compileNested(matches []Match) ([]condition, error) {
+	conds := make([]condition, 0, len(matches))
+	for i := range matches {
+		if matches[i].Scope != "" {
+			return nil, fmt.Errorf("scope is only allowed on the top-level match")
+		}
+		c, err := matches[i].compile()
+		if err != nil {
+			return nil, err
+		}
+		conds = append(conds, c)
+	}
+	return conds, nil
+}
+
+func (m *Match) compileLeaf() (condition, error) {
+	if m.Path == "" {
+		return nil, fmt.Errorf("match path is required")
+	}
+	path, err := parsePath(m.Path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid path %q: %w", m.Path, err)
+	}
+
+	var test func(value interface{}, found bool) bool
+	switch m.Op {
+	case OpExists:
+		test = func(_ interface{}, found bool) bool { return found }
+	case OpNotExists:
+		test = func(_ interface{}, found bool) bool { return !found }
+	case OpEquals, OpNotEquals:
+		if m.Value == nil {
+			return nil, fmt.Errorf("operator %s requires a value", m.Op)
+		}
+		want := normalise(m.Value)
+		negate := m.Op == OpNotEquals
+		test = func(value interface{}, found bool) bool {
+			return found && reflect.DeepEqual(normalise(value), want) != negate
+		}
+	case OpIn, OpNotIn:
+		values, ok := m.Value.([]interface{})
+		if !ok || len(values) == 0 {
+			return nil, fmt.Errorf("operator %s requires a non-empty list value", m.Op)
+		}
+		negate := m.Op == OpNotIn
+		test = func(value interface{}, found bool) bool {
+			if !found {
+				return false
+			}
+			for _, v := range values {
+				if reflect.DeepEqual(normalise(value), normalise(v)) {
+					return !negate
+				}
+			}
+			return negate
+		}
+	case OpContains:
+		if m.Value == nil {
+			return nil, fmt.Errorf("operator %s requires a value", m.Op)
+		}
+		want := normalise(m.Value)
+		test = func(value interface{}, found bool) bool {
+			switch v := value.(type) {
+			case []interface{}:
+				for _, item := range v {
+					if reflect.DeepEqual(normalise(item), want) {
+						return true
+					}
+				}
+			case string:
+				if s, ok := want.(string); ok {
+					return strings.Contains(v, s)
+				}
+			}
+			return false
+		}
+	case OpMatches, OpNotMatches:
+		pattern, ok := m.Value.(string)
+		if !ok {
+			return nil, fmt.Errorf("operator %s requires a string value", m.Op)
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regular expression %q: %w", pattern, err)
+		}
+		negate := m.Op == OpNotMatches
+		test = func(value interface{}, found bool) bool {
+			s, ok := value.(string)
+			return found && ok && re.MatchString(s) != negate
+		}
+	case OpGreaterThan, OpLessThan:
+		want, ok := toFloat(m.Value)
+		if !ok {
+			return nil, fmt.Errorf("operator %s requires a numeric value", m.Op)
+		}
+		greater := m.Op == OpGreaterThan
+		test = func(value interface{}, found bool) bool {
+			v, ok := toFloat(value)
+			if !found || !ok {
+				return false
+			}
+			if greater {
+				return v > want
+			}
+			return v < want
+		}
+	case "":
+		return nil, fmt.Errorf("match operator is required")
+	default:
+		return nil, fmt.Errorf("unknown match operator %q", m.Op)
+	}
+
+	return func(node interface{}) bool {
+		value, found := lookupPath(node, path)
+		return test(value, found)
+	}, nil
+}
+
+// parsePath splits a path such as metadata.labels["app.kubernetes.io/name"]
+// or spec.containers[0].image into its segments.
+func parsePath(path string) ([]string, error) {
+	var segments []string
+	var current strings.Builder
+
+	flush := func() {
+		if current.Len() > 0 {
+			segments = append(segments, current.String())
+			current.Reset()
+		}
+	}
+
+	for i := 0; i < len(path); i++ {
+		switch c := path[i]; c {
+		case '.':
+			if current.Len() == 0 && (i == 0 || path[i-1] != ']') {
+				return nil, fmt.Errorf("empty segment at offset %d", i)
+			}
+			flush()
+		case '[':
+			flush()
+			end := strings.IndexByte(path[i:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated bracket at offset %d", i)
+			}
+			key := path[i+1 : i+end]
+			if unquoted, err := strconv.Unquote(key); err == nil {
+				key = unquoted
+			} else if _, err := strconv.Atoi(key); err != nil {
+				return nil, fmt.Errorf("bracket segment %q must be an index or a quoted key", key)
+			}
+			segments = append(segments, key)
+			i += end
+		default:
+			current.WriteByte(c)
+		}
+	}
+	flush()
+
+	if len(segments) == 0 {
+		return nil, fmt.Errorf("empty path")
+	}
+	return segments, nil
+}
+
+// lookupPath resolves the segments of a path against a decoded JSON node.
+func lookupPath(node interface{}, segments []string) (interface{}, bool) {
+	for _, segment := range segments {
+		switch n := node.(type) {
+		case map[string]interface{}:
+			v, ok := n[segment]
+			if !ok {
+				return nil, false
+			}
+			node = v
+		case []interface{}:
+			i, err := strconv.Atoi(segment)
+			if err != nil || i < 0 || i >= len(n) {
+				return nil, false
+			}
+			node = n[i]
+		default:
+			return nil, false
+		}
+	}
+	return node, node != nil
+}
+
+// getContainerMatchNodes returns the initContainers, containers and
+// ephemeralContainers found under the spec selector with their findings
+func getContainerMatchNodes(object interface{}, spec string) []matchNode {
+	var nodes []matchNode
+	for _, cf := range containerFields {
+		path := append(strings.Split(spec, "."), cf.field)
+		list, _ := lookupPath(object, path)
+		items, _ := list.([]interface{})
+		for i, item := range items {
+			value, _ := lookupPath(item, []string{"name"})
+			name, _ := value.(string)
+			nodes = append(nodes, matchNode{
+				node: item,
+				finding: Finding{
+					Container:     name,
+					ContainerType: cf.containerType,
+					Path:          fmt.Sprintf("%s.%s[%d]", spec, cf.field, i),
+				},
+			})
+		}
+	}
+	return nodes
+}
+
+// getContainers returns the initContainers, containers and ephemeralContainers
+// found under the spec selector, in that order.
+func getContainers(object interface{}, spec string) []interface{} {
+	var containers []interface{}
+	for _, field := range []string{"initContainers", "containers", "ephemeralContainers"} {
+		path := append(strings.Split(spec, "."), field)
+		if list, ok := lookupPath(object, path); ok {
+			if items, ok := list.([]interface{}); ok {
+				containers = append(containers, items...)
+			}
+		}
+	}
+	return containers
+}
+
+// normalise converts numbers to float64 so that values decoded from
+// YAML and JSON compare equal.
+func normalise(v interface{}) interface{} {
+	if f, ok := toFloat(v); ok {
+		return f
+	}
+	return v
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
@@ -0,0 +1,126 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/ghodss/yaml"
+)
+
+func Test_Match(t *testing.T) {
+	var data = `
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+  labels:
+    app.kubernetes.io/name: app
+    team: payments
+spec:
+  replicas: 3
+  template:
+    spec:
+      initContainers:
+        - name: init1
+          image: docker.io/busybox
+      containers:
+        - name: c1
+          image: registry.example.com/app:1.0
+          ports:
+            - containerPort: 8080
+        - name: c2
+          image: docker.io/nginx
+`
+
+	json, err := yaml.YAMLToJSON([]byte(data))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	tests := []struct {
+		name     string
+		match    Match
+		expected int
+	}{
+		{
+			name:     "label exists",
+			match:    Match{Path: "metadata.labels.team", Op: OpExists},
+			expected: 1,
+		},
+		{
+			name:     "quoted label key exists",
+			match:    Match{Path: `metadata.labels["app.kubernetes.io/name"]`, Op: OpExists},
+			expected: 1,
+		},
+		{
+			name:     "annotation does not exist",
+			match:    Match{Path: "metadata.annotations.owner", Op: OpNotExists},
+			expected: 1,
+		},
+		{
+			name:     "label not in list",
+			match:    Match{Path: "metadata.labels.team", Op: OpNotIn, Value: []interface{}{"infra", "security"}},
+			expected: 1,
+		},
+		{
+			name:     "replicas greater than",
+			match:    Match{Path: "spec.replicas", Op: OpGreaterThan, Value: 2},
+			expected: 1,
+		},
+		{
+			name:     "containers from banned registry",
+			match:    Match{Scope: MatchScopeContainers, Path: "image", Op: OpMatches, Value: "^docker\\.io/"},
+			expected: 2,
+		},
+		{
+			name: "containers exposing port 8080",
+			match: Match{Scope: MatchScopeContainers, All: []Match{
+				{Path: "ports[0].containerPort", Op: OpEquals, Value: 8080},
+				{Not: &Match{Path: "image", Op: OpContains, Value: "docker.io"}},
+			}},
+			expected: 1,
+		},
+		{
+			name:     "pod spec without service account",
+			match:    Match{Scope: MatchScopePodSpec, Path: "serviceAccountName", Op: OpNotExists},
+			expected: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			predicate, err := tt.match.Compile()
+			if err != nil {
+				t.Fatal(err.Error())
+			}
+			if count := predicate(json).Count; count != tt.expected {
+				t.Errorf("Got %v matches wanted %v", count, tt.expected)
+			}
+		})
+	}
+}
+
+func Test_Match_Invalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		match Match
+	}{
+		{name: "missing operator", match: Match{Path: "metadata.name"}},
+		{name: "unknown operator", match: Match{Path: "metadata.name", Op: "like"}},
+		{name: "unknown scope", match: Match{Scope: "volumes", Path: "name", Op: OpExists}},
+		{name: "invalid regular expression", match: Match{Path: "metadata.name", Op: OpMatches, Value: "("}},
+		{name: "non-numeric comparison", match: Match{Path: "spec.replicas", Op: OpLessThan, Value: "two"}},
+		{name: "in without list", match: Match{Path: "metadata.name", Op: OpIn, Value: "app"}},
+		{name: "empty path segment", match: Match{Path: "metadata..name", Op: OpExists}},
+		{name: "nested scope", match: Match{Any: []Match{{Scope: MatchScopeContainers, Path: "image", Op: OpExists}}}},
+		{name: "ambiguous match", match: Match{Path: "metadata.name", Op: OpExists, Not: &Match{Path: "kind", Op: OpExists}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.match.Compile(); err == nil {
+				t.Errorf("Compile succeeded when it shouldn't")
+			}
+		})
+	}
+}
@@ -0,0 +1,183 @@
+package rules
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// PodSpecMapping locates the pod specs of the objects of an apiVersion and
+// kind, typically a custom resource running pods
+type PodSpecMapping struct {
+	// APIVersion is a group and version, e.g. argoproj.io/v1alpha1, or a
+	// group matching all of its versions, e.g. argoproj.io
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	// Paths are the dotted paths of the pod specs, e.g. spec.template.spec
+	Paths []string `json:"paths"`
+}
+
+// defaultPodSpecMappings locate the pod specs of popular workload resources
+var defaultPodSpecMappings = []PodSpecMapping{
+	{APIVersion: "argoproj.io", Kind: "Rollout", Paths: []string{"spec.template.spec"}},
+	{APIVersion: "serving.knative.dev", Kind: "Service", Paths: []string{"spec.template.spec"}},
+	{APIVersion: "serving.knative.dev", Kind: "Configuration", Paths: []string{"spec.template.spec"}},
+	{APIVersion: "serving.knative.dev", Kind: "Revision", Paths: []string{"spec"}},
+	{APIVersion: "apps.openshift.io", Kind: "DeploymentConfig", Paths: []string{"spec.template.spec"}},
+}
+
+var (
+	podSpecMappingsMu sync.RWMutex
+	podSpecMappings   = append([]PodSpecMapping{}, defaultPodSpecMappings...)
+)
+
+var podSpecPathRegex = regexp.MustCompile(`^[A-Za-z0-9_-]+(\.[A-Za-z0-9_-]+)*$`)
+
+// Validate checks the mapping has an apiVersion, a kind and valid paths
+func (m PodSpecMapping) Validate() error {
+	if m.APIVersion == "" {
+		return fmt.Errorf("pod spec mapping for kind %q: apiVersion is required", m.Kind)
+	}
+	if m.Kind == "" {
+		return fmt.Errorf("pod spec mapping for %s: kind is required", m.APIVersion)
+	}
+	if len(m.Paths) == 0 {
+		return fmt.Errorf("pod spec mapping for %s %s: at least one path is required", m.APIVersion, m.Kind)
+	}
+	for _, path := range m.Paths {
+		if !podSpecPathRegex.MatchString(path) {
+			return fmt.Errorf("pod spec mapping for %s %s: invalid path %q, expected dotted keys such as spec.template.spec", m.APIVersion, m.Kind, path)
+		}
+	}
+	return nil
+}
+
+// matches reports whether the mapping applies to an object of the apiVersion
+// and kind
+func (m PodSpecMapping) matches(apiVersion, kind string) bool {
+	if m.Kind != kind {
+		return false
+	}
+	if strings.Contains(m.APIVersion, "/") {
+		return m.APIVersion == apiVersion
+	}
+	group, _, found := strings.Cut(apiVersion, "/")
+	return found && group == m.APIVersion
+}
+
+// RegisterPodSpecMappings adds mappings for the pod specs of custom
+// resources, they take precedence over the mappings registered before
+func RegisterPodSpecMappings(mappings ...PodSpecMapping) error {
+	for _, m := range mappings {
+		if err := m.Validate(); err != nil {
+			return err
+		}
+	}
+
+	podSpecMappingsMu.Lock()
+	defer podSpecMappingsMu.Unlock()
+	podSpecMappings = append(append([]PodSpecMapping{}, mappings...), podSpecMappings...)
+	return nil
+}
+
+// PodSpecMappings returns the registered pod spec mappings, in order of
+// precedence
+func PodSpecMappings() []PodSpecMapping {
+	podSpecMappingsMu.RLock()
+	defer podSpecMappingsMu.RUnlock()
+	return append([]PodSpecMapping{}, podSpecMappings...)
+}
+
+// lookupPodSpecMapping returns the mapping of an apiVersion and kind
+func lookupPodSpecMapping(apiVersion, kind string) (PodSpecMapping, bool) {
+	podSpecMappingsMu.RLock()
+	defer podSpecMappingsMu.RUnlock()
+	for _, m := range podSpecMappings {
+		if m.matches(apiVersion, kind) {
+			return m, true
+		}
+	}
+	return PodSpecMapping{}, false
+}
+
+// IsPodSpecKind reports whether a pod spec mapping exists for the kind in any
+// apiVersion
+func IsPodSpecKind(kind string) bool {
+	podSpecMappingsMu.RLock()
+	defer podSpecMappingsMu.RUnlock()
+	for _, m := range podSpecMappings {
+		if m.Kind == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// HasPodSpecMapping reports whether the pod specs of an object are located by
+// a pod spec mapping
+func HasPodSpecMapping(data []byte) bool {
+	var object map[string]interface{}
+	if err := json.Unmarshal(data, &object); err != nil {
+		return false
+	}
+	_, ok := objectPodSpecMapping(object)
+	return ok
+}
+
+func objectPodSpecMapping(object map[string]interface{}) (PodSpecMapping, bool) {
+	apiVersion, _ := object["apiVersion"].(string)
+	kind, _ := object["kind"].(string)
+	return lookupPodSpecMapping(apiVersion, kind)
+}
+
+// existingPaths returns the paths of the mapping present in the object, or
+// the first path if none is
+func (m PodSpecMapping) existingPaths(object interface{}) []string {
+	var paths []string
+	for _, path := range m.Paths {
+		if _, ok := lookupPath(object, strings.Split(path, ".")); ok {
+			paths = append(paths, path)
+		}
+	}
+	if len(paths) == 0 {
+		return m.Paths[:1]
+	}
+	return paths
+}
+
+// SplitPodSpecs returns an object for each pod spec of an object mapped to
+// several pod spec paths, holding only that pod spec. Other objects are
+// returned unchanged.
+func SplitPodSpecs(data []byte) [][]byte {
+	paths := getSpecSelectors(data)
+	if len(paths) < 2 {
+		return [][]byte{data}
+	}
+
+	var docs [][]byte
+	for _, keep := range paths {
+		var object interface{}
+		if err := json.Unmarshal(data, &object); err != nil {
+			return [][]byte{data}
+		}
+		for _, path := range paths {
+			if path == keep {
+				continue
+			}
+			segments := strings.Split(path, ".")
+			if parent, ok := lookupPath(object, segments[:len(segments)-1]); ok {
+				if m, ok := parent.(map[string]interface{}); ok {
+					delete(m, segments[len(segments)-1])
+				}
+			}
+		}
+		out, err := json.Marshal(object)
+		if err != nil {
+			return [][]byte{data}
+		}
+		docs = append(docs, out)
+	}
+	return docs
+}
@@ -4,10 +4,11 @@ import (
 	"github.com/thedevsaddam/gojsonq/v2"
 )
 
-func Privileged(json []byte) int {
+func Privileged(json []byte) Result {
 	return checkSecurityContext(
 		json,
 		false, // not present in PodSecurityContext
+		"securityContext.privileged",
 		func(jq *gojsonq.JSONQ) checkSecurityContextResult {
 			value := jq.From("securityContext.privileged").Get()
 
@@ -32,7 +32,7 @@ spec:
 		t.Fatal(err.Error())
 	}
 
-	containers := Privileged(json)
+	containers := Privileged(json).Count
 	if containers != 2 {
 		t.Errorf("Got %v containers wanted %v", containers, 2)
 	}
@@ -55,7 +55,7 @@ spec:
 		t.Fatal(err.Error())
 	}
 
-	containers := Privileged(json)
+	containers := Privileged(json).Count
 	if containers != 1 {
 		t.Errorf("Got %v containers wanted %v", containers, 1)
 	}
@@ -78,8 +78,76 @@ spec:
 		t.Fatal(err.Error())
 	}
 
-	containers := Privileged(json)
+	containers := Privileged(json).Count
 	if containers != 0 {
 		t.Errorf("Got %v containers wanted %v", containers, 0)
 	}
 }
+
+func Test_Privileged_PodTemplates(t *testing.T) {
+	var tests = []struct {
+		name string
+		data string
+	}{
+		{
+			name: "Job",
+			data: `
+---
+apiVersion: batch/v1
+kind: Job
+spec:
+  template:
+    spec:
+      containers:
+      - name: c1
+        securityContext:
+          privileged: true
+`,
+		},
+		{
+			name: "CronJob",
+			data: `
+---
+apiVersion: batch/v1
+kind: CronJob
+spec:
+  jobTemplate:
+    spec:
+      template:
+        spec:
+          containers:
+          - name: c1
+            securityContext:
+              privileged: true
+`,
+		},
+		{
+			name: "PodTemplate",
+			data: `
+---
+apiVersion: v1
+kind: PodTemplate
+template:
+  spec:
+    containers:
+    - name: c1
+      securityContext:
+        privileged: true
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			json, err := yaml.YAMLToJSON([]byte(tt.data))
+			if err != nil {
+				t.Fatal(err.Error())
+			}
+
+			containers := Privileged(json).Count
+			if containers != 1 {
+				t.Errorf("Got %v containers wanted %v", containers, 1)
+			}
+		})
+	}
+}
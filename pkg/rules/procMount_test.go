@@ -26,7 +26,7 @@ spec:
 		t.Fatal(err.Error())
 	}
 
-	containers := ProcMount(json)
+	containers := ProcMount(json).Count
 	if containers != 1 {
 		t.Errorf("Got %v volumes wanted %v", containers, 1)
 	}
@@ -57,7 +57,7 @@ spec:
 		t.Fatal(err.Error())
 	}
 
-	containers := ProcMount(json)
+	containers := ProcMount(json).Count
 	if containers != 1 {
 		t.Errorf("Got %v volumes wanted %v", containers, 1)
 	}
@@ -80,7 +80,7 @@ spec:
 		t.Fatal(err.Error())
 	}
 
-	containers := ProcMount(json)
+	containers := ProcMount(json).Count
 	if containers != 0 {
 		t.Errorf("Got %v volumes wanted %v", containers, 0)
 	}
@@ -104,7 +104,7 @@ spec:
 		t.Fatal(err.Error())
 	}
 
-	containers := ProcMount(json)
+	containers := ProcMount(json).Count
 	if containers != 0 {
 		t.Errorf("Got %v volumes wanted %v", containers, 0)
 	}
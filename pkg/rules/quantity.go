@@ -0,0 +1,55 @@
+package rules
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// quantitySuffixes maps the suffixes of Kubernetes resource quantities
+// to their multiplier, binary suffixes first so "Mi" is not read as "M".
+var quantitySuffixes = []struct {
+	suffix     string
+	multiplier float64
+}{
+	{"Ki", 1 << 10},
+	{"Mi", 1 << 20},
+	{"Gi", 1 << 30},
+	{"Ti", 1 << 40},
+	{"Pi", 1 << 50},
+	{"Ei", 1 << 60},
+	{"n", 1e-9},
+	{"u", 1e-6},
+	{"m", 1e-3},
+	{"k", 1e3},
+	{"M", 1e6},
+	{"G", 1e9},
+	{"T", 1e12},
+	{"P", 1e15},
+	{"E", 1e18},
+}
+
+// parseQuantity converts a Kubernetes resource quantity such as "500m",
+// "2Gi" or "1e3" to its value in base units.
+func parseQuantity(s string) (float64, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, fmt.Errorf("empty quantity")
+	}
+
+	multiplier := 1.0
+	number := s
+	for _, q := range quantitySuffixes {
+		if strings.HasSuffix(s, q.suffix) {
+			multiplier = q.multiplier
+			number = strings.TrimSuffix(s, q.suffix)
+			break
+		}
+	}
+
+	v, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid quantity %q", s)
+	}
+	return v * multiplier, nil
+}
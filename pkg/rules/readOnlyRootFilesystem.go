@@ -4,10 +4,11 @@ import (
 	"github.com/thedevsaddam/gojsonq/v2"
 )
 
-func ReadOnlyRootFilesystem(json []byte) int {
+func ReadOnlyRootFilesystem(json []byte) Result {
 	return checkSecurityContext(
 		json,
 		false, // not present in PodSecurityContext
+		"securityContext.readOnlyRootFilesystem",
 		func(jq *gojsonq.JSONQ) checkSecurityContextResult {
 			value := jq.From("securityContext.readOnlyRootFilesystem").Get()
 
@@ -29,7 +29,7 @@ spec:
 		t.Fatal(err.Error())
 	}
 
-	containers := ReadOnlyRootFilesystem(json)
+	containers := ReadOnlyRootFilesystem(json).Count
 	if containers != 1 {
 		t.Errorf("Got %v containers wanted %v", containers, 1)
 	}
@@ -66,7 +66,7 @@ spec:
 		t.Fatal(err.Error())
 	}
 
-	containers := ReadOnlyRootFilesystem(json)
+	containers := ReadOnlyRootFilesystem(json).Count
 	if containers != 2 {
 		t.Errorf("Got %v containers wanted %v", containers, 2)
 	}
@@ -94,7 +94,7 @@ spec:
 		t.Fatal(err.Error())
 	}
 
-	containers := ReadOnlyRootFilesystem(json)
+	containers := ReadOnlyRootFilesystem(json).Count
 	if containers != 0 {
 		t.Errorf("Got %v containers wanted %v", containers, 0)
 	}
@@ -116,7 +116,7 @@ spec:
 		t.Fatal(err.Error())
 	}
 
-	containers := ReadOnlyRootFilesystem(json)
+	containers := ReadOnlyRootFilesystem(json).Count
 	if containers != 0 {
 		t.Errorf("Got %v containers wanted %v", containers, 0)
 	}
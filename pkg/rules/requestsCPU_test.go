@@ -27,7 +27,7 @@ spec:
 		t.Fatal(err.Error())
 	}
 
-	containers := RequestsCPU(json)
+	containers := RequestsCPU(json).Count
 	if containers != 1 {
 		t.Errorf("Got %v containers wanted %v", containers, 1)
 	}
@@ -89,7 +89,7 @@ spec:
 		t.Fatal(err.Error())
 	}
 
-	containers := RequestsCPU(json)
+	containers := RequestsCPU(json).Count
 	if containers != 1 {
 		t.Errorf("Got %v containers wanted %v", containers, 1)
 	}
@@ -30,7 +30,7 @@ spec:
 		t.Fatal(err.Error())
 	}
 
-	containers := RequestsMemory(json)
+	containers := RequestsMemory(json).Count
 	if containers != 1 {
 		t.Errorf("Got %v containers wanted %v", containers, 1)
 	}
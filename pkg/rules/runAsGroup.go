@@ -4,10 +4,11 @@ import (
 	"github.com/thedevsaddam/gojsonq/v2"
 )
 
-func RunAsGroup(json []byte) int {
+func RunAsGroup(json []byte) Result {
 	return checkSecurityContext(
 		json,
 		true,
+		"securityContext.runAsGroup",
 		func(jq *gojsonq.JSONQ) checkSecurityContextResult {
 			value := jq.From("securityContext.runAsGroup").Get()
 
@@ -39,7 +39,7 @@ spec:
 		t.Fatal(err.Error())
 	}
 
-	containers := RunAsGroup(json)
+	containers := RunAsGroup(json).Count
 	if containers != 4 {
 		t.Errorf("Got %v containers wanted %v", containers, 4)
 	}
@@ -72,7 +72,7 @@ spec:
 		t.Fatal(err.Error())
 	}
 
-	containers := RunAsGroup(json)
+	containers := RunAsGroup(json).Count
 	if containers != 2 {
 		t.Errorf("Got %v containers wanted %v", containers, 2)
 	}
@@ -95,7 +95,7 @@ spec:
 		t.Fatal(err.Error())
 	}
 
-	containers := RunAsGroup(json)
+	containers := RunAsGroup(json).Count
 	if containers != 0 {
 		t.Errorf("Got %v containers wanted %v", containers, 0)
 	}
@@ -118,7 +118,7 @@ spec:
 		t.Fatal(err.Error())
 	}
 
-	containers := RunAsGroup(json)
+	containers := RunAsGroup(json).Count
 	if containers != 1 {
 		t.Errorf("Got %v containers wanted %v", containers, 1)
 	}
@@ -143,7 +143,7 @@ spec:
 		t.Fatal(err.Error())
 	}
 
-	containers := RunAsGroup(json)
+	containers := RunAsGroup(json).Count
 	if containers != 1 {
 		t.Errorf("Got %v containers wanted %v", containers, 1)
 	}
@@ -169,7 +169,7 @@ spec:
 		t.Fatal(err.Error())
 	}
 
-	containers := RunAsGroup(json)
+	containers := RunAsGroup(json).Count
 	if containers != 1 {
 		t.Errorf("Got %v containers wanted %v", containers, 1)
 	}
@@ -192,7 +192,7 @@ spec:
 		t.Fatal(err.Error())
 	}
 
-	containers := RunAsGroup(json)
+	containers := RunAsGroup(json).Count
 	if containers != 0 {
 		t.Errorf("Got %v containers wanted %v", containers, 0)
 	}
@@ -4,10 +4,11 @@ import (
 	"github.com/thedevsaddam/gojsonq/v2"
 )
 
-func RunAsNonRoot(json []byte) int {
+func RunAsNonRoot(json []byte) Result {
 	return checkSecurityContext(
 		json,
 		true,
+		"securityContext.runAsNonRoot",
 		func(jq *gojsonq.JSONQ) checkSecurityContextResult {
 			value := jq.From("securityContext.runAsNonRoot").Get()
 
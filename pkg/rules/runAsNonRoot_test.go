@@ -39,7 +39,7 @@ spec:
 		t.Fatal(err.Error())
 	}
 
-	containers := RunAsNonRoot(json)
+	containers := RunAsNonRoot(json).Count
 	if containers != 4 {
 		t.Errorf("Got %v containers wanted %v", containers, 4)
 	}
@@ -70,7 +70,7 @@ spec:
 		t.Fatal(err.Error())
 	}
 
-	containers := RunAsNonRoot(json)
+	containers := RunAsNonRoot(json).Count
 	if containers != 3 {
 		t.Errorf("Got %v containers wanted %v", containers, 3)
 	}
@@ -107,7 +107,7 @@ spec:
 		t.Fatal(err.Error())
 	}
 
-	containers := RunAsNonRoot(json)
+	containers := RunAsNonRoot(json).Count
 	if containers != 2 {
 		t.Errorf("Got %v containers wanted %v", containers, 2)
 	}
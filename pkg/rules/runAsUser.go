@@ -4,10 +4,11 @@ import (
 	"github.com/thedevsaddam/gojsonq/v2"
 )
 
-func RunAsUser(json []byte) int {
+func RunAsUser(json []byte) Result {
 	return checkSecurityContext(
 		json,
 		true,
+		"securityContext.runAsUser",
 		func(jq *gojsonq.JSONQ) checkSecurityContextResult {
 			value := jq.From("securityContext.runAsUser").Get()
 
@@ -25,10 +25,11 @@ func isSeccompUnconfined(jq *gojsonq.JSONQ, expectedUnconfined bool) checkSecuri
 
 // SeccompAny retrieves the number of instances in a manifest where the Seccomp profile has been specified
 // to a value other than 'Unconfined'
-func SeccompAny(json []byte) int {
+func SeccompAny(json []byte) Result {
 	return checkSecurityContext(
 		json,
 		true, // present in PodSecurityContext
+		"securityContext.seccompProfile.type",
 		func(jq *gojsonq.JSONQ) checkSecurityContextResult {
 			return isSeccompUnconfined(jq, false)
 		})
@@ -96,7 +96,7 @@ func Test_SeccompAny(t *testing.T) {
 				t.Fatal(err.Error())
 			}
 
-			count := SeccompAny(json)
+			count := SeccompAny(json).Count
 			expectedCount := 0
 			if tc.expectedProfileType == tcprofSeccompRuntimeDefault || tc.expectedProfileType == tcprofSeccompLocalHost {
 				expectedCount = 1
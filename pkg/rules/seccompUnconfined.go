@@ -6,10 +6,11 @@ import (
 
 // SeccompUnconfined retrieves the number of instances in a manifest where the Seccomp profile has been specified
 // to a value of 'Unconfined'
-func SeccompUnconfined(json []byte) int {
+func SeccompUnconfined(json []byte) Result {
 	return checkSecurityContext(
 		json,
 		true, // present in PodSecurityContext
+		"securityContext.seccompProfile.type",
 		func(jq *gojsonq.JSONQ) checkSecurityContextResult {
 			return isSeccompUnconfined(jq, true)
 		})
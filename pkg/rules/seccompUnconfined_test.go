@@ -13,7 +13,7 @@ func Test_SeccompUnconfined(t *testing.T) {
 				t.Fatal(err.Error())
 			}
 
-			count := SeccompUnconfined(json)
+			count := SeccompUnconfined(json).Count
 			expectedCount := 0
 			if tc.expectedProfileType == tcprofSeccompUnconfined {
 				expectedCount = 1
@@ -3,62 +3,36 @@ package rules
 import (
 	"bytes"
 	"fmt"
-	"strconv"
 
 	"github.com/thedevsaddam/gojsonq/v2"
 )
 
-func SecretsAsEnvironmentVariables(json []byte) int {
+func SecretsAsEnvironmentVariables(json []byte) Result {
 	spec := getSpecSelector(json)
 	jq := gojsonq.New().Reader(bytes.NewReader(json))
-	found := 0
-
-	containerPaths := []string{
-		spec + ".containers",
-		spec + ".initContainers",
-		spec + ".ephemeralContainers",
-	}
 
 	// Check for secrets in env, e.g. .containers.[0].env.[0].valueFrom.secretKeyRef
-	checkEnv := func(jq *gojsonq.JSONQ, containerNode string) bool {
-		envNode := containerNode + ".env"
-		envCount := jq.Copy().From(envNode).Count()
-		for i := 0; i < envCount; i++ {
-			secretKeyRef := jq.Copy().From(fmt.Sprintf("%s.[%s].%s", envNode, strconv.Itoa(i), "valueFrom.secretKeyRef")).Get()
-			if secretKeyRef != nil {
-				return true
-			}
-		}
-		return false
-	}
-
-	// Check for secrets in envFrom, e.g. .containers.[0].envFrom.[0].secretRef
-	checkEnvFrom := func(jq *gojsonq.JSONQ, containerNode string) bool {
-		envNode := containerNode + ".envFrom"
-		envCount := jq.Copy().From(envNode).Count()
-		for i := 0; i < envCount; i++ {
-			secretRef := jq.Copy().From(fmt.Sprintf("%s.[%s].%s", envNode, strconv.Itoa(i), "secretRef")).Get()
-			if secretRef != nil {
-				return true
+	// and envFrom, e.g. .containers.[0].envFrom.[0].secretRef
+	checkRefs := func(c container, list, ref string) []Finding {
+		var findings []Finding
+		count := jq.Copy().From(c.node + "." + list).Count()
+		for i := 0; i < count; i++ {
+			value := jq.Copy().From(fmt.Sprintf("%s.%s.[%d].%s", c.node, list, i, ref)).Get()
+			if value != nil {
+				findings = append(findings, c.finding(fmt.Sprintf("%s[%d].%s", list, i, ref), value))
 			}
 		}
-		return false
+		return findings
 	}
 
-	for _, containerPath := range containerPaths {
-		containerCount := jq.Copy().From(containerPath).Count()
-
-		for i := 0; i < containerCount; i++ {
-			containerNode := fmt.Sprintf("%s.[%s]", containerPath, strconv.Itoa(i))
-
-			hasSecretsinEnv := checkEnv(jq, containerNode)
-			hasSecretsinEnvFrom := checkEnvFrom(jq, containerNode)
-
-			if hasSecretsinEnv || hasSecretsinEnvFrom {
-				found++
-			}
+	var res Result
+	for _, c := range getContainerNodes(jq, spec) {
+		findings := append(checkRefs(c, "env", "valueFrom.secretKeyRef"), checkRefs(c, "envFrom", "secretRef")...)
+		if len(findings) > 0 {
+			res.Count++
+			res.Findings = append(res.Findings, findings...)
 		}
 	}
 
-	return found
+	return res
 }
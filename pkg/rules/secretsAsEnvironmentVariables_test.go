@@ -31,7 +31,7 @@ spec:
 		t.Fatal(err.Error())
 	}
 
-	containers := SecretsAsEnvironmentVariables(json)
+	containers := SecretsAsEnvironmentVariables(json).Count
 	if containers != 1 {
 		t.Errorf("Got %v containers wanted %v", containers, 1)
 	}
@@ -59,7 +59,7 @@ spec:
 		t.Fatal(err.Error())
 	}
 
-	containers := SecretsAsEnvironmentVariables(json)
+	containers := SecretsAsEnvironmentVariables(json).Count
 	if containers != 1 {
 		t.Errorf("Got %v containers wanted %v", containers, 1)
 	}
@@ -92,7 +92,7 @@ spec:
 		t.Fatal(err.Error())
 	}
 
-	containers := SecretsAsEnvironmentVariables(json)
+	containers := SecretsAsEnvironmentVariables(json).Count
 	if containers != 1 {
 		t.Errorf("Got %v containers wanted %v", containers, 1)
 	}
@@ -122,7 +122,7 @@ spec:
 		t.Fatal(err.Error())
 	}
 
-	containers := SecretsAsEnvironmentVariables(json)
+	containers := SecretsAsEnvironmentVariables(json).Count
 	if containers != 1 {
 		t.Errorf("Got %v containers wanted %v", containers, 1)
 	}
@@ -158,7 +158,7 @@ spec:
 		t.Fatal(err.Error())
 	}
 
-	containers := SecretsAsEnvironmentVariables(json)
+	containers := SecretsAsEnvironmentVariables(json).Count
 	if containers != 1 {
 		t.Errorf("Got %v containers wanted %v", containers, 1)
 	}
@@ -186,7 +186,7 @@ spec:
 		t.Fatal(err.Error())
 	}
 
-	containers := SecretsAsEnvironmentVariables(json)
+	containers := SecretsAsEnvironmentVariables(json).Count
 	if containers != 0 {
 		t.Errorf("Got %v containers wanted %v", containers, 0)
 	}
@@ -216,7 +216,7 @@ spec:
 		t.Fatal(err.Error())
 	}
 
-	containers := SecretsAsEnvironmentVariables(json)
+	containers := SecretsAsEnvironmentVariables(json).Count
 	if containers != 0 {
 		t.Errorf("Got %v containers wanted %v", containers, 0)
 	}
@@ -1,29 +1,62 @@
 package rules
 
 import (
-	"bytes"
-	"fmt"
-
-	"github.com/thedevsaddam/gojsonq/v2"
+	"encoding/json"
 )
 
 func getSpecSelector(json []byte) string {
-	selector := "spec.template.spec"
+	return getSpecSelectors(json)[0]
+}
+
+// getSpecSelectors returns the paths of the pod specs of an object. Objects
+// with a pod spec mapping may hold several, other objects hold one.
+func getSpecSelectors(data []byte) []string {
+	var object map[string]interface{}
+	if err := json.Unmarshal(data, &object); err != nil {
+		return []string{specSelectorForKind("")}
+	}
+
+	return specSelectorsOf(object)
+}
 
-	jq := gojsonq.New().Reader(bytes.NewReader(json)).From("kind")
-	if jq.Error() != nil {
-		return selector
+// specSelectorsOf returns the paths of the pod specs of a decoded object
+func specSelectorsOf(object map[string]interface{}) []string {
+	if m, ok := objectPodSpecMapping(object); ok {
+		return m.existingPaths(object)
 	}
 
-	kind := fmt.Sprintf("%s", jq.Get())
+	kind, _ := object["kind"].(string)
+	return []string{specSelectorForKind(kind)}
+}
+
+// specSelectorForKind returns the path of the pod spec for a kind
+func specSelectorForKind(kind string) string {
+	selector := "spec.template.spec"
 
 	if kind == "Pod" {
 		selector = "spec"
 	}
 
+	if kind == "PodTemplate" {
+		selector = "template.spec"
+	}
+
 	if kind == "CronJob" {
 		selector = "spec.jobTemplate." + selector
 	}
 
 	return selector
 }
+
+// SpecSelector returns the path of the pod spec of an object, e.g.
+// spec.template.spec for a Deployment, or its first pod spec for objects
+// with several
+func SpecSelector(json []byte) string {
+	return getSpecSelector(json)
+}
+
+// SpecSelectors returns the paths of the pod specs of an object, several for
+// the objects of a pod spec mapping with several paths
+func SpecSelectors(json []byte) []string {
+	return getSpecSelectors(json)
+}
@@ -0,0 +1,90 @@
+package rules
+
+import (
+	"testing"
+)
+
+func Test_SpecSelector(t *testing.T) {
+	var tests = []struct {
+		kind     string
+		expected string
+	}{
+		{"Pod", "spec"},
+		{"PodTemplate", "template.spec"},
+		{"Deployment", "spec.template.spec"},
+		{"StatefulSet", "spec.template.spec"},
+		{"DaemonSet", "spec.template.spec"},
+		{"ReplicaSet", "spec.template.spec"},
+		{"ReplicationController", "spec.template.spec"},
+		{"Job", "spec.template.spec"},
+		{"CronJob", "spec.jobTemplate.spec.template.spec"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.kind, func(t *testing.T) {
+			json := []byte(`{"kind":"` + tt.kind + `"}`)
+			if got := SpecSelector(json); got != tt.expected {
+				t.Errorf("Got %v selector wanted %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func Test_SpecSelector_PodSpecMappings(t *testing.T) {
+	var tests = []struct {
+		name     string
+		json     string
+		expected string
+	}{
+		{"Argo Rollout", `{"apiVersion":"argoproj.io/v1alpha1","kind":"Rollout"}`, "spec.template.spec"},
+		{"Knative Service", `{"apiVersion":"serving.knative.dev/v1","kind":"Service"}`, "spec.template.spec"},
+		{"Knative Revision", `{"apiVersion":"serving.knative.dev/v1","kind":"Revision"}`, "spec"},
+		{"OpenShift DeploymentConfig", `{"apiVersion":"apps.openshift.io/v1","kind":"DeploymentConfig"}`, "spec.template.spec"},
+		{"unmapped group", `{"apiVersion":"example.org/v1","kind":"Rollout"}`, "spec.template.spec"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SpecSelector([]byte(tt.json)); got != tt.expected {
+				t.Errorf("Got %v selector wanted %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func Test_RegisterPodSpecMappings(t *testing.T) {
+	defer func(mappings []PodSpecMapping) { podSpecMappings = mappings }(PodSpecMappings())
+
+	if err := RegisterPodSpecMappings(PodSpecMapping{APIVersion: "example.com/v1", Kind: "Worker", Paths: []string{"spec..template"}}); err == nil {
+		t.Errorf("Got no error for an invalid path wanted error")
+	}
+
+	err := RegisterPodSpecMappings(PodSpecMapping{
+		APIVersion: "example.com",
+		Kind:       "Worker",
+		Paths:      []string{"spec.template.spec", "spec.sidecar.spec"},
+	})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if !IsPodSpecKind("Worker") {
+		t.Errorf("Got Worker not a pod spec kind")
+	}
+
+	json := []byte(`{"apiVersion":"example.com/v2","kind":"Worker","spec":{"sidecar":{"spec":{"hostPID":true}}}}`)
+	if got, expected := SpecSelector(json), "spec.sidecar.spec"; got != expected {
+		t.Errorf("Got %v selector wanted %v", got, expected)
+	}
+
+	json = []byte(`{"apiVersion":"example.com/v1","kind":"Worker","spec":{"template":{"spec":{"hostPID":true}},"sidecar":{"spec":{"hostIPC":true}}}}`)
+	docs := SplitPodSpecs(json)
+	if len(docs) != 2 {
+		t.Fatalf("Got %d pod specs wanted %d", len(docs), 2)
+	}
+	if HostPID(docs[0]).Count != 1 || HostIPC(docs[0]).Count != 0 {
+		t.Errorf("Got first pod spec %s", docs[0])
+	}
+	if HostPID(docs[1]).Count != 0 || HostIPC(docs[1]).Count != 1 {
+		t.Errorf("Got second pod spec %s", docs[1])
+	}
+}
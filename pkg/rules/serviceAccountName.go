@@ -6,7 +6,7 @@ import (
 	"github.com/thedevsaddam/gojsonq/v2"
 )
 
-func ServiceAccountName(json []byte) int {
+func ServiceAccountName(json []byte) Result {
 	spec := getSpecSelector(json)
 
 	jqContainers := gojsonq.New().Reader(bytes.NewReader(json)).
@@ -15,9 +15,9 @@ func ServiceAccountName(json []byte) int {
 		Where(".serviceAccountName", "!=", "")
 
 	// TODO(ajm) the above `Where` selectors don't do what I'd expect and filter the results
-	if fmt.Sprintf("%v", jqContainers.Get()) != "<nil>" {
-		return 1
+	if value := jqContainers.Get(); fmt.Sprintf("%v", value) != "<nil>" {
+		return match(spec+".serviceAccountName", value)
 	}
 
-	return 0
+	return Result{}
 }
@@ -23,7 +23,7 @@ spec:
 		t.Fatal(err.Error())
 	}
 
-	containers := ServiceAccountName(json)
+	containers := ServiceAccountName(json).Count
 	if containers != 1 {
 		t.Errorf("Got %v containers wanted %v", containers, 1)
 	}
@@ -47,7 +47,7 @@ spec:
 		t.Fatal(err.Error())
 	}
 
-	containers := ServiceAccountName(json)
+	containers := ServiceAccountName(json).Count
 	if containers != 0 {
 		t.Errorf("Got %v containers wanted %v", containers, 0)
 	}
@@ -70,7 +70,7 @@ spec:
 		t.Fatal(err.Error())
 	}
 
-	containers := ServiceAccountName(json)
+	containers := ServiceAccountName(json).Count
 	if containers != 0 {
 		t.Errorf("Got %v containers wanted %v", containers, 0)
 	}
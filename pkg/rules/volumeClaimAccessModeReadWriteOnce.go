@@ -3,35 +3,33 @@ package rules
 import (
 	"bytes"
 	"fmt"
-	"strings"
 
 	"github.com/thedevsaddam/gojsonq/v2"
 )
 
-func VolumeClaimAccessModeReadWriteOnce(json []byte) int {
-
-	volumeClaims := 0
+func VolumeClaimAccessModeReadWriteOnce(json []byte) Result {
+	jq := gojsonq.New().Reader(bytes.NewReader(json))
 	// count all volumeClaimTemplates
-	volumeClaimTemplates := gojsonq.New().Reader(bytes.NewReader(json)).
-		From("spec.volumeClaimTemplates").
-		Only("spec")
-
-	volumeClaims += len(volumeClaimTemplates.([]interface{}))
+	volumeClaims := jq.Copy().From("spec.volumeClaimTemplates").Count()
 
 	// pass test if no PVCs are included in statefulset (which is legal)
 	if volumeClaims == 0 {
-		return 1
+		return Result{Count: 1}
 	}
 
-	found := 0
-
-	paths := gojsonq.New().Reader(bytes.NewReader(json)).
-		From("spec.volumeClaimTemplates").
-		Only("spec.accessModes")
-
-	if paths != nil && strings.Contains(fmt.Sprintf("%v", paths), "accessModes:[ReadWriteOnce]") {
-		found++
+	var res Result
+	for i := 0; i < volumeClaims; i++ {
+		value := jq.Copy().From(fmt.Sprintf("spec.volumeClaimTemplates.[%d].spec.accessModes", i)).Get()
+		if fmt.Sprintf("%v", value) == "[ReadWriteOnce]" {
+			res.Findings = append(res.Findings, Finding{
+				Path:  fmt.Sprintf("spec.volumeClaimTemplates[%d].spec.accessModes", i),
+				Value: value,
+			})
+		}
+	}
+	if len(res.Findings) > 0 {
+		res.Count = 1
 	}
 
-	return found
+	return res
 }
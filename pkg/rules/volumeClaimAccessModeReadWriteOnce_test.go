@@ -53,7 +53,7 @@ spec:
 		t.Fatal(err.Error())
 	}
 
-	containers := VolumeClaimAccessModeReadWriteOnce(json)
+	containers := VolumeClaimAccessModeReadWriteOnce(json).Count
 	if containers != 1 {
 		t.Errorf("Got %v containers wanted %v", containers, 1)
 	}
@@ -77,7 +77,7 @@ spec:
 		t.Fatal(err.Error())
 	}
 
-	containers := VolumeClaimAccessModeReadWriteOnce(json)
+	containers := VolumeClaimAccessModeReadWriteOnce(json).Count
 	if containers != 1 {
 		t.Errorf("Got %v containers wanted %v", containers, 1)
 	}
@@ -2,31 +2,31 @@ package rules
 
 import (
 	"bytes"
+	"fmt"
 
 	"github.com/thedevsaddam/gojsonq/v2"
 )
 
-func VolumeClaimRequestsStorage(json []byte) int {
-	volumeClaims := 0
+func VolumeClaimRequestsStorage(json []byte) Result {
+	jq := gojsonq.New().Reader(bytes.NewReader(json))
 	// count all volumeClaimTemplates
-	volumeClaimTemplates := gojsonq.New().Reader(bytes.NewReader(json)).
-		From("spec.volumeClaimTemplates").
-		Only("spec")
-
-	volumeClaims += len(volumeClaimTemplates.([]interface{}))
+	volumeClaims := jq.Copy().From("spec.volumeClaimTemplates").Count()
 
 	// pass test if no PVCs are included in statefulset (which is legal)
 	if volumeClaims == 0 {
-		return 1
+		return Result{Count: 1}
 	}
 
-	found := 0
-
-	paths := gojsonq.New().Reader(bytes.NewReader(json)).
-		From("spec.volumeClaimTemplates").
-		Only("spec.resources.requests.storage")
-
-	found += len(paths.([]interface{}))
+	var res Result
+	for i := 0; i < volumeClaims; i++ {
+		value := jq.Copy().From(fmt.Sprintf("spec.volumeClaimTemplates.[%d].spec.resources.requests.storage", i)).Get()
+		if value != nil {
+			res.add(Finding{
+				Path:  fmt.Sprintf("spec.volumeClaimTemplates[%d].spec.resources.requests.storage", i),
+				Value: value,
+			})
+		}
+	}
 
-	return found
+	return res
 }
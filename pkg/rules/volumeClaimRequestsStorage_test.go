@@ -36,7 +36,7 @@ spec:
 		t.Fatal(err.Error())
 	}
 
-	containers := VolumeClaimRequestsStorage(json)
+	containers := VolumeClaimRequestsStorage(json).Count
 	if containers != 1 {
 		t.Errorf("Got %v containers wanted %v", containers, 1)
 	}
@@ -75,7 +75,7 @@ spec:
 		t.Fatal(err.Error())
 	}
 
-	containers := VolumeClaimRequestsStorage(json)
+	containers := VolumeClaimRequestsStorage(json).Count
 	if containers != 2 {
 		t.Errorf("Got %v containers wanted %v", containers, 2)
 	}
@@ -99,7 +99,7 @@ spec:
 		t.Fatal(err.Error())
 	}
 
-	containers := VolumeClaimRequestsStorage(json)
+	containers := VolumeClaimRequestsStorage(json).Count
 	if containers != 1 {
 		t.Errorf("Got %v containers wanted %v", containers, 1)
 	}
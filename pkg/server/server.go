@@ -26,11 +26,12 @@ func ListenAndServe(
 	stopCh <-chan struct{},
 	keypath string,
 	schemaConfig ruler.SchemaConfig,
+	rulesetConfig ruler.RulesetConfig,
 ) {
 
 	mux := http.DefaultServeMux
-	mux.Handle("/", scanHandler(logger, keypath, schemaConfig))
-	mux.Handle("/scan", scanHandler(logger, keypath, schemaConfig))
+	mux.Handle("/", scanHandler(logger, keypath, schemaConfig, rulesetConfig))
+	mux.Handle("/scan", scanHandler(logger, keypath, schemaConfig, rulesetConfig))
 	mux.Handle("/metrics", promhttp.Handler())
 	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
@@ -106,7 +107,7 @@ func retrieveRequestData(r *http.Request) ([]byte, error) {
 	return body, nil
 }
 
-func scanHandler(logger *zap.SugaredLogger, keypath string, schemaConfig ruler.SchemaConfig) http.Handler {
+func scanHandler(logger *zap.SugaredLogger, keypath string, schemaConfig ruler.SchemaConfig, rulesetConfig ruler.RulesetConfig) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.Method == http.MethodGet {
 			http.Redirect(w, r, "https://kubesec.io", http.StatusSeeOther)
@@ -135,8 +136,14 @@ func scanHandler(logger *zap.SugaredLogger, keypath string, schemaConfig ruler.S
 			ruleIDs = append(ruleIDs, rules...)
 		}
 
+		// Select a policy profile, e.g. ?profile=strict
+		config := rulesetConfig
+		if profile := r.URL.Query().Get("profile"); profile != "" {
+			config.Profile = profile
+		}
+
 		var payload interface{}
-		ruleset, err := ruler.NewRuleset(logger, ruleIDs...)
+		ruleset, err := ruler.NewRulesetWithConfig(logger, config, ruleIDs...)
 		if err != nil {
 			w.WriteHeader(http.StatusBadRequest)
 			if _, err := w.Write([]byte(err.Error() + "\n")); err != nil {
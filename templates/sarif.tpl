@@ -34,7 +34,8 @@
                 }
               },
               "properties": {
-                "points": "{{ .Points }}"
+                "points": "{{ .Points }}",
+                "severity": "{{ .Severity }}"
               }
             }
           {{- end -}}
@@ -43,7 +44,7 @@
       },
       "results": [
       {{- $result_first := true }}
-      {{- range $result_index, $res := joinSlices .Scoring.Advise .Scoring.Critical -}}
+      {{- range $result_index, $res := joinSlices .Scoring.Advise .Scoring.Critical .Scoring.Suppressed -}}
         {{- if $result_first -}}
           {{- $result_first = false -}}
         {{ else -}}
@@ -51,15 +52,50 @@
         {{- end }}
         {
           "ruleId": "{{ $res.ID }}",
-          "level": "warning",
+          "level": "{{ if or (eq $res.Severity "critical") (eq $res.Severity "high") }}error{{ else if eq $res.Severity "medium" }}warning{{ else }}note{{ end }}",
           "message": {
             "text": {{ endWithPeriod $res.Reason | printf "%q" }},
             "properties": {
               "score": "{{ $res.Points }}",
-              "selector": {{ escapeString $res.Selector | printf "%q" }}
+              "selector": {{ escapeString $res.Selector | printf "%q" }}{{ if $res.Findings }},
+              "findings": {{ toJSON $res.Findings }}{{ end }}
             }
           },
+          {{- with $res.Suppression }}
+          "suppressions": [
+            {
+              "kind": "{{ if eq .Source "annotation" }}inSource{{ else }}external{{ end }}",
+              "status": "{{ if .Expired }}rejected{{ else }}accepted{{ end }}",
+              "justification": {{ printf "%q" .Justification }}
+            }
+          ],
+          {{- end }}
           "locations": [
+          {{- range $finding_index, $finding := $res.Findings }}
+            {{- if $finding_index }},{{ end }}
+            {
+              "physicalLocation": {
+                "artifactLocation": {
+                  "uri": "{{ $report.FileName }}"
+                }
+                {{- with $finding.Location }},
+                "region": {
+                  "startLine": {{ .Line }},
+                  "startColumn": {{ .Column }}
+                }
+                {{- end }}
+              },
+              "logicalLocations": [
+                {
+                  {{- with $finding.Container }}
+                  "name": {{ printf "%q" . }},
+                  {{- end }}
+                  "fullyQualifiedName": {{ printf "%q" $finding.Path }},
+                  "kind": "member"
+                }
+              ]
+            }
+          {{- else }}
             {
               "physicalLocation": {
                 "artifactLocation": {
@@ -67,6 +103,7 @@
                 }
               }
             }
+          {{- end }}
           ]
         }
       {{- end -}}
@@ -238,6 +238,36 @@ teardown() {
   assert_lt_zero_points
 }
 
+@test "fails Job with securityContext.privileged = true" {
+  run _app "${TEST_DIR}/asset/score-0-job-securitycontext-privileged.yml"
+  assert_lt_zero_points
+}
+
+@test "fails CronJob with hostPid" {
+  run _app "${TEST_DIR}/asset/score-0-cronjob-host-pid.yml"
+  assert_lt_zero_points
+}
+
+@test "fails ReplicaSet with hostNetwork" {
+  run _app "${TEST_DIR}/asset/score-0-replicaset-host-network.yml"
+  assert_lt_zero_points
+}
+
+@test "fails ReplicationController with mounted host docker.sock" {
+  run _app "${TEST_DIR}/asset/score-0-replicationcontroller-mount-docker-socket.yml"
+  assert_lt_zero_points
+}
+
+@test "fails PodTemplate with CAP_SYS_ADMIN" {
+  run _app "${TEST_DIR}/asset/score-0-podtemplate-cap-sys-admin.yml"
+  assert_lt_zero_points
+}
+
+@test "fails Argo Rollout with privileged security context" {
+  run _app "${TEST_DIR}/asset/score-0-rollout-privileged.yml"
+  assert_lt_zero_points
+}
+
 @test "passes Deployment with serviceaccountname" {
   run _app "${TEST_DIR}/asset/score-2-dep-serviceaccount.yml"
   assert_gt_zero_points
@@ -371,3 +401,96 @@ teardown() {
   assert [ "${RUN_1_SIGNATURE}" = "${RUN_2_SIGNATURE}" ]
   assert [ "${RUN_1_SIGNATURE}" = "${RUN_3_SIGNATURE}" ]
 }
+
+@test "writes a single SARIF run with located results" {
+  run bash -c "${BIN_DIR:-}/kubesec scan -f sarif \"${TEST_DIR}/asset/score-0-daemonset-host-pid.yml\" 2>/dev/null"
+
+  assert_equal "$(jq -r '.version' <<<"${output}")" "2.1.0"
+  assert_equal "$(jq '.runs | length' <<<"${output}")" "1"
+  assert_equal "$(jq -r '.runs[0].results[] | select(.ruleId == "HostPID") | .level' <<<"${output}")" "error"
+  assert_equal "$(jq '[.runs[0].results[] | select(.locations[0].physicalLocation.region.startLine > 0)] | length' <<<"${output}")" \
+    "$(jq '.runs[0].results | length' <<<"${output}")"
+  assert_equal "$(jq '[.runs[0].results[].partialFingerprints | length] | min' <<<"${output}")" "1"
+}
+
+@test "writes JUnit test suites with failed critical rules" {
+  run bash -c "${BIN_DIR:-}/kubesec scan -f junit \"${TEST_DIR}/asset/score-0-daemonset-host-pid.yml\" 2>/dev/null"
+
+  assert_line --index 0 '<?xml version="1.0" encoding="UTF-8"?>'
+  assert_output --regexp '<testsuite name="DaemonSet/[^"]*" tests="[0-9]+" failures="2" errors="0" skipped="0"'
+  assert_output --regexp '<testcase name="HostPID" [^>]*>'
+  assert_output --partial '<failure message="Sharing the host&#39;s PID namespace'
+  assert_output --regexp '<failure message="score -?[0-9]+ is not above zero" type="PolicyFailed">'
+}
+
+@test "writes a markdown report with score changes from a baseline" {
+  FILE="${TEST_DIR}/asset/score-0-daemonset-host-pid.yml"
+  BASELINE="${BATS_TMPDIR}/kubesec-baseline.json"
+  ${BIN_DIR:-}/kubesec scan "${FILE}" 2>/dev/null | jq '.[0].score += 5' >"${BASELINE}"
+
+  run bash -c "${BIN_DIR:-}/kubesec scan -f markdown --baseline \"${BASELINE}\" \"${FILE}\" 2>/dev/null"
+
+  assert_line --index 0 "## 🛡️ Kubesec Scan Report"
+  assert_output --partial "| Score | Change |"
+  assert_output --partial "⬇️ -5"
+  assert_output --partial "<details>"
+  # the object fails as it fails the exit code, with a score not above zero
+  assert_output --partial "1 of 1 objects failed."
+  assert_output --partial "| ❌ |"
+}
+
+@test "writes a self-contained HTML report" {
+  run bash -c "${BIN_DIR:-}/kubesec scan -f html \"${TEST_DIR}/asset/score-0-daemonset-host-pid.yml\" 2>/dev/null"
+
+  assert_line --index 0 "<!DOCTYPE html>"
+  assert_output --partial '<table id="findings-table" class="sortable">'
+  assert_output --partial '<tr id="rule-HostPID">'
+  assert_output --partial '<span class="line match">'
+  assert_output --partial '<span class="badge failed">failed</span>'
+  assert_output --partial '<td>(unset)</td>'
+  refute_output --partial '<script src='
+  refute_output --partial '<link rel="stylesheet"'
+}
+
+@test "lists the exceptions unused by a directory or standard input" {
+  EXCEPTIONS="${BATS_TMPDIR}/kubesec-exceptions.yaml"
+  MANIFESTS="${BATS_TMPDIR}/kubesec-exceptions-manifests"
+  mkdir -p "${MANIFESTS}"
+  cp "${TEST_DIR}/asset/score-0-daemonset-host-pid.yml" "${MANIFESTS}/"
+  cat >"${EXCEPTIONS}" <<YAML
+exceptions:
+  - rules: [HostPID]
+    owner: platform-team
+    expires: "2099-01-01"
+    justification: node exporter
+  - rules: [HostNetwork]
+    owner: platform-team
+    expires: "2099-01-01"
+    justification: CNI plugin
+YAML
+
+  run ${BIN_DIR:-}/kubesec exceptions check --exceptions "${EXCEPTIONS}" -f json "${MANIFESTS}"
+
+  assert_failure 2
+  assert_equal "$(jq -r '[.[] | select(.unused) | .rules[]] | join(",")' <<<"${output}")" "HostNetwork"
+
+  run bash -c "${BIN_DIR:-}/kubesec exceptions check --exceptions \"${EXCEPTIONS}\" -f json - <\"${MANIFESTS}/score-0-daemonset-host-pid.yml\""
+
+  assert_failure 2
+  assert_equal "$(jq -r '[.[] | select(.unused) | .rules[]] | join(",")' <<<"${output}")" "HostNetwork"
+}
+
+@test "exits with --schema-invalid-exit-code on objects failing the schema validation" {
+  run "${BIN_DIR}"/kubesec scan --schema-invalid-exit-code 4 "${TEST_DIR}/asset/invalid-schema.yml"
+
+  assert_equal "${status}" "4"
+  assert_output --partial '"valid": false'
+}
+
+@test "prints the reasons of a --fail-on-severity failure on stderr" {
+  run bash -c "${BIN_DIR:-}/kubesec scan --fail-on-severity high \"${TEST_DIR}/asset/score-0-daemonset-host-pid.yml\" 2>&1 >/dev/null"
+
+  assert_equal "${status}" "2"
+  assert_output --partial "findings at or above severity high: HostPID"
+  assert_output --partial "1 of 1 objects failed the exit policy, exiting with 2 (policy failed)"
+}